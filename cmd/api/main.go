@@ -5,11 +5,20 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sultra-otomotif-api/internal/auth"
+	"sultra-otomotif-api/internal/authz"
 	"sultra-otomotif-api/internal/config"
+	"sultra-otomotif-api/internal/database"
+	"sultra-otomotif-api/internal/geocode"
 	"sultra-otomotif-api/internal/handler"
+	"sultra-otomotif-api/internal/imaging"
+	"sultra-otomotif-api/internal/mailer"
 	"sultra-otomotif-api/internal/middleware"
+	"sultra-otomotif-api/internal/oidc"
 	"sultra-otomotif-api/internal/repository"
 	"sultra-otomotif-api/internal/service"
+	"sultra-otomotif-api/internal/sms"
+	"sultra-otomotif-api/internal/storage"
 	"sultra-otomotif-api/internal/websocket"
 	"time"
 
@@ -21,10 +30,17 @@ import (
 func main() {
 	// 1. Memuat Konfigurasi Aplikasi
 	cfg := config.LoadConfig()
-	if cfg.JWTSecretKey == "" || cfg.DBSource == "" || cfg.CloudinaryURL == "" || cfg.FrontendURL == "" {
+	if (cfg.JWTSecretKey == "" && cfg.JWTPrivateKeyFile == "") || cfg.DBSource == "" || cfg.FrontendURL == "" {
 		log.Fatal("FATAL: Required environment variables are not set.")
 	}
 
+	// Kunci JWT: satu kunci penanda tangan + kunci lama yang masih diterima selama rotasi
+	jwtKeys, err := auth.LoadKeySet(cfg.JWTSecretKey, cfg.JWTPreviousSecretKeys, cfg.JWTPrivateKeyFile, cfg.JWTPublicKeyFiles)
+	if err != nil {
+		log.Fatalf("FATAL: Unable to load JWT keys: %v", err)
+	}
+	log.Printf("JWT signing key: %s", jwtKeys.SigningKeyID())
+
 	// 2. Menghubungkan ke Database
 	db, err := pgxpool.New(context.Background(), cfg.DBSource)
 	if err != nil {
@@ -33,6 +49,15 @@ func main() {
 	defer db.Close()
 	log.Println("Database connection successful")
 
+	// Pastikan skema database sudah sesuai dengan versi aplikasi
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("FATAL: Unable to load migrations: %v", err)
+	}
+	if err := migrator.EnsureUpToDate(context.Background()); err != nil {
+		log.Fatalf("FATAL: %v. Run `go run ./cmd/migrate up` first.", err)
+	}
+
 	// 3. Inisialisasi Semua Layer (Dependency Injection)
 	userRepository := repository.NewUserRepository(db)
 	vehicleRepository := repository.NewVehicleRepository(db)
@@ -41,22 +66,139 @@ func main() {
 	reviewRepository := repository.NewReviewRepository(db)
 	salesRepository := repository.NewSalesRepository(db)
 	chatRepository := repository.NewChatRepository(db)
+	sessionRepository := repository.NewSessionRepository(db)
+	userTokenRepository := repository.NewUserTokenRepository(db)
+	loginThrottleRepository := repository.NewLoginThrottleRepository(db)
+	twoFactorRepository := repository.NewTwoFactorRepository(db)
+	platformSettingRepository := repository.NewPlatformSettingRepository(db)
+	apiKeyRepository := repository.NewAPIKeyRepository(db)
+	phoneOTPRepository := repository.NewPhoneOTPRepository(db)
+	userIdentityRepository := repository.NewUserIdentityRepository(db)
+	oidcStateRepository := repository.NewOIDCStateRepository(db)
+
+	var appMailer mailer.Mailer
+	if cfg.SMTPHost != "" {
+		appMailer = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+	} else {
+		log.Println("SMTP_HOST not set, emails will only be logged")
+		appMailer = mailer.NewLogMailer(cfg.MailOutboxDir)
+	}
 
-	userService := service.NewUserService(userRepository)
-	vehicleService := service.NewVehicleService(vehicleRepository, imageRepository, userRepository)
-	bookingService := service.NewBookingService(bookingRepository, vehicleRepository)
+	var smsSender sms.SMSSender
+	if cfg.TwilioAccountSID != "" {
+		if cfg.TwilioAuthToken == "" || cfg.SMSFrom == "" {
+			log.Fatal("FATAL: TWILIO_ACCOUNT_SID is set but TWILIO_AUTH_TOKEN or SMS_FROM is missing")
+		}
+		smsSender = sms.NewTwilioSender(sms.TwilioConfig{
+			AccountSID: cfg.TwilioAccountSID,
+			AuthToken:  cfg.TwilioAuthToken,
+			From:       cfg.SMSFrom,
+		})
+	} else {
+		log.Println("TWILIO_ACCOUNT_SID not set, SMS will only be logged")
+		smsSender = sms.NewLogSender()
+	}
+	var imageStorage storage.ObjectStorage
+	if cfg.CloudinaryURL != "" {
+		imageStorage, err = storage.NewCloudinaryStorage(cfg.CloudinaryURL, "sultra-otomotif")
+	} else {
+		log.Printf("CLOUDINARY_URL not set, images will be stored in %s and served from /media", cfg.MediaDir)
+		imageStorage, err = storage.NewLocalStorage(cfg.MediaDir, cfg.MediaBaseURL)
+	}
+	if err != nil {
+		log.Fatalf("FATAL: Unable to initialize image storage: %v", err)
+	}
+
+	imageProcessor := imaging.NewProcessor(imaging.Options{
+		MaxBytes:  int64(cfg.ImageMaxSizeMB) << 20,
+		MaxPixels: cfg.ImageMaxMegapixels * 1_000_000,
+	})
+
+	// Geocoder statis hanya mengenali kota/kabupaten di Sulawesi Tenggara. Provider sungguhan cukup
+	// mengimplementasikan geocode.Geocoder.
+	var geocoder geocode.Geocoder = geocode.NewStaticGeocoder()
+
+	authService := service.NewAuthService(sessionRepository, userRepository, apiKeyRepository, jwtKeys, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	loginThrottleService := service.NewLoginThrottleService(loginThrottleRepository, service.LoginThrottleConfig{
+		MaxAccountAttempts: cfg.LoginMaxAccountAttempts,
+		MaxIPAttempts:      cfg.LoginMaxIPAttempts,
+		LockoutDuration:    cfg.LoginLockoutDuration,
+		ProgressiveAfter:   3,
+		BaseDelay:          time.Second,
+		MaxDelay:           30 * time.Second,
+		MaxEmailRequests:   cfg.EmailMaxRequests,
+		EmailRequestWindow: cfg.EmailRequestWindow,
+	})
+	// Secret TOTP disimpan terenkripsi; tanpa kunci, user yang sudah mengaktifkan 2FA tidak bisa login
+	var totpBox *auth.SecretBox
+	if cfg.TOTPEncryptionKey != "" {
+		totpBox, err = auth.NewSecretBox(cfg.TOTPEncryptionKey)
+		if err != nil {
+			log.Fatalf("FATAL: Invalid TOTP_ENCRYPTION_KEY: %v", err)
+		}
+	} else {
+		log.Println("Warning: TOTP_ENCRYPTION_KEY not set, two-factor authentication is unavailable")
+	}
+	settingsService := service.NewSettingsService(platformSettingRepository, totpBox != nil)
+	if totpBox == nil {
+		// Tanpa kunci, admin yang diwajibkan 2FA tidak bisa mendaftar dan tidak ada yang bisa login sebagai admin
+		settings, err := settingsService.GetSettings(context.Background())
+		if err != nil {
+			log.Fatalf("FATAL: Unable to load platform settings: %v", err)
+		}
+		if settings.Require2FAForAdmin {
+			log.Fatal("FATAL: require_2fa_for_admin is enabled but TOTP_ENCRYPTION_KEY is not set")
+		}
+	}
+	twoFactorService := service.NewTwoFactorService(twoFactorRepository, userRepository, settingsService, authService, loginThrottleService, totpBox, cfg.TOTPIssuer)
+	userService := service.NewUserService(userRepository, userTokenRepository, authService, twoFactorService, loginThrottleService, appMailer, cfg.FrontendURL)
+	vehicleService := service.NewVehicleService(vehicleRepository, imageRepository, userRepository, geocoder, imageStorage, imageProcessor)
+	bookingService := service.NewBookingService(bookingRepository, vehicleRepository, userRepository)
 	reviewService := service.NewReviewService(reviewRepository, bookingRepository)
-	adminService := service.NewAdminService(userRepository, vehicleRepository)
+	adminService := service.NewAdminService(userRepository, vehicleRepository, authService, loginThrottleService, settingsService)
 	salesService := service.NewSalesService(salesRepository, vehicleRepository)
-	chatService := service.NewChatService(chatRepository, vehicleRepository)
+	chatService := service.NewChatService(chatRepository, vehicleRepository, userRepository)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository)
+	phoneVerificationService := service.NewPhoneVerificationService(userRepository, phoneOTPRepository, smsSender, service.PhoneVerificationConfig{
+		CodeTTL:     cfg.PhoneOTPTTL,
+		MaxAttempts: cfg.PhoneOTPMaxAttempts,
+		Cooldown:    cfg.PhoneOTPCooldown,
+		MaxPerHour:  cfg.PhoneOTPMaxPerHour,
+	})
+
+	// Login OIDC; issuer bisa diarahkan ke IdP tiruan lokal untuk development & pengujian
+	var oidcProviders []*oidc.Provider
+	for _, p := range cfg.OIDCProviders {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+		}, nil))
+	}
+	accountService := service.NewAccountService(userRepository, bookingRepository, salesRepository, vehicleRepository, reviewRepository, chatRepository)
+	oidcService := service.NewOIDCService(oidcProviders, userRepository, userIdentityRepository, oidcStateRepository, twoFactorService)
 
-	userHandler := handler.NewUserHandler(userService, cfg.JWTSecretKey)
+	userHandler := handler.NewUserHandler(userService, authService)
 	vehicleHandler := handler.NewVehicleHandler(vehicleService)
 	bookingHandler := handler.NewBookingHandler(bookingService)
 	reviewHandler := handler.NewReviewHandler(reviewService)
 	adminHandler := handler.NewAdminHandler(adminService)
 	salesHandler := handler.NewSalesHandler(salesService)
 	chatHandler := handler.NewChatHandler(chatService)
+	jwksHandler := handler.NewJWKSHandler(jwtKeys)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	phoneHandler := handler.NewPhoneHandler(phoneVerificationService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+	accountHandler := handler.NewAccountHandler(accountService)
 
 	hub := websocket.NewHub(chatService)
 	go hub.Run()
@@ -70,6 +212,12 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	router := gin.Default()
+	// IP klien dipakai untuk membatasi login gagal; jangan percaya X-Forwarded-For dari sembarang sumber
+	if len(cfg.TrustedProxies) > 0 {
+		if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			log.Fatalf("FATAL: Invalid TRUSTED_PROXIES: %v", err)
+		}
+	}
 
 	// Konfigurasi CORS yang fleksibel
 	corsConfig := cors.Config{
@@ -82,6 +230,14 @@ func main() {
 	}
 	router.Use(cors.New(corsConfig))
 
+	// Kunci publik JWT untuk verifikasi token di luar server ini (misal edge function frontend)
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
+	// Gambar yang disimpan di disk lokal disajikan langsung oleh server ini
+	if _, ok := imageStorage.(*storage.LocalStorage); ok {
+		router.Static("/media", cfg.MediaDir)
+	}
+
 	apiV1 := router.Group("/api/v1")
 
 	// Health Check Endpoint
@@ -90,17 +246,23 @@ func main() {
 	})
 
 	// 5. Mendaftarkan Semua Rute API
-	setupAuthRoutes(apiV1, userHandler, cfg.JWTSecretKey)
-	setupVehicleRoutes(apiV1, vehicleHandler, cfg.JWTSecretKey)
-	setupBookingRoutes(apiV1, bookingHandler, cfg.JWTSecretKey)
+	setupAuthRoutes(apiV1, userHandler, authService)
+	setupTwoFactorRoutes(apiV1, twoFactorHandler, authService)
+	setupAPIKeyRoutes(apiV1, apiKeyHandler, authService)
+	setupPhoneRoutes(apiV1, phoneHandler, authService)
+	setupOIDCRoutes(apiV1, oidcHandler)
+	setupAccountRoutes(apiV1, accountHandler, authService)
+	apiV1.GET("/auth/jwks.json", jwksHandler.GetJWKS)
+	setupVehicleRoutes(apiV1, vehicleHandler, authService)
+	setupBookingRoutes(apiV1, bookingHandler, authService)
 	setupPaymentRoutes(apiV1, bookingHandler)
-	setupReviewRoutes(apiV1, reviewHandler, cfg.JWTSecretKey)
-	setupAdminRoutes(apiV1, adminHandler, cfg.JWTSecretKey)
-	setupSalesRoutes(apiV1, salesHandler, cfg.JWTSecretKey)
-	setupChatRoutes(apiV1, chatHandler, cfg.JWTSecretKey)
+	setupReviewRoutes(apiV1, reviewHandler, authService)
+	setupAdminRoutes(apiV1, adminHandler, authService)
+	setupSalesRoutes(apiV1, salesHandler, authService)
+	setupChatRoutes(apiV1, chatHandler, authService)
 
 	// Daftarkan Rute WebSocket
-	apiV1.GET("/ws", middleware.AuthMiddleware(cfg.JWTSecretKey), func(c *gin.Context) {
+	apiV1.GET("/ws", middleware.SessionAuthMiddleware(authService), func(c *gin.Context) {
 		handler.ServeWs(hub, c)
 	})
 
@@ -117,50 +279,129 @@ func main() {
 }
 
 // setupAuthRoutes mendaftarkan semua rute yang berhubungan dengan autentikasi.
-func setupAuthRoutes(group *gin.RouterGroup, handler *handler.UserHandler, jwtSecret string) {
+func setupAuthRoutes(group *gin.RouterGroup, handler *handler.UserHandler, authService service.AuthService) {
 	authRoutes := group.Group("/auth")
 	{
 		authRoutes.POST("/register", handler.Register)
 		authRoutes.POST("/login", handler.Login)
-		authRoutes.GET("/me", middleware.AuthMiddleware(jwtSecret), handler.GetMe)
+		authRoutes.POST("/refresh", handler.Refresh)
+		authRoutes.POST("/logout", middleware.SessionAuthMiddleware(authService), handler.Logout)
+		authRoutes.GET("/me", middleware.AuthMiddleware(authService), handler.GetMe)
+		authRoutes.PATCH("/me", middleware.SessionAuthMiddleware(authService), handler.UpdateMe)
+
+		// Daftar perangkat yang sedang login & sign-out jarak jauh
+		authRoutes.GET("/sessions", middleware.SessionAuthMiddleware(authService), handler.ListSessions)
+		authRoutes.DELETE("/sessions/:id", middleware.SessionAuthMiddleware(authService), handler.RevokeSession)
+
+		// Reset password & verifikasi email
+		authRoutes.POST("/password/forgot", handler.ForgotPassword)
+		authRoutes.POST("/password/reset", handler.ResetPassword)
+		authRoutes.POST("/email/verify", handler.VerifyEmail)
+		authRoutes.POST("/email/resend", middleware.SessionAuthMiddleware(authService), handler.ResendVerification)
+	}
+}
+
+// setupTwoFactorRoutes mendaftarkan rute 2FA (TOTP) untuk admin & vendor.
+func setupTwoFactorRoutes(group *gin.RouterGroup, handler *handler.TwoFactorHandler, authService service.AuthService) {
+	twoFactorRoutes := group.Group("/auth/2fa")
+	{
+		// Langkah kedua login, memakai challenge_token dari /auth/login
+		twoFactorRoutes.POST("/verify", handler.Verify)
+
+		// Pendaftaran bisa memakai access token atau token tantangan login jika 2FA diwajibkan
+		canManage := middleware.RequirePermission(authz.TwoFactorManage)
+		twoFactorRoutes.POST("/enroll", middleware.EnrollmentAuthMiddleware(authService), canManage, handler.Enroll)
+		twoFactorRoutes.POST("/confirm", middleware.EnrollmentAuthMiddleware(authService), canManage, handler.Confirm)
+
+		twoFactorRoutes.GET("/", middleware.SessionAuthMiddleware(authService), canManage, handler.Status)
+		twoFactorRoutes.POST("/disable", middleware.SessionAuthMiddleware(authService), canManage, handler.Disable)
+		twoFactorRoutes.POST("/backup-codes", middleware.SessionAuthMiddleware(authService), canManage, handler.RegenerateBackupCodes)
+	}
+}
+
+// setupPhoneRoutes mendaftarkan rute verifikasi nomor telepon lewat OTP SMS.
+func setupPhoneRoutes(group *gin.RouterGroup, handler *handler.PhoneHandler, authService service.AuthService) {
+	phoneRoutes := group.Group("/auth/phone")
+	phoneRoutes.Use(middleware.SessionAuthMiddleware(authService))
+	{
+		phoneRoutes.POST("/otp", handler.SendOTP)
+		phoneRoutes.POST("/verify", handler.VerifyOTP)
+	}
+}
+
+// setupAccountRoutes mendaftarkan rute ekspor data pribadi dan penghapusan akun oleh user sendiri.
+// Hanya bisa diakses dengan login biasa, bukan API key.
+func setupAccountRoutes(group *gin.RouterGroup, handler *handler.AccountHandler, authService service.AuthService) {
+	accountRoutes := group.Group("/auth/me")
+	accountRoutes.Use(middleware.SessionAuthMiddleware(authService))
+	{
+		accountRoutes.GET("/export", handler.ExportData)
+		accountRoutes.DELETE("", handler.DeleteAccount)
+	}
+}
+
+// setupOIDCRoutes mendaftarkan rute login lewat penyedia OpenID Connect (misal Google).
+func setupOIDCRoutes(group *gin.RouterGroup, handler *handler.OIDCHandler) {
+	oidcRoutes := group.Group("/auth/oidc")
+	{
+		oidcRoutes.GET("/providers", handler.Providers)
+		oidcRoutes.POST("/:provider/authorize", handler.Authorize)
+		oidcRoutes.POST("/:provider/callback", handler.Callback)
+	}
+}
+
+// setupAPIKeyRoutes mendaftarkan rute pengelolaan API key milik vendor. Hanya bisa diakses dengan login biasa.
+func setupAPIKeyRoutes(group *gin.RouterGroup, handler *handler.APIKeyHandler, authService service.AuthService) {
+	apiKeyRoutes := group.Group("/auth/api-keys")
+	apiKeyRoutes.Use(middleware.SessionAuthMiddleware(authService), middleware.RequirePermission(authz.APIKeyManage))
+	{
+		apiKeyRoutes.GET("/", handler.ListKeys)
+		apiKeyRoutes.POST("/", handler.CreateKey)
+		apiKeyRoutes.DELETE("/:id", handler.RevokeKey)
 	}
 }
 
 // setupVehicleRoutes mendaftarkan semua rute yang berhubungan dengan kendaraan.
-func setupVehicleRoutes(group *gin.RouterGroup, handler *handler.VehicleHandler, jwtSecret string) {
+func setupVehicleRoutes(group *gin.RouterGroup, handler *handler.VehicleHandler, authService service.AuthService) {
 	vehicleRoutes := group.Group("/vehicles")
 	{
 		// Rute Publik
 		vehicleRoutes.GET("/", handler.GetAllVehicles)
 		vehicleRoutes.GET("/:id", handler.GetVehicleByID)
 
-		// Rute yang dilindungi (hanya untuk Vendor)
-		protectedVendorRoutes := vehicleRoutes.Use(middleware.AuthMiddleware(jwtSecret), middleware.RoleMiddleware("vendor"))
+		// Rute yang dilindungi (vendor untuk kendaraan miliknya, admin untuk semua kendaraan)
+		protectedRoutes := vehicleRoutes.Use(middleware.AuthMiddleware(authService))
+		canWrite := middleware.RequirePermission(authz.VehicleWriteOwn, authz.VehicleWriteAny)
 		{
-			protectedVendorRoutes.POST("/", handler.CreateVehicle)
-			protectedVendorRoutes.PUT("/:id", handler.UpdateVehicle)
-			protectedVendorRoutes.DELETE("/:id", handler.DeleteVehicle)
-			protectedVendorRoutes.POST("/:id/images", handler.UploadVehicleImage)
-			protectedVendorRoutes.GET("/my-listings", handler.GetMyListings)
+			protectedRoutes.POST("/", middleware.RequirePermission(authz.VehicleCreate), handler.CreateVehicle)
+			protectedRoutes.PUT("/:id", canWrite, handler.UpdateVehicle)
+			protectedRoutes.PATCH("/:id/status", canWrite, handler.UpdateVehicleStatus)
+			protectedRoutes.DELETE("/:id", canWrite, handler.DeleteVehicle)
+			protectedRoutes.POST("/:id/images", canWrite, handler.UploadVehicleImage)
+			protectedRoutes.POST("/:id/images/batch", canWrite, handler.UploadVehicleImages)
+			protectedRoutes.PUT("/:id/images/order", canWrite, handler.ReorderVehicleImages)
+			protectedRoutes.PATCH("/:id/images/:image_id/primary", canWrite, handler.SetPrimaryVehicleImage)
+			protectedRoutes.DELETE("/:id/images/:image_id", canWrite, handler.DeleteVehicleImage)
+			protectedRoutes.GET("/my-listings", middleware.RequirePermission(authz.VehicleReadOwn), handler.GetMyListings)
 		}
 	}
 }
 
 // setupBookingRoutes mendaftarkan semua rute yang berhubungan dengan booking.
-func setupBookingRoutes(group *gin.RouterGroup, handler *handler.BookingHandler, jwtSecret string) {
+func setupBookingRoutes(group *gin.RouterGroup, handler *handler.BookingHandler, authService service.AuthService) {
 	bookingRoutes := group.Group("/bookings")
-	bookingRoutes.Use(middleware.AuthMiddleware(jwtSecret)) // Semua rute booking butuh login
+	bookingRoutes.Use(middleware.AuthMiddleware(authService)) // Semua rute booking butuh login
 	{
 		// Rute khusus Customer
-		bookingRoutes.POST("/", middleware.RoleMiddleware("customer"), handler.CreateBooking)
-		bookingRoutes.GET("/my-bookings", middleware.RoleMiddleware("customer"), handler.GetMyBookings)
+		bookingRoutes.POST("/", middleware.RequirePermission(authz.BookingCreate), handler.CreateBooking)
+		bookingRoutes.GET("/my-bookings", middleware.RequirePermission(authz.BookingReadOwn), handler.GetMyBookings)
 
 		// Rute khusus Vendor
-		bookingRoutes.GET("/vendor", middleware.RoleMiddleware("vendor"), handler.GetVendorBookings)
-		bookingRoutes.PATCH("/:id/status", middleware.RoleMiddleware("vendor"), handler.UpdateBookingStatus)
+		bookingRoutes.GET("/vendor", middleware.RequirePermission(authz.BookingUpdateOwn), handler.GetVendorBookings)
+		bookingRoutes.PATCH("/:id/status", middleware.RequirePermission(authz.BookingUpdateOwn), handler.UpdateBookingStatus)
 
-		// Rute yang bisa diakses oleh Customer atau Vendor yang bersangkutan
-		bookingRoutes.GET("/:id", handler.GetBookingByID)
+		// Rute yang bisa diakses oleh Customer atau Vendor yang bersangkutan, dan Admin
+		bookingRoutes.GET("/:id", middleware.RequirePermission(authz.BookingReadOwn, authz.BookingReadAny), handler.GetBookingByID)
 	}
 }
 
@@ -174,48 +415,58 @@ func setupPaymentRoutes(group *gin.RouterGroup, handler *handler.BookingHandler)
 }
 
 // setupReviewRoutes mendaftarkan semua rute yang berhubungan dengan review.
-func setupReviewRoutes(group *gin.RouterGroup, handler *handler.ReviewHandler, jwtSecret string) {
+func setupReviewRoutes(group *gin.RouterGroup, handler *handler.ReviewHandler, authService service.AuthService) {
 	// Endpoint publik untuk melihat review sebuah kendaraan
 	group.GET("/vehicles/:id/reviews", handler.GetVehicleReviews)
 
 	// Endpoint dilindungi untuk membuat review
 	reviewCreationRoutes := group.Group("/bookings/:booking_id/reviews")
-	reviewCreationRoutes.Use(middleware.AuthMiddleware(jwtSecret), middleware.RoleMiddleware("customer"))
+	reviewCreationRoutes.Use(middleware.AuthMiddleware(authService), middleware.RequirePermission(authz.ReviewCreate))
 	{
 		reviewCreationRoutes.POST("/", handler.CreateReview)
 	}
 }
 
-func setupAdminRoutes(group *gin.RouterGroup, handler *handler.AdminHandler, jwtSecret string) {
+func setupAdminRoutes(group *gin.RouterGroup, handler *handler.AdminHandler, authService service.AuthService) {
 	adminRoutes := group.Group("/admin")
-	adminRoutes.Use(middleware.AuthMiddleware(jwtSecret), middleware.RoleMiddleware("admin"))
+	adminRoutes.Use(middleware.AuthMiddleware(authService))
 	{
 		// Rute Manajemen Vendor
-		adminRoutes.GET("/vendors", handler.GetVendors)
-		adminRoutes.PATCH("/vendors/:id/verify", handler.VerifyVendor)
+		adminRoutes.GET("/vendors", middleware.RequirePermission(authz.UserReadAny), handler.GetVendors)
+		adminRoutes.PATCH("/vendors/:id/verify", middleware.RequirePermission(authz.VendorVerify), handler.VerifyVendor)
+		adminRoutes.PATCH("/vendors/:id/unverify", middleware.RequirePermission(authz.VendorVerify), handler.UnverifyVendor)
 
 		// Rute Manajemen User
-		adminRoutes.GET("/users", handler.GetAllUsers)
-		adminRoutes.DELETE("/users/:id", handler.DeleteUser)
+		adminRoutes.GET("/users", middleware.RequirePermission(authz.UserReadAny), handler.GetAllUsers)
+		adminRoutes.DELETE("/users/:id", middleware.RequirePermission(authz.UserDeleteAny), handler.DeleteUser)
+		adminRoutes.DELETE("/users/:id/sessions", middleware.RequirePermission(authz.SessionRevokeAny), handler.RevokeUserSessions)
+
+		// Rute Proteksi Login (akun/IP yang diblokir karena brute-force)
+		adminRoutes.GET("/login-locks", middleware.RequirePermission(authz.LoginLockManage), handler.GetLoginLocks)
+		adminRoutes.DELETE("/login-locks/:scope/:key", middleware.RequirePermission(authz.LoginLockManage), handler.ClearLoginLock)
+
+		// Pengaturan platform (misal wajib 2FA untuk admin)
+		adminRoutes.GET("/settings", middleware.RequirePermission(authz.SettingsManage), handler.GetSettings)
+		adminRoutes.PATCH("/settings", middleware.RequirePermission(authz.SettingsManage), handler.UpdateSettings)
 
 		// Rute Manajemen Listing
-		adminRoutes.GET("/vehicles", handler.GetAllVehicles)
-		adminRoutes.DELETE("/vehicles/:id", handler.DeleteVehicle)
+		adminRoutes.GET("/vehicles", middleware.RequirePermission(authz.VehicleReadAny), handler.GetAllVehicles)
+		adminRoutes.DELETE("/vehicles/:id", middleware.RequirePermission(authz.VehicleWriteAny), handler.DeleteVehicle)
 	}
 }
 
-func setupSalesRoutes(group *gin.RouterGroup, handler *handler.SalesHandler, jwtSecret string) {
+func setupSalesRoutes(group *gin.RouterGroup, handler *handler.SalesHandler, authService service.AuthService) {
 	salesRoutes := group.Group("/sales")
-	salesRoutes.Use(middleware.AuthMiddleware(jwtSecret))
+	salesRoutes.Use(middleware.AuthMiddleware(authService))
 	{
 		// Rute untuk melihat riwayat pembelian (customer) & penjualan (vendor)
-		salesRoutes.GET("/purchases", middleware.RoleMiddleware("customer"), handler.GetMyPurchases)
-		salesRoutes.GET("/sales", middleware.RoleMiddleware("vendor"), handler.GetMySales)
+		salesRoutes.GET("/purchases", middleware.RequirePermission(authz.PurchaseReadOwn), handler.GetMyPurchases)
+		salesRoutes.GET("/sales", middleware.RequirePermission(authz.SaleReadOwn), handler.GetMySales)
 	}
 
 	// Rute untuk customer memulai pembelian sebuah mobil
 	purchaseRoutes := group.Group("/vehicles/:id/purchase")
-	purchaseRoutes.Use(middleware.AuthMiddleware(jwtSecret), middleware.RoleMiddleware("customer"))
+	purchaseRoutes.Use(middleware.AuthMiddleware(authService), middleware.RequirePermission(authz.PurchaseCreate))
 	{
 		purchaseRoutes.POST("/", handler.InitiatePurchase)
 	}
@@ -224,17 +475,17 @@ func setupSalesRoutes(group *gin.RouterGroup, handler *handler.SalesHandler, jwt
 	group.POST("/sales/callback", handler.PaymentCallback)
 }
 
-func setupChatRoutes(group *gin.RouterGroup, handler *handler.ChatHandler, jwtSecret string) {
+func setupChatRoutes(group *gin.RouterGroup, handler *handler.ChatHandler, authService service.AuthService) {
 	chatRoutes := group.Group("/conversations")
-	chatRoutes.Use(middleware.AuthMiddleware(jwtSecret))
+	chatRoutes.Use(middleware.AuthMiddleware(authService), middleware.RequirePermission(authz.ChatReadOwn))
 	{
 		chatRoutes.GET("/", handler.ListConversations)
 		chatRoutes.GET("/:id/messages", handler.GetMessages)
 	}
 
-	// Rute untuk memulai percakapan
+	// Rute untuk memulai percakapan (customer dengan pemilik kendaraan, atau vendor dengan customer)
 	startChatRoutes := group.Group("/vehicles/:id/conversations")
-	startChatRoutes.Use(middleware.AuthMiddleware(jwtSecret), middleware.RoleMiddleware("customer"))
+	startChatRoutes.Use(middleware.AuthMiddleware(authService), middleware.RequirePermission(authz.ChatStart))
 	{
 		startChatRoutes.POST("/", handler.StartConversation)
 	}
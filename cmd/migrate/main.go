@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sultra-otomotif-api/internal/config"
+	"sultra-otomotif-api/internal/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usage = `Usage: migrate <command> [argument]
+
+Commands:
+  up             Menjalankan semua migrasi yang belum diterapkan
+  down [n]       Membatalkan n migrasi terakhir (default 1)
+  status         Menampilkan status setiap migrasi
+  to <version>   Menaikkan atau menurunkan skema ke versi tertentu (0 = kosong)`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	cfg := config.LoadConfig()
+	if cfg.DBSource == "" {
+		log.Fatal("FATAL: DB_SOURCE is not set.")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, cfg.DBSource)
+	if err != nil {
+		log.Fatalf("FATAL: Unable to connect to database: %v\n", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("FATAL: Unable to load migrations: %v", err)
+	}
+
+	command, arg := os.Args[1], ""
+	if len(os.Args) > 2 {
+		arg = os.Args[2]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		report("Applied", applied, err)
+	case "down":
+		steps := 1
+		if arg != "" {
+			steps, err = strconv.Atoi(arg)
+			if err != nil {
+				log.Fatalf("FATAL: Invalid step count %q", arg)
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		report("Reverted", reverted, err)
+	case "to":
+		if arg == "" {
+			log.Fatal("FATAL: Target version is required")
+		}
+		version, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			log.Fatalf("FATAL: Invalid version %q", arg)
+		}
+		changed, err := migrator.To(ctx, version)
+		report("Migrated", changed, err)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
+
+func report(verb string, migrations []database.Migration, err error) {
+	for _, m := range migrations {
+		log.Printf("%s %04d_%s", verb, m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	if len(migrations) == 0 {
+		log.Println("Nothing to do, schema is already at the requested version")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"sultra-otomotif-api/internal/config"
+	"sultra-otomotif-api/internal/database"
+	"sultra-otomotif-api/internal/repository"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	seed := flag.Int64("seed", 42, "seed untuk data acak; seed yang sama menghasilkan data yang sama")
+	baseDate := flag.String("base-date", time.Now().UTC().Format("2006-01-02"), "tanggal acuan untuk booking (YYYY-MM-DD)")
+	flag.Parse()
+
+	base, err := time.Parse("2006-01-02", *baseDate)
+	if err != nil {
+		log.Fatalf("FATAL: Invalid -base-date: %v", err)
+	}
+
+	cfg := config.LoadConfig()
+	if cfg.DBSource == "" {
+		log.Fatal("FATAL: DB_SOURCE is not set.")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, cfg.DBSource)
+	if err != nil {
+		log.Fatalf("FATAL: Unable to connect to database: %v\n", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("FATAL: Unable to load migrations: %v", err)
+	}
+	if err := migrator.EnsureUpToDate(ctx); err != nil {
+		log.Fatalf("FATAL: %v. Run `go run ./cmd/migrate up` first.", err)
+	}
+
+	s := newSeeder(*seed, base, seederRepositories{
+		users:    repository.NewUserRepository(db),
+		vehicles: repository.NewVehicleRepository(db),
+		images:   repository.NewImageRepository(db),
+		bookings: repository.NewBookingRepository(db),
+		reviews:  repository.NewReviewRepository(db),
+		sales:    repository.NewSalesRepository(db),
+		chats:    repository.NewChatRepository(db),
+	})
+
+	if err := s.run(ctx); err != nil {
+		log.Fatalf("FATAL: Seeding failed: %v", err)
+	}
+	log.Printf("Seeding finished: %d records created, %d already existed", s.created, s.skipped)
+	log.Printf("All seeded accounts use the password %q", seedPassword)
+}
@@ -0,0 +1,521 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sultra-otomotif-api/internal/geocode"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// seedPassword dipakai oleh semua akun hasil seed agar mudah login saat demo/QA
+const seedPassword = "password123"
+
+// seedNamespace adalah namespace UUIDv5 untuk membuat ID yang stabil di setiap run
+var seedNamespace = uuid.MustParse("6f1d7c2e-3a8b-4c55-9d0e-2b7a5e4f9c10")
+
+type seederRepositories struct {
+	users    repository.UserRepository
+	vehicles repository.VehicleRepository
+	images   repository.ImageRepository
+	bookings repository.BookingRepository
+	reviews  repository.ReviewRepository
+	sales    repository.SalesRepository
+	chats    repository.ChatRepository
+}
+
+type seeder struct {
+	repos    seederRepositories
+	seed     int64
+	rnd      *rand.Rand
+	baseDate time.Time
+	geocoder geocode.Geocoder
+
+	created int
+	skipped int
+}
+
+func newSeeder(seed int64, baseDate time.Time, repos seederRepositories) *seeder {
+	return &seeder{
+		repos:    repos,
+		seed:     seed,
+		rnd:      rand.New(rand.NewSource(seed)),
+		baseDate: baseDate,
+		geocoder: geocode.NewStaticGeocoder(),
+	}
+}
+
+// id menghasilkan UUID deterministik dari seed dan sebuah kunci fixture
+func (s *seeder) id(key string) uuid.UUID {
+	return uuid.NewSHA1(seedNamespace, []byte(fmt.Sprintf("%d:%s", s.seed, key)))
+}
+
+// day mengembalikan tanggal baseDate + offset hari
+func (s *seeder) day(offset int) time.Time {
+	return s.baseDate.AddDate(0, 0, offset)
+}
+
+func (s *seeder) run(ctx context.Context) error {
+	users, err := s.seedUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("users: %w", err)
+	}
+	vehicles, err := s.seedVehicles(ctx, users)
+	if err != nil {
+		return fmt.Errorf("vehicles: %w", err)
+	}
+	if err := s.seedBookings(ctx, users, vehicles); err != nil {
+		return fmt.Errorf("bookings: %w", err)
+	}
+	if err := s.seedSales(ctx, users, vehicles); err != nil {
+		return fmt.Errorf("sales: %w", err)
+	}
+	if err := s.seedConversations(ctx, users, vehicles); err != nil {
+		return fmt.Errorf("conversations: %w", err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Users
+
+type userFixture struct {
+	key      string
+	fullName string
+	email    string
+	phone    string
+	role     string
+	verified bool
+}
+
+var userFixtures = []userFixture{
+	{key: "admin", fullName: "Admin Sultra Otomotif", email: "admin@sultra-otomotif.test", phone: "+6281100000001", role: "admin"},
+	{key: "vendor-kendari", fullName: "Kendari Rent Car", email: "vendor.kendari@sultra-otomotif.test", phone: "+6281200000001", role: "vendor", verified: true},
+	{key: "vendor-baubau", fullName: "Baubau Motor Sejahtera", email: "vendor.baubau@sultra-otomotif.test", phone: "+6281200000002", role: "vendor", verified: true},
+	{key: "vendor-unverified", fullName: "Kolaka Jaya Mobilindo", email: "vendor.baru@sultra-otomotif.test", phone: "+6281200000003", role: "vendor"},
+	{key: "customer-andi", fullName: "Andi Saputra", email: "andi@sultra-otomotif.test", phone: "+6281300000001", role: "customer"},
+	{key: "customer-siti", fullName: "Siti Rahmawati", email: "siti@sultra-otomotif.test", phone: "+6281300000002", role: "customer"},
+	{key: "customer-budi", fullName: "Budi La Ode", email: "budi@sultra-otomotif.test", phone: "+6281300000003", role: "customer"},
+	{key: "customer-wa-ode", fullName: "Wa Ode Nurhaliza", email: "waode@sultra-otomotif.test", phone: "+6281300000004", role: "customer"},
+}
+
+func (s *seeder) seedUsers(ctx context.Context) (map[string]model.User, error) {
+	passwordHash, err := helper.HashPassword(seedPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	users := map[string]model.User{}
+	for _, f := range userFixtures {
+		id := s.id("user:" + f.key)
+		user, err := s.repos.users.FindByID(ctx, id)
+		if err == nil {
+			s.skipped++
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			user, err = s.repos.users.Save(ctx, model.User{
+				ID:           id,
+				FullName:     f.fullName,
+				Email:        f.email,
+				PasswordHash: passwordHash,
+				PhoneNumber:  f.phone,
+				Role:         f.role,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("save %s: %w", f.email, err)
+			}
+			s.created++
+			log.Printf("Created %s %s", f.role, f.email)
+		} else {
+			return nil, err
+		}
+
+		// Semua akun contoh dianggap sudah mengonfirmasi email dan nomor teleponnya
+		if user.EmailVerifiedAt == nil {
+			if _, err := s.repos.users.MarkEmailVerified(ctx, id, user.Email); err != nil {
+				return nil, err
+			}
+		}
+		if user.PhoneVerifiedAt == nil {
+			if _, err := s.repos.users.MarkPhoneVerified(ctx, id, user.PhoneNumber); err != nil {
+				return nil, err
+			}
+		}
+		if f.verified && !user.IsVerified {
+			if err := s.repos.users.UpdateVerificationStatus(ctx, id, true); err != nil {
+				return nil, err
+			}
+			user.IsVerified = true
+		}
+		users[f.key] = user
+	}
+	return users, nil
+}
+
+// ---------------------------------------------------------------------------
+// Vehicles
+
+type vehicleFixture struct {
+	key          string
+	owner        string
+	brand        string
+	model        string
+	vehicleType  string
+	transmission string
+	fuel         string
+	location     string
+	forSale      bool
+	forRent      bool
+	features     []string
+	// status listing, kosong berarti published. Status maintenance memakai jendela
+	// baseDate+maintenanceFrom selama maintenanceDays hari.
+	status          string
+	maintenanceFrom int
+	maintenanceDays int
+}
+
+var vehicleFixtures = []vehicleFixture{
+	{key: "avanza", owner: "vendor-kendari", brand: "Toyota", model: "Avanza", vehicleType: "mobil", transmission: "manual", fuel: "bensin", location: "Kendari", forRent: true, features: []string{"AC", "7 Kursi", "Audio Bluetooth"}},
+	{key: "innova", owner: "vendor-kendari", brand: "Toyota", model: "Kijang Innova", vehicleType: "mobil", transmission: "matic", fuel: "diesel", location: "Kendari", forRent: true, features: []string{"AC Double Blower", "7 Kursi", "Kamera Mundur"}},
+	{key: "xpander", owner: "vendor-kendari", brand: "Mitsubishi", model: "Xpander", vehicleType: "mobil", transmission: "matic", fuel: "bensin", location: "Kendari", forSale: true, forRent: true, features: []string{"AC", "Keyless Entry", "Head Unit Android"}},
+	{key: "brio", owner: "vendor-kendari", brand: "Honda", model: "Brio", vehicleType: "mobil", transmission: "matic", fuel: "bensin", location: "Kendari", forSale: true, features: []string{"AC", "Irit BBM"}},
+	{key: "vario", owner: "vendor-kendari", brand: "Honda", model: "Vario 160", vehicleType: "motor", transmission: "matic", fuel: "bensin", location: "Kendari", forRent: true, features: []string{"Smart Key", "USB Charger"}, status: "maintenance", maintenanceFrom: 20, maintenanceDays: 7},
+	{key: "hilux", owner: "vendor-baubau", brand: "Toyota", model: "Hilux", vehicleType: "mobil", transmission: "manual", fuel: "diesel", location: "Baubau", forSale: true, forRent: true, features: []string{"4x4", "Bak Terbuka"}},
+	{key: "ertiga", owner: "vendor-baubau", brand: "Suzuki", model: "Ertiga", vehicleType: "mobil", transmission: "manual", fuel: "bensin", location: "Baubau", forRent: true, features: []string{"AC", "7 Kursi"}},
+	{key: "nmax", owner: "vendor-baubau", brand: "Yamaha", model: "NMAX", vehicleType: "motor", transmission: "matic", fuel: "bensin", location: "Baubau", forSale: true, forRent: true, features: []string{"ABS", "Smart Key"}},
+	{key: "ioniq", owner: "vendor-baubau", brand: "Hyundai", model: "Ioniq 5", vehicleType: "mobil", transmission: "matic", fuel: "listrik", location: "Baubau", forSale: true, features: []string{"Fast Charging", "Sunroof", "ADAS"}},
+	{key: "klx", owner: "vendor-baubau", brand: "Kawasaki", model: "KLX 150", vehicleType: "motor", transmission: "manual", fuel: "bensin", location: "Baubau", forRent: true, features: []string{"Trail", "Ban Offroad"}},
+	{key: "fortuner", owner: "vendor-kendari", brand: "Toyota", model: "Fortuner", vehicleType: "mobil", transmission: "matic", fuel: "diesel", location: "Kendari", forSale: true, features: []string{"4x4", "Sunroof"}, status: "draft"},
+}
+
+var seedColors = []string{"Hitam", "Putih", "Silver", "Merah", "Abu-abu", "Biru"}
+
+func (s *seeder) seedVehicles(ctx context.Context, users map[string]model.User) (map[string]model.Vehicle, error) {
+	vehicles := map[string]model.Vehicle{}
+	for i, f := range vehicleFixtures {
+		// Nilai acak selalu diambil agar urutan rnd stabil, baik data baru maupun yang sudah ada
+		year := 2016 + s.rnd.Intn(9)
+		color := seedColors[s.rnd.Intn(len(seedColors))]
+		priceFactor := 1 + s.rnd.Float64()
+
+		id := s.id("vehicle:" + f.key)
+		vehicle, err := s.repos.vehicles.FindByID(ctx, id)
+		if err == nil {
+			s.skipped++
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			newVehicle := model.Vehicle{
+				ID:           id,
+				OwnerID:      users[f.owner].ID,
+				Brand:        f.brand,
+				Model:        f.model,
+				Year:         year,
+				PlateNumber:  fmt.Sprintf("DT %04d S%c", 1000+i*37, 'A'+rune(i)),
+				Color:        &color,
+				VehicleType:  f.vehicleType,
+				Transmission: f.transmission,
+				Fuel:         f.fuel,
+				Status:       "published",
+				IsForSale:    f.forSale,
+				IsForRent:    f.forRent,
+				Location:     &f.location,
+				Features:     f.features,
+			}
+			if f.status != "" {
+				newVehicle.Status = f.status
+			}
+			if f.status == "maintenance" {
+				start, end := s.day(f.maintenanceFrom), s.day(f.maintenanceFrom+f.maintenanceDays-1)
+				newVehicle.MaintenanceStart, newVehicle.MaintenanceEnd = &start, &end
+			}
+			if point, err := s.geocoder.Geocode(ctx, f.location); err == nil {
+				newVehicle.Latitude, newVehicle.Longitude = &point.Latitude, &point.Longitude
+			}
+			description := fmt.Sprintf("%s %s tahun %d, kondisi terawat dan siap pakai di %s.", f.brand, f.model, year, f.location)
+			newVehicle.Description = &description
+
+			if f.vehicleType == "mobil" {
+				newVehicle.SalePrice, newVehicle.RentalPriceDaily = s.prices(f, priceFactor, 150_000_000, 300_000)
+			} else {
+				newVehicle.SalePrice, newVehicle.RentalPriceDaily = s.prices(f, priceFactor, 20_000_000, 75_000)
+			}
+			if newVehicle.RentalPriceDaily != nil {
+				weekly := roundThousand(*newVehicle.RentalPriceDaily * 6)
+				monthly := roundThousand(*newVehicle.RentalPriceDaily * 22)
+				newVehicle.RentalPriceWeekly = &weekly
+				newVehicle.RentalPriceMonthly = &monthly
+			}
+
+			if _, err := s.repos.vehicles.Create(ctx, newVehicle); err != nil {
+				return nil, fmt.Errorf("create %s: %w", f.key, err)
+			}
+			s.created++
+			log.Printf("Created vehicle %s %s", f.brand, f.model)
+
+			vehicle, err = s.repos.vehicles.FindByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, err
+		}
+
+		if len(vehicle.Images) == 0 {
+			for n := 1; n <= 3; n++ {
+				imageURL := fmt.Sprintf("https://picsum.photos/seed/sultra-%s-%d/1200/800", f.key, n)
+				if _, err := s.repos.images.SaveVehicleImage(ctx, model.VehicleImage{VehicleID: id, ImageURL: imageURL}); err != nil {
+					return nil, err
+				}
+				s.created++
+			}
+		}
+		vehicles[f.key] = vehicle
+	}
+	return vehicles, nil
+}
+
+func (s *seeder) prices(f vehicleFixture, factor, baseSale, baseDaily float64) (*float64, *float64) {
+	var salePrice, dailyPrice *float64
+	if f.forSale {
+		price := roundThousand(baseSale * factor)
+		salePrice = &price
+	}
+	if f.forRent {
+		price := roundThousand(baseDaily * factor)
+		dailyPrice = &price
+	}
+	return salePrice, dailyPrice
+}
+
+func roundThousand(f float64) float64 {
+	return float64(int64(f/1000) * 1000)
+}
+
+// ---------------------------------------------------------------------------
+// Bookings & reviews
+
+type bookingFixture struct {
+	key      string
+	customer string
+	vehicle  string
+	start    int
+	days     int
+	status   string
+	rating   int
+	comment  string
+}
+
+// bookingFixtures mencakup semua status di state machine BookingService.UpdateBookingStatus.
+// Booking confirmed/rented_out pada kendaraan yang sama tidak boleh tumpang tindih.
+var bookingFixtures = []bookingFixture{
+	{key: "pending-avanza", customer: "customer-andi", vehicle: "avanza", start: 10, days: 3, status: "pending_payment"},
+	{key: "pending-nmax", customer: "customer-budi", vehicle: "nmax", start: 2, days: 2, status: "pending_payment"},
+	{key: "confirmed-avanza", customer: "customer-siti", vehicle: "avanza", start: 3, days: 3, status: "confirmed"},
+	{key: "confirmed-ertiga", customer: "customer-wa-ode", vehicle: "ertiga", start: 1, days: 5, status: "confirmed"},
+	{key: "rented-innova", customer: "customer-budi", vehicle: "innova", start: -1, days: 4, status: "rented_out"},
+	{key: "rented-klx", customer: "customer-andi", vehicle: "klx", start: -2, days: 3, status: "rented_out"},
+	{key: "completed-innova", customer: "customer-andi", vehicle: "innova", start: -20, days: 4, status: "completed", rating: 5, comment: "Mobil bersih, vendor ramah dan tepat waktu."},
+	{key: "completed-xpander", customer: "customer-siti", vehicle: "xpander", start: -40, days: 3, status: "completed", rating: 4, comment: "Nyaman untuk perjalanan ke Konawe."},
+	{key: "completed-vario", customer: "customer-wa-ode", vehicle: "vario", start: -15, days: 2, status: "completed"},
+	{key: "cancelled-xpander", customer: "customer-wa-ode", vehicle: "xpander", start: 5, days: 2, status: "cancelled"},
+	{key: "cancelled-hilux", customer: "customer-budi", vehicle: "hilux", start: -8, days: 3, status: "cancelled"},
+}
+
+func (s *seeder) seedBookings(ctx context.Context, users map[string]model.User, vehicles map[string]model.Vehicle) error {
+	for _, f := range bookingFixtures {
+		vehicle := vehicles[f.vehicle]
+		if vehicle.RentalPriceDaily == nil {
+			return fmt.Errorf("vehicle %s has no rental price", f.vehicle)
+		}
+
+		id := s.id("booking:" + f.key)
+		booking, err := s.repos.bookings.FindBookingByID(ctx, id)
+		if err == nil {
+			s.skipped++
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			booking, err = s.repos.bookings.Create(ctx, model.Booking{
+				ID:         id,
+				UserID:     users[f.customer].ID,
+				VehicleID:  vehicle.ID,
+				StartDate:  s.day(f.start),
+				EndDate:    s.day(f.start + f.days - 1),
+				TotalPrice: float64(f.days) * *vehicle.RentalPriceDaily,
+				Status:     f.status,
+			})
+			if err != nil {
+				return fmt.Errorf("create %s: %w", f.key, err)
+			}
+			s.created++
+		} else {
+			return err
+		}
+
+		if f.rating == 0 {
+			continue
+		}
+		if err := s.seedReview(ctx, f, booking); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *seeder) seedReview(ctx context.Context, f bookingFixture, booking model.Booking) error {
+	id := s.id("review:" + f.key)
+	existing, err := s.repos.reviews.FindByVehicleID(ctx, booking.VehicleID, model.PageRequest{})
+	if err != nil {
+		return err
+	}
+	for _, review := range existing.Items {
+		if review.ID == id {
+			s.skipped++
+			return nil
+		}
+	}
+
+	_, err = s.repos.reviews.Create(ctx, model.Review{
+		ID:        id,
+		BookingID: booking.ID,
+		UserID:    booking.UserID,
+		VehicleID: booking.VehicleID,
+		Rating:    f.rating,
+		Comment:   f.comment,
+	})
+	if err != nil {
+		return fmt.Errorf("review %s: %w", f.key, err)
+	}
+	s.created++
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Sales
+
+type saleFixture struct {
+	key     string
+	buyer   string
+	vehicle string
+	status  string
+}
+
+var saleFixtures = []saleFixture{
+	{key: "pending-brio", buyer: "customer-budi", vehicle: "brio", status: "payment_pending"},
+	{key: "completed-ioniq", buyer: "customer-siti", vehicle: "ioniq", status: "completed"},
+}
+
+func (s *seeder) seedSales(ctx context.Context, users map[string]model.User, vehicles map[string]model.Vehicle) error {
+	for _, f := range saleFixtures {
+		vehicle := vehicles[f.vehicle]
+		if vehicle.SalePrice == nil {
+			return fmt.Errorf("vehicle %s has no sale price", f.vehicle)
+		}
+
+		id := s.id("sale:" + f.key)
+		_, err := s.repos.sales.FindByID(ctx, id)
+		if err == nil {
+			s.skipped++
+			continue
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		_, err = s.repos.sales.Create(ctx, model.SalesTransaction{
+			ID:          id,
+			VehicleID:   vehicle.ID,
+			SellerID:    vehicle.OwnerID,
+			BuyerID:     users[f.buyer].ID,
+			AgreedPrice: *vehicle.SalePrice,
+			Status:      f.status,
+		})
+		if err != nil {
+			return fmt.Errorf("create %s: %w", f.key, err)
+		}
+		s.created++
+
+		// Sama seperti SalesService.ConfirmSale: kendaraan yang terjual ditarik dari pasar
+		if f.status == "completed" && vehicle.Status != "sold" {
+			vehicle.IsForSale = false
+			vehicle.IsForRent = false
+			if _, err := s.repos.vehicles.Update(ctx, vehicle); err != nil {
+				return err
+			}
+			fromStatus := vehicle.Status
+			vehicle.Status = "sold"
+			vehicle.MaintenanceStart, vehicle.MaintenanceEnd = nil, nil
+			if _, err := s.repos.vehicles.UpdateStatus(ctx, vehicle, fromStatus); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Conversations
+
+type conversationFixture struct {
+	customer string
+	vehicle  string
+	messages []string // Bergantian: customer, vendor, customer, ...
+}
+
+var conversationFixtures = []conversationFixture{
+	{customer: "customer-andi", vehicle: "avanza", messages: []string{
+		"Halo, Avanza-nya bisa diantar ke Bandara Haluoleo?",
+		"Bisa kak, ada biaya antar Rp50.000.",
+		"Oke, saya booking untuk minggu depan ya.",
+	}},
+	{customer: "customer-budi", vehicle: "brio", messages: []string{
+		"Brio-nya masih bisa nego?",
+		"Harga sudah pas kak, tapi bonus servis pertama gratis.",
+	}},
+	{customer: "customer-siti", vehicle: "hilux", messages: []string{
+		"Hilux ini sudah 4x4 ya pak?",
+	}},
+}
+
+func (s *seeder) seedConversations(ctx context.Context, users map[string]model.User, vehicles map[string]model.Vehicle) error {
+	for _, f := range conversationFixtures {
+		customerID := users[f.customer].ID
+		vehicle := vehicles[f.vehicle]
+
+		convo, err := s.repos.chats.FindOrCreateConversation(ctx, customerID, vehicle.OwnerID, vehicle.ID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.repos.chats.FindMessagesByConversationID(ctx, convo.ID, model.PageRequest{Limit: 1})
+		if err != nil {
+			return err
+		}
+		if len(existing.Items) > 0 {
+			s.skipped++
+			continue
+		}
+
+		for i, content := range f.messages {
+			sender, recipient := customerID, vehicle.OwnerID
+			if i%2 == 1 {
+				sender, recipient = recipient, sender
+			}
+			_, err := s.repos.chats.SaveMessage(ctx, model.Message{
+				ConversationID: convo.ID,
+				SenderID:       sender,
+				RecipientID:    recipient,
+				Content:        content,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		s.created++
+	}
+	return nil
+}
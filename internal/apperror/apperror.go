@@ -0,0 +1,144 @@
+// Package apperror berisi error domain bertipe yang dipakai oleh semua service.
+// Setiap error memiliki Kind (menentukan HTTP status), Code yang stabil untuk
+// dibaca mesin, pesan untuk manusia, dan detail per field yang opsional.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Kind adalah kategori error yang dipetakan ke HTTP status code
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindRateLimited  Kind = "rate_limited"
+	KindInternal     Kind = "internal"
+)
+
+// Error adalah error domain yang dikembalikan oleh layer service
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  map[string]string
+	Err     error
+	// RetryAfter diisi untuk KindRateLimited; dikirim ke klien sebagai header Retry-After
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is membuat errors.Is(err, ErrSesuatu) cocok berdasarkan Code, walaupun error sudah di-copy lewat WithField/Wrap
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithField mengembalikan salinan error dengan tambahan detail untuk sebuah field input
+func (e *Error) WithField(field, reason string) *Error {
+	clone := *e
+	clone.Fields = make(map[string]string, len(e.Fields)+1)
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	clone.Fields[field] = reason
+	return &clone
+}
+
+// Wrap mengembalikan salinan error yang membungkus error penyebabnya
+func (e *Error) Wrap(err error) *Error {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+// WithRetryAfter mengembalikan salinan error dengan waktu tunggu sebelum klien boleh mencoba lagi
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	clone := *e
+	clone.RetryAfter = d
+	return &clone
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func RateLimited(code, message string) *Error {
+	return New(KindRateLimited, code, message)
+}
+
+// Internal membungkus error tak terduga (misal dari database) dengan pesan yang aman ditampilkan
+func Internal(code, message string, err error) *Error {
+	return &Error{Kind: KindInternal, Code: code, Message: message, Err: err}
+}
+
+// As mengambil *Error dari rantai error, jika ada
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// IsKind mengecek apakah err adalah *Error dengan kind tertentu
+func IsKind(err error, kind Kind) bool {
+	appErr, ok := As(err)
+	return ok && appErr.Kind == kind
+}
+
+// HTTPStatus memetakan Kind ke HTTP status code
+func HTTPStatus(kind Kind) int {
+	switch kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
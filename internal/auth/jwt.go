@@ -1,25 +1,118 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-func GenerateToken(userID uuid.UUID, role string, secretKey string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"role":    role,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(), // Token berlaku 3 hari
-		"iat":     time.Now().Unix(),
+// Claims adalah isi access token. SessionID ("sid") menghubungkan token ke sesi di database
+// sehingga token bisa dicabut sebelum kedaluwarsa.
+type Claims struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Role      string    `json:"role"`
+	SessionID uuid.UUID `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+func GenerateToken(userID uuid.UUID, role string, sessionID uuid.UUID, keys *KeySet, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := Claims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(secretKey))
+	signedToken, err := keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signedToken, expiresAt, nil
+}
+
+// ParseToken memverifikasi tanda tangan & masa berlaku token lalu mengembalikan claims-nya
+func ParseToken(tokenString string, keys *KeySet) (*Claims, error) {
+	claims := &Claims{}
+	token, err := keys.Parse(tokenString, claims, jwt.WithExpirationRequired())
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if !token.Valid || claims.UserID == uuid.Nil || claims.SessionID == uuid.Nil {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// ChallengeClaims adalah isi token tantangan berumur pendek yang diterbitkan setelah password benar
+// tapi sebelum login selesai (misal menunggu kode 2FA). Token ini tidak membawa "sid" sehingga
+// ditolak oleh ParseToken, dan access token tidak membawa "purpose" sehingga ditolak di sini.
+// "jti" unik per token agar token bisa ditandai terpakai.
+type ChallengeClaims struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Role    string    `json:"role"`
+	Purpose string    `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+func GenerateChallengeToken(userID uuid.UUID, role, purpose string, keys *KeySet, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := ChallengeClaims{
+		UserID:  userID,
+		Role:    role,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
 
-	return signedToken, nil
+	signedToken, err := keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signedToken, expiresAt, nil
+}
+
+// ParseChallengeToken memverifikasi token tantangan dan memastikan tujuannya sesuai
+func ParseChallengeToken(tokenString string, purpose string, keys *KeySet) (*ChallengeClaims, error) {
+	claims := &ChallengeClaims{}
+	token, err := keys.Parse(tokenString, claims, jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.UserID == uuid.Nil || claims.Purpose != purpose || claims.ID == "" {
+		return nil, errors.New("invalid challenge token claims")
+	}
+	return claims, nil
+}
+
+// GenerateOpaqueToken membuat token acak yang aman untuk URL beserta hash SHA-256-nya.
+// Hanya hash yang disimpan di database.
+func GenerateOpaqueToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken menghasilkan hash SHA-256 (hex) dari token opaque
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
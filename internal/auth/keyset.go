@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key adalah satu kunci JWT yang dikenali lewat key ID ("kid")
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{} // nil jika kunci hanya untuk verifikasi
+	verifyKey interface{}
+}
+
+// KeySet berisi satu kunci penanda tangan dan beberapa kunci verifikasi yang masih aktif.
+// Dengan begitu secret/kunci bisa dirotasi tanpa membuat semua user logout: kunci lama
+// tetap diterima untuk verifikasi sampai semua token yang ditandatanganinya kedaluwarsa.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	order   []string
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: map[string]*Key{}}
+}
+
+// LoadKeySet membangun KeySet dari konfigurasi:
+//   - currentSecret: secret HMAC (HS256) yang aktif
+//   - previousSecrets: secret HMAC lama yang hanya dipakai untuk verifikasi
+//   - privateKeyFile: file PEM RSA (RS256) atau Ed25519 (EdDSA); jika diisi, kunci ini yang menandatangani
+//   - publicKeyFiles: file PEM kunci publik lama yang hanya dipakai untuk verifikasi
+func LoadKeySet(currentSecret string, previousSecrets []string, privateKeyFile string, publicKeyFiles []string) (*KeySet, error) {
+	ks := NewKeySet()
+
+	if currentSecret != "" {
+		key, err := ks.AddHMACKey([]byte(currentSecret))
+		if err != nil {
+			return nil, err
+		}
+		ks.signing = key
+	}
+	for _, secret := range previousSecrets {
+		if _, err := ks.AddHMACKey([]byte(secret)); err != nil {
+			return nil, err
+		}
+	}
+
+	if privateKeyFile != "" {
+		pemBytes, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read JWT private key: %w", err)
+		}
+		key, err := ks.AddPrivateKeyPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("load JWT private key: %w", err)
+		}
+		ks.signing = key
+	}
+	for _, file := range publicKeyFiles {
+		pemBytes, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read JWT public key: %w", err)
+		}
+		if _, err := ks.AddPublicKeyPEM(pemBytes); err != nil {
+			return nil, fmt.Errorf("load JWT public key %s: %w", file, err)
+		}
+	}
+
+	if ks.signing == nil {
+		return nil, errors.New("no JWT signing key configured")
+	}
+	return ks, nil
+}
+
+// AddHMACKey menambahkan secret HS256. Key ID diturunkan dari hash secret sehingga stabil antar restart.
+func (ks *KeySet) AddHMACKey(secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("empty HMAC secret")
+	}
+	sum := sha256.Sum256(secret)
+	return ks.add(&Key{
+		ID:        "hs-" + hex.EncodeToString(sum[:6]),
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	})
+}
+
+// AddPrivateKeyPEM menambahkan kunci privat RSA atau Ed25519 yang bisa menandatangani dan memverifikasi
+func (ks *KeySet) AddPrivateKeyPEM(pemBytes []byte) (*Key, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if rsaErr != nil {
+			return nil, err
+		}
+		privateKey = rsaKey
+	}
+
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return ks.addAsymmetric(jwt.SigningMethodRS256, k, &k.PublicKey)
+	case ed25519.PrivateKey:
+		return ks.addAsymmetric(jwt.SigningMethodEdDSA, k, k.Public())
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+}
+
+// AddPublicKeyPEM menambahkan kunci publik RSA atau Ed25519 yang hanya dipakai untuk verifikasi
+func (ks *KeySet) AddPublicKeyPEM(pemBytes []byte) (*Key, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		rsaKey, rsaErr := x509.ParsePKCS1PublicKey(block.Bytes)
+		if rsaErr != nil {
+			return nil, err
+		}
+		publicKey = rsaKey
+	}
+
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return ks.addAsymmetric(jwt.SigningMethodRS256, nil, k)
+	case ed25519.PublicKey:
+		return ks.addAsymmetric(jwt.SigningMethodEdDSA, nil, k)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+func (ks *KeySet) addAsymmetric(method jwt.SigningMethod, signKey interface{}, publicKey crypto.PublicKey) (*Key, error) {
+	jwk, err := publicJWK(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return ks.add(&Key{
+		ID:        jwk.thumbprint(),
+		Method:    method,
+		signKey:   signKey,
+		verifyKey: publicKey,
+	})
+}
+
+func (ks *KeySet) add(key *Key) (*Key, error) {
+	if existing, ok := ks.keys[key.ID]; ok {
+		// Kunci yang sama bisa muncul sebagai privat dan publik; pertahankan yang bisa menandatangani
+		if existing.signKey == nil && key.signKey != nil {
+			existing.signKey = key.signKey
+		}
+		return existing, nil
+	}
+	ks.keys[key.ID] = key
+	ks.order = append(ks.order, key.ID)
+	return key, nil
+}
+
+// SigningKeyID mengembalikan kid dari kunci yang sedang dipakai untuk menandatangani
+func (ks *KeySet) SigningKeyID() string {
+	return ks.signing.ID
+}
+
+// Sign menandatangani claims dengan kunci aktif dan menaruh kid di header token
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.signKey)
+}
+
+// Parse memverifikasi token dengan kunci yang sesuai kid di header-nya
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	options = append(options, jwt.WithValidMethods(ks.validMethods()))
+	return jwt.ParseWithClaims(tokenString, claims, ks.keyfunc, options...)
+}
+
+func (ks *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		// Token lama tanpa kid: coba semua kunci dengan algoritma yang sama
+		var candidates []jwt.VerificationKey
+		for _, id := range ks.order {
+			if key := ks.keys[id]; key.Method.Alg() == token.Method.Alg() {
+				candidates = append(candidates, key.verifyKey)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, errors.New("no key available for token algorithm")
+		}
+		return jwt.VerificationKeySet{Keys: candidates}, nil
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	// Cegah serangan algorithm confusion: algoritma token harus sama dengan algoritma kunci
+	if key.Method.Alg() != token.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.verifyKey, nil
+}
+
+func (ks *KeySet) validMethods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, id := range ks.order {
+		alg := ks.keys[id].Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWK adalah representasi JSON Web Key (RFC 7517) untuk kunci publik
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan semua kunci publik asimetris. Secret HMAC tidak pernah dipublikasikan.
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, id := range ks.order {
+		key := ks.keys[id]
+		if _, isHMAC := key.Method.(*jwt.SigningMethodHMAC); isHMAC {
+			continue
+		}
+		jwk, err := publicJWK(key.verifyKey)
+		if err != nil {
+			continue
+		}
+		jwk.Kid = key.ID
+		jwk.Use = "sig"
+		jwk.Alg = key.Method.Alg()
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func publicJWK(publicKey crypto.PublicKey) (JWK, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(k)}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// thumbprint menghitung JWK thumbprint (RFC 7638) yang dipakai sebagai kid kunci asimetris
+func (j JWK) thumbprint() string {
+	var members map[string]string
+	if j.Kty == "RSA" {
+		members = map[string]string{"e": j.E, "kty": j.Kty, "n": j.N}
+	} else {
+		members = map[string]string{"crv": j.Crv, "kty": j.Kty, "x": j.X}
+	}
+	// encoding/json mengurutkan key map secara leksikografis, sesuai aturan RFC 7638
+	canonical, _ := json.Marshal(members)
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestSecretBox(t *testing.T) {
+	box, err := NewSecretBox("kunci-rahasia-untuk-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := box.Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Open() = %q, want the original plaintext", opened)
+	}
+
+	// Nonce acak: plaintext yang sama menghasilkan ciphertext berbeda
+	again, _ := box.Seal("JBSWY3DPEHPK3PXP")
+	if again == sealed {
+		t.Error("sealing the same plaintext twice produced identical ciphertext")
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, _ := NewSecretBox("kunci-lain")
+		if _, err := other.Open(sealed); err == nil {
+			t.Error("Open with another key succeeded")
+		}
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		raw, _ := base64.StdEncoding.DecodeString(sealed)
+		raw[len(raw)-1] ^= 0x01
+		if _, err := box.Open(base64.StdEncoding.EncodeToString(raw)); err == nil {
+			t.Error("Open of tampered ciphertext succeeded")
+		}
+	})
+
+	t.Run("malformed input", func(t *testing.T) {
+		for _, input := range []string{"", "bukan base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+			if _, err := box.Open(input); err == nil {
+				t.Errorf("Open(%q) succeeded", input)
+			}
+		}
+	})
+}
+
+func TestNewSecretBoxRejectsEmptyKey(t *testing.T) {
+	if _, err := NewSecretBox(""); err == nil {
+		t.Error("NewSecretBox(\"\") succeeded")
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// Secret dan kode dari test vector RFC 6238 (SHA1), dipotong ke 6 digit
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestValidateTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"RFC vector at T=59", rfcSecret, "287082", 59, 1, true},
+		{"RFC vector at T=1111111109", rfcSecret, "081804", 1111111109, 37037036, true},
+		{"RFC vector at T=1234567890", rfcSecret, "005924", 1234567890, 41152263, true},
+		{"previous period is accepted", rfcSecret, "287082", 59 + 30, 1, true},
+		{"next period is accepted", rfcSecret, "081804", 1111111109 - 30, 37037036, true},
+		{"two periods late is rejected", rfcSecret, "287082", 59 + 60, 0, false},
+		{"surrounding whitespace is ignored", rfcSecret, " 287082 ", 59, 1, true},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", 59, 1, true},
+		{"wrong code", rfcSecret, "287083", 59, 0, false},
+		{"too short", rfcSecret, "28708", 59, 0, false},
+		{"too long", rfcSecret, "2870820", 59, 0, false},
+		{"invalid secret", "not base32!", "287082", 59, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.now, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q does not decode to 20 bytes: %v", secret, err)
+	}
+	if _, ok := ValidateTOTP(secret, totpCode(key, now.Unix()/totpPeriod), now); !ok {
+		t.Error("current code for a generated secret is rejected")
+	}
+}
@@ -0,0 +1,98 @@
+// Package authz berisi daftar permission, pemetaan role ke permission, dan policy
+// untuk pengecekan kepemilikan resource. Semua keputusan "siapa boleh melakukan apa"
+// dikumpulkan di sini agar tidak tersebar di handler dan service.
+package authz
+
+// Permission ditulis dengan format resource:aksi[:cakupan]. Cakupan "own" berarti hanya
+// resource milik subjek sendiri, "any" berarti semua resource.
+type Permission string
+
+const (
+	VehicleCreate    Permission = "vehicle:create"
+	VehicleReadOwn   Permission = "vehicle:read:own"
+	VehicleReadAny   Permission = "vehicle:read:any"
+	VehicleWriteOwn  Permission = "vehicle:write:own"
+	VehicleWriteAny  Permission = "vehicle:write:any"
+	BookingCreate    Permission = "booking:create"
+	BookingReadOwn   Permission = "booking:read:own"
+	BookingReadAny   Permission = "booking:read:any"
+	BookingUpdateOwn Permission = "booking:update:own"
+	ReviewCreate     Permission = "review:create"
+	PurchaseCreate   Permission = "purchase:create"
+	PurchaseReadOwn  Permission = "purchase:read:own"
+	SaleReadOwn      Permission = "sale:read:own"
+	ChatStart        Permission = "chat:start"
+	ChatReadOwn      Permission = "chat:read:own"
+	UserReadAny      Permission = "user:read:any"
+	UserDeleteAny    Permission = "user:delete:any"
+	VendorVerify     Permission = "vendor:verify"
+	LoginLockManage  Permission = "login_lock:manage"
+	TwoFactorManage  Permission = "two_factor:manage"
+	SettingsManage   Permission = "settings:manage"
+	APIKeyManage     Permission = "api_key:manage"
+	SessionRevokeAny Permission = "session:revoke:any"
+)
+
+// rolePermissions adalah satu-satunya tempat pemetaan role ke permission
+var rolePermissions = map[string][]Permission{
+	"customer": {
+		BookingCreate, BookingReadOwn,
+		ReviewCreate,
+		PurchaseCreate, PurchaseReadOwn,
+		ChatStart, ChatReadOwn,
+	},
+	"vendor": {
+		VehicleCreate, VehicleReadOwn, VehicleWriteOwn,
+		BookingReadOwn, BookingUpdateOwn,
+		SaleReadOwn,
+		ChatStart, ChatReadOwn,
+		TwoFactorManage,
+		APIKeyManage,
+	},
+	"admin": {
+		VehicleReadAny, VehicleWriteAny,
+		BookingReadAny,
+		ChatReadOwn,
+		UserReadAny, UserDeleteAny,
+		SessionRevokeAny,
+		VendorVerify,
+		LoginLockManage,
+		TwoFactorManage,
+		SettingsManage,
+	},
+}
+
+// apiKeyScopes adalah permission yang boleh didelegasikan ke API key. Permission untuk mengelola
+// akun (2FA, API key) sengaja tidak termasuk agar key yang bocor tidak bisa membuat key baru.
+var apiKeyScopes = []Permission{
+	VehicleCreate, VehicleReadOwn, VehicleWriteOwn,
+	BookingReadOwn, BookingUpdateOwn,
+	SaleReadOwn,
+}
+
+// RoleHas mengecek apakah sebuah role memiliki permission tertentu
+func RoleHas(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionsFor mengembalikan semua permission milik sebuah role
+func PermissionsFor(role string) []Permission {
+	perms := make([]Permission, len(rolePermissions[role]))
+	copy(perms, rolePermissions[role])
+	return perms
+}
+
+// IsAPIKeyScope mengecek apakah permission boleh dipakai sebagai scope API key
+func IsAPIKeyScope(perm Permission) bool {
+	for _, p := range apiKeyScopes {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package authz
+
+import "testing"
+
+var allPermissions = []Permission{
+	VehicleCreate, VehicleReadOwn, VehicleReadAny, VehicleWriteOwn, VehicleWriteAny,
+	BookingCreate, BookingReadOwn, BookingReadAny, BookingUpdateOwn,
+	ReviewCreate, PurchaseCreate, PurchaseReadOwn, SaleReadOwn,
+	ChatStart, ChatReadOwn,
+	UserReadAny, UserDeleteAny, VendorVerify,
+	LoginLockManage, TwoFactorManage, SettingsManage, APIKeyManage, SessionRevokeAny,
+}
+
+// expectedGrants ditulis ulang secara eksplisit agar perubahan pada rolePermissions harus disengaja
+var expectedGrants = map[string][]Permission{
+	"customer": {BookingCreate, BookingReadOwn, ReviewCreate, PurchaseCreate, PurchaseReadOwn, ChatStart, ChatReadOwn},
+	"vendor": {
+		VehicleCreate, VehicleReadOwn, VehicleWriteOwn, BookingReadOwn, BookingUpdateOwn,
+		SaleReadOwn, ChatStart, ChatReadOwn, TwoFactorManage, APIKeyManage,
+	},
+	"admin": {
+		VehicleReadAny, VehicleWriteAny, BookingReadAny, ChatReadOwn, UserReadAny, UserDeleteAny,
+		SessionRevokeAny, VendorVerify, LoginLockManage, TwoFactorManage, SettingsManage,
+	},
+	"":      {},
+	"guest": {},
+}
+
+func contains(perms []Permission, perm Permission) bool {
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRoleHas(t *testing.T) {
+	for role, granted := range expectedGrants {
+		for _, perm := range allPermissions {
+			want := contains(granted, perm)
+			if got := RoleHas(role, perm); got != want {
+				t.Errorf("RoleHas(%q, %s) = %v, want %v", role, perm, got, want)
+			}
+		}
+	}
+}
+
+func TestPermissionsForReturnsCopy(t *testing.T) {
+	perms := PermissionsFor("vendor")
+	perms[0] = SettingsManage
+	if RoleHas("vendor", SettingsManage) {
+		t.Error("modifying the returned slice changed the vendor role")
+	}
+}
+
+func TestIsAPIKeyScope(t *testing.T) {
+	scopes := []Permission{VehicleCreate, VehicleReadOwn, VehicleWriteOwn, BookingReadOwn, BookingUpdateOwn, SaleReadOwn}
+	for _, perm := range allPermissions {
+		if got, want := IsAPIKeyScope(perm), contains(scopes, perm); got != want {
+			t.Errorf("IsAPIKeyScope(%s) = %v, want %v", perm, got, want)
+		}
+	}
+}
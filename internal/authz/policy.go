@@ -0,0 +1,37 @@
+package authz
+
+import "sultra-otomotif-api/internal/model"
+
+// CanWriteVehicle: vendor hanya boleh mengubah kendaraan miliknya, admin semua kendaraan
+func CanWriteVehicle(s Subject, vehicle model.Vehicle) bool {
+	return s.canScoped(VehicleWriteOwn, VehicleWriteAny, vehicle.OwnerID == s.UserID)
+}
+
+// CanReadBooking: penyewa dan pemilik kendaraan boleh melihat booking-nya, admin semua booking
+func CanReadBooking(s Subject, booking model.Booking, vehicle model.Vehicle) bool {
+	isParty := booking.UserID == s.UserID || vehicle.OwnerID == s.UserID
+	return s.canScoped(BookingReadOwn, BookingReadAny, isParty)
+}
+
+// CanUpdateBookingStatus: hanya pemilik kendaraan yang boleh mengubah status booking
+func CanUpdateBookingStatus(s Subject, vehicle model.Vehicle) bool {
+	return vehicle.OwnerID == s.UserID && s.Can(BookingUpdateOwn)
+}
+
+// CanReadConversation: hanya customer & vendor di percakapan tersebut yang boleh membaca atau mengirim pesan
+func CanReadConversation(s Subject, convo model.Conversation) bool {
+	isParticipant := convo.CustomerID == s.UserID || convo.VendorID == s.UserID
+	return isParticipant && s.Can(ChatReadOwn)
+}
+
+// CanStartConversation: customer boleh memulai percakapan tentang kendaraan orang lain,
+// sedangkan vendor hanya tentang kendaraan miliknya sendiri (dengan customer yang dipilih).
+func CanStartConversation(s Subject, vehicle model.Vehicle) bool {
+	if !s.Can(ChatStart) {
+		return false
+	}
+	if s.Role == "vendor" {
+		return vehicle.OwnerID == s.UserID
+	}
+	return vehicle.OwnerID != s.UserID
+}
@@ -0,0 +1,105 @@
+package authz
+
+import (
+	"testing"
+
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestCanWriteVehicle(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		scopes  []Permission
+		isOwner bool
+		want    bool
+	}{
+		{"vendor owner", "vendor", nil, true, true},
+		{"vendor not owner", "vendor", nil, false, false},
+		{"admin", "admin", nil, false, true},
+		{"customer owner", "customer", nil, true, false},
+		{"unknown role owner", "guest", nil, true, false},
+		{"vendor key with write scope", "vendor", []Permission{VehicleWriteOwn}, true, true},
+		{"vendor key with read scope", "vendor", []Permission{VehicleReadOwn}, true, false},
+		{"vendor key with write scope not owner", "vendor", []Permission{VehicleWriteOwn}, false, false},
+		{"admin with any scope missing", "admin", []Permission{VehicleReadAny}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Subject{UserID: uuid.New(), Role: tt.role, Scopes: tt.scopes}
+			vehicle := model.Vehicle{ID: uuid.New(), OwnerID: uuid.New()}
+			if tt.isOwner {
+				vehicle.OwnerID = s.UserID
+			}
+			if got := CanWriteVehicle(s, vehicle); got != tt.want {
+				t.Errorf("CanWriteVehicle = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanReadBooking(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		scopes []Permission
+		party  string // "renter", "owner" atau "" jika bukan pihak booking
+		want   bool
+	}{
+		{"customer renter", "customer", nil, "renter", true},
+		{"customer stranger", "customer", nil, "", false},
+		{"vendor owner", "vendor", nil, "owner", true},
+		{"vendor stranger", "vendor", nil, "", false},
+		{"admin stranger", "admin", nil, "", true},
+		{"unknown role renter", "guest", nil, "renter", false},
+		{"vendor key with read scope", "vendor", []Permission{BookingReadOwn}, "owner", true},
+		{"vendor key without read scope", "vendor", []Permission{BookingUpdateOwn}, "owner", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Subject{UserID: uuid.New(), Role: tt.role, Scopes: tt.scopes}
+			booking := model.Booking{ID: uuid.New(), UserID: uuid.New()}
+			vehicle := model.Vehicle{ID: uuid.New(), OwnerID: uuid.New()}
+			switch tt.party {
+			case "renter":
+				booking.UserID = s.UserID
+			case "owner":
+				vehicle.OwnerID = s.UserID
+			}
+			if got := CanReadBooking(s, booking, vehicle); got != tt.want {
+				t.Errorf("CanReadBooking = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanUpdateBookingStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		scopes  []Permission
+		isOwner bool
+		want    bool
+	}{
+		{"vendor owner", "vendor", nil, true, true},
+		{"vendor not owner", "vendor", nil, false, false},
+		{"admin", "admin", nil, false, false},
+		{"customer owner", "customer", nil, true, false},
+		{"vendor key with update scope", "vendor", []Permission{BookingUpdateOwn}, true, true},
+		{"vendor key with read scope", "vendor", []Permission{BookingReadOwn}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Subject{UserID: uuid.New(), Role: tt.role, Scopes: tt.scopes}
+			vehicle := model.Vehicle{ID: uuid.New(), OwnerID: uuid.New()}
+			if tt.isOwner {
+				vehicle.OwnerID = s.UserID
+			}
+			if got := CanUpdateBookingStatus(s, vehicle); got != tt.want {
+				t.Errorf("CanUpdateBookingStatus = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
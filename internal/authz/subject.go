@@ -0,0 +1,43 @@
+package authz
+
+import "github.com/google/uuid"
+
+// Subject adalah pihak yang sedang melakukan request (user yang login atau API key miliknya)
+type Subject struct {
+	UserID uuid.UUID
+	Role   string
+	// Scopes membatasi permission jika request memakai API key; nil berarti semua permission role
+	Scopes []Permission
+}
+
+// Can mengecek apakah subjek memiliki permission tertentu
+func (s Subject) Can(perm Permission) bool {
+	if !RoleHas(s.Role, perm) {
+		return false
+	}
+	if s.Scopes == nil {
+		return true
+	}
+	for _, p := range s.Scopes {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAny mengecek apakah subjek memiliki salah satu dari permission yang diberikan
+func (s Subject) CanAny(perms ...Permission) bool {
+	for _, p := range perms {
+		if s.Can(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// canScoped adalah pola umum untuk permission ber-cakupan: boleh jika punya permission "any",
+// atau punya permission "own" dan resource tersebut memang miliknya.
+func (s Subject) canScoped(own, any Permission, isOwner bool) bool {
+	return s.Can(any) || (isOwner && s.Can(own))
+}
@@ -0,0 +1,53 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSubjectCanWithoutScopes(t *testing.T) {
+	for role, granted := range expectedGrants {
+		s := Subject{UserID: uuid.New(), Role: role}
+		for _, perm := range allPermissions {
+			if got, want := s.Can(perm), contains(granted, perm); got != want {
+				t.Errorf("%s Can(%s) = %v, want %v", role, perm, got, want)
+			}
+		}
+	}
+}
+
+func TestSubjectCanIntersectsAPIKeyScopes(t *testing.T) {
+	scopes := []Permission{VehicleReadOwn, BookingUpdateOwn, SettingsManage}
+	for role, granted := range expectedGrants {
+		s := Subject{UserID: uuid.New(), Role: role, Scopes: scopes}
+		for _, perm := range allPermissions {
+			want := contains(granted, perm) && contains(scopes, perm)
+			if got := s.Can(perm); got != want {
+				t.Errorf("%s with scopes %v Can(%s) = %v, want %v", role, scopes, perm, got, want)
+			}
+		}
+	}
+}
+
+func TestSubjectCanWithEmptyScopes(t *testing.T) {
+	s := Subject{UserID: uuid.New(), Role: "vendor", Scopes: []Permission{}}
+	for _, perm := range allPermissions {
+		if s.Can(perm) {
+			t.Errorf("API key without scopes Can(%s) = true", perm)
+		}
+	}
+}
+
+func TestSubjectCanAny(t *testing.T) {
+	vendor := Subject{UserID: uuid.New(), Role: "vendor", Scopes: []Permission{VehicleReadOwn}}
+	if !vendor.CanAny(VehicleReadAny, VehicleReadOwn) {
+		t.Error("CanAny should allow when one permission is granted")
+	}
+	if vendor.CanAny(VehicleReadAny, VehicleWriteOwn) {
+		t.Error("CanAny should deny a permission outside the API key scopes")
+	}
+	if vendor.CanAny() {
+		t.Error("CanAny without permissions should deny")
+	}
+}
@@ -4,16 +4,75 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBSource      string
-	JWTSecretKey  string
-	AppPort       string
+	DBSource     string
+	JWTSecretKey string
+	// Secret HMAC lama yang masih diterima untuk verifikasi selama masa rotasi
+	JWTPreviousSecretKeys []string
+	// File PEM kunci privat RSA/Ed25519; jika diisi, token ditandatangani dengan RS256/EdDSA
+	JWTPrivateKeyFile string
+	// File PEM kunci publik lama yang masih diterima untuk verifikasi
+	JWTPublicKeyFiles []string
+	AppPort           string
+	// Penyimpanan gambar: Cloudinary jika CloudinaryURL diisi, selain itu disk lokal di MediaDir
+	// yang disajikan di rute /media dengan URL publik MediaBaseURL.
 	CloudinaryURL string
-	FrontendURL   string
+	MediaDir      string
+	MediaBaseURL  string
+	// Batas upload gambar kendaraan
+	ImageMaxSizeMB     int
+	ImageMaxMegapixels int
+	FrontendURL        string
+	AccessTokenTTL     time.Duration
+	RefreshTokenTTL    time.Duration
+	// SMTP untuk email transaksional. Jika SMTPHost kosong, email hanya ditulis ke log
+	// (dan ke MailOutboxDir jika diisi) untuk development.
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+	MailFrom      string
+	MailOutboxDir string
+	// Twilio untuk SMS OTP. Jika TwilioAccountSID kosong, SMS hanya ditulis ke log untuk development.
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	SMSFrom          string
+	// Masa berlaku OTP telepon, jumlah tebakan per kode, dan batas pengiriman SMS per user/nomor
+	PhoneOTPTTL         time.Duration
+	PhoneOTPMaxAttempts int
+	PhoneOTPCooldown    time.Duration
+	PhoneOTPMaxPerHour  int
+	// Proteksi brute-force login
+	LoginMaxAccountAttempts int
+	LoginMaxIPAttempts      int
+	LoginLockoutDuration    time.Duration
+	// Batas permintaan email reset password / kirim ulang verifikasi per email
+	EmailMaxRequests   int
+	EmailRequestWindow time.Duration
+	// Alamat/CIDR reverse proxy yang dipercaya untuk header X-Forwarded-For
+	TrustedProxies []string
+	// Kunci untuk mengenkripsi secret TOTP di database. Jika kosong, pendaftaran 2FA dinonaktifkan.
+	TOTPEncryptionKey string
+	// Nama penerbit yang tampil di aplikasi authenticator
+	TOTPIssuer string
+	// Penyedia login OpenID Connect dari OIDC_PROVIDERS
+	OIDCProviders []OIDCProviderConfig
+}
+
+// OIDCProviderConfig adalah konfigurasi satu penyedia OIDC, dibaca dari OIDC_<NAMA>_*
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
 }
 
 func LoadConfig() Config {
@@ -22,11 +81,118 @@ func LoadConfig() Config {
 		log.Println("Warning: .env file not found, using environment variables")
 	}
 
+	frontendURL := os.Getenv("FRONTEND_URL")
 	return Config{
-		DBSource:      os.Getenv("DB_SOURCE"),
-		JWTSecretKey:  os.Getenv("JWT_SECRET_KEY"),
-		AppPort:       os.Getenv("APP_PORT"),
-		CloudinaryURL: os.Getenv("CLOUDINARY_URL"),
-		FrontendURL:   os.Getenv("FRONTEND_URL"),
+		DBSource:                os.Getenv("DB_SOURCE"),
+		JWTSecretKey:            os.Getenv("JWT_SECRET_KEY"),
+		JWTPreviousSecretKeys:   getList("JWT_PREVIOUS_SECRET_KEYS"),
+		JWTPrivateKeyFile:       os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		JWTPublicKeyFiles:       getList("JWT_PUBLIC_KEY_FILES"),
+		AppPort:                 os.Getenv("APP_PORT"),
+		CloudinaryURL:           os.Getenv("CLOUDINARY_URL"),
+		MediaDir:                getString("MEDIA_DIR", "./media"),
+		MediaBaseURL:            getString("MEDIA_BASE_URL", "http://localhost:"+getString("APP_PORT", "8080")+"/media"),
+		ImageMaxSizeMB:          getInt("IMAGE_MAX_SIZE_MB", 15),
+		ImageMaxMegapixels:      getInt("IMAGE_MAX_MEGAPIXELS", 40),
+		FrontendURL:             frontendURL,
+		AccessTokenTTL:          getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:         getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		SMTPHost:                os.Getenv("SMTP_HOST"),
+		SMTPPort:                os.Getenv("SMTP_PORT"),
+		SMTPUsername:            os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:            os.Getenv("SMTP_PASSWORD"),
+		MailFrom:                os.Getenv("MAIL_FROM"),
+		MailOutboxDir:           os.Getenv("MAIL_OUTBOX_DIR"),
+		TwilioAccountSID:        os.Getenv("TWILIO_ACCOUNT_SID"),
+		TwilioAuthToken:         os.Getenv("TWILIO_AUTH_TOKEN"),
+		SMSFrom:                 os.Getenv("SMS_FROM"),
+		PhoneOTPTTL:             getDuration("PHONE_OTP_TTL", 5*time.Minute),
+		PhoneOTPMaxAttempts:     getInt("PHONE_OTP_MAX_ATTEMPTS", 5),
+		PhoneOTPCooldown:        getDuration("PHONE_OTP_COOLDOWN", time.Minute),
+		PhoneOTPMaxPerHour:      getInt("PHONE_OTP_MAX_PER_HOUR", 5),
+		LoginMaxAccountAttempts: getInt("LOGIN_MAX_ACCOUNT_ATTEMPTS", 5),
+		LoginMaxIPAttempts:      getInt("LOGIN_MAX_IP_ATTEMPTS", 20),
+		LoginLockoutDuration:    getDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		EmailMaxRequests:        getInt("EMAIL_MAX_REQUESTS", 3),
+		EmailRequestWindow:      getDuration("EMAIL_REQUEST_WINDOW", time.Hour),
+		TrustedProxies:          getList("TRUSTED_PROXIES"),
+		TOTPEncryptionKey:       os.Getenv("TOTP_ENCRYPTION_KEY"),
+		TOTPIssuer:              getString("TOTP_ISSUER", "Sultra Otomotif"),
+		OIDCProviders:           getOIDCProviders(frontendURL),
+	}
+}
+
+// wellKnownIssuers adalah issuer bawaan untuk penyedia umum sehingga OIDC_<NAMA>_ISSUER boleh dikosongkan
+var wellKnownIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+}
+
+// getOIDCProviders membaca daftar penyedia dari OIDC_PROVIDERS (misal "google,mock") lalu
+// konfigurasi tiap penyedia dari OIDC_<NAMA>_ISSUER, _CLIENT_ID, _CLIENT_SECRET dan _REDIRECT_URL.
+// Penyedia yang konfigurasinya tidak lengkap dilewati.
+func getOIDCProviders(frontendURL string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range getList("OIDC_PROVIDERS") {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		p := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getString(prefix+"ISSUER", wellKnownIssuers[name]),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  getString(prefix+"REDIRECT_URL", strings.TrimRight(frontendURL, "/")+"/auth/callback/"+name),
+		}
+		if p.Issuer == "" || p.ClientID == "" {
+			log.Printf("Warning: OIDC provider %q needs %sISSUER and %sCLIENT_ID, skipping", name, prefix, prefix)
+			continue
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+func getString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getDuration membaca environment variable berformat durasi Go (misal "15m", "720h")
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s %q, using default %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// getInt membaca environment variable berupa bilangan bulat positif
+func getInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: invalid %s %q, using default %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getList membaca environment variable berisi daftar yang dipisah koma
+func getList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
@@ -0,0 +1,14 @@
+CREATE TABLE users (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    full_name     VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL UNIQUE,
+    password_hash TEXT         NOT NULL,
+    phone_number  VARCHAR(32)  NOT NULL,
+    role          VARCHAR(20)  NOT NULL CHECK (role IN ('customer', 'vendor', 'admin')),
+    is_verified   BOOLEAN      NOT NULL DEFAULT FALSE,
+    verified_at   TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_users_role ON users (role);
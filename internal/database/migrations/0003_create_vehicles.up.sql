@@ -0,0 +1,27 @@
+CREATE TABLE vehicles (
+    id                   UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id             UUID          NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    brand                VARCHAR(100)  NOT NULL,
+    model                VARCHAR(100)  NOT NULL,
+    year                 INTEGER       NOT NULL,
+    plate_number         VARCHAR(20)   NOT NULL UNIQUE,
+    color                VARCHAR(50),
+    vehicle_type         VARCHAR(10)   NOT NULL CHECK (vehicle_type IN ('mobil', 'motor')),
+    transmission         VARCHAR(10)   NOT NULL CHECK (transmission IN ('matic', 'manual')),
+    fuel                 VARCHAR(10)   NOT NULL CHECK (fuel IN ('bensin', 'diesel', 'listrik')),
+    status               VARCHAR(20)   NOT NULL DEFAULT 'available',
+    description          TEXT,
+    is_for_sale          BOOLEAN       NOT NULL DEFAULT FALSE,
+    sale_price           NUMERIC(15, 2),
+    is_for_rent          BOOLEAN       NOT NULL DEFAULT FALSE,
+    rental_price_daily   NUMERIC(15, 2),
+    rental_price_weekly  NUMERIC(15, 2),
+    rental_price_monthly NUMERIC(15, 2),
+    location             VARCHAR(255),
+    features             TEXT[],
+    created_at           TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vehicles_owner_id ON vehicles (owner_id);
+CREATE INDEX idx_vehicles_status_created_at ON vehicles (status, created_at DESC);
@@ -0,0 +1,9 @@
+CREATE TABLE vehicle_images (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vehicle_id UUID        NOT NULL REFERENCES vehicles (id) ON DELETE CASCADE,
+    image_url  TEXT        NOT NULL,
+    is_primary BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vehicle_images_vehicle_id ON vehicle_images (vehicle_id);
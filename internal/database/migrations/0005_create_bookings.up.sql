@@ -0,0 +1,18 @@
+CREATE TABLE bookings (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id       UUID           NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    vehicle_id    UUID           NOT NULL REFERENCES vehicles (id) ON DELETE CASCADE,
+    start_date    DATE           NOT NULL,
+    end_date      DATE           NOT NULL,
+    total_price   NUMERIC(15, 2) NOT NULL,
+    status        VARCHAR(20)    NOT NULL DEFAULT 'pending_payment'
+        CHECK (status IN ('pending_payment', 'confirmed', 'rented_out', 'completed', 'cancelled')),
+    payment_token TEXT,
+    payment_url   TEXT,
+    created_at    TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_bookings_user_id ON bookings (user_id, created_at DESC);
+CREATE INDEX idx_bookings_vehicle_id_status ON bookings (vehicle_id, status);
@@ -0,0 +1,11 @@
+CREATE TABLE reviews (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID        NOT NULL UNIQUE REFERENCES bookings (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    vehicle_id UUID        NOT NULL REFERENCES vehicles (id) ON DELETE CASCADE,
+    rating     SMALLINT    NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment    TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reviews_vehicle_id ON reviews (vehicle_id, created_at DESC);
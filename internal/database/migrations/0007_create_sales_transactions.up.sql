@@ -0,0 +1,16 @@
+CREATE TABLE sales_transactions (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vehicle_id    UUID           NOT NULL REFERENCES vehicles (id) ON DELETE CASCADE,
+    seller_id     UUID           NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    buyer_id      UUID           NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    agreed_price  NUMERIC(15, 2) NOT NULL,
+    status        VARCHAR(20)    NOT NULL DEFAULT 'payment_pending'
+        CHECK (status IN ('payment_pending', 'completed', 'cancelled')),
+    payment_token TEXT,
+    payment_url   TEXT,
+    created_at    TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sales_transactions_buyer_id ON sales_transactions (buyer_id, created_at DESC);
+CREATE INDEX idx_sales_transactions_seller_id ON sales_transactions (seller_id, created_at DESC);
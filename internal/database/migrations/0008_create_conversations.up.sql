@@ -0,0 +1,23 @@
+CREATE TABLE conversations (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    vendor_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    vehicle_id  UUID        NOT NULL REFERENCES vehicles (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (customer_id, vendor_id, vehicle_id)
+);
+
+CREATE INDEX idx_conversations_vendor_id ON conversations (vendor_id, updated_at DESC);
+
+CREATE TABLE messages (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID        NOT NULL REFERENCES conversations (id) ON DELETE CASCADE,
+    sender_id       UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    content         TEXT        NOT NULL,
+    is_read         BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_messages_conversation_id ON messages (conversation_id, created_at);
@@ -0,0 +1,25 @@
+-- Setiap login membuat satu sesi; access token membawa ID sesi (claim "sid")
+-- sehingga sesi bisa dicabut dari server kapan saja.
+CREATE TABLE sessions (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id        UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    revoked_at     TIMESTAMPTZ,
+    revoked_reason VARCHAR(50),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+-- Refresh token dirotasi setiap kali dipakai. Token yang sudah dirotasi (rotated_at terisi)
+-- lalu dipakai lagi dianggap bocor, dan seluruh sesinya dicabut.
+CREATE TABLE refresh_tokens (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID        NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    token_hash TEXT        NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    rotated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens (session_id);
@@ -0,0 +1,18 @@
+-- Waktu user mengonfirmasi kepemilikan email (terpisah dari is_verified milik vendor)
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+-- Token sekali pakai untuk reset password & verifikasi email. Hanya hash token yang disimpan.
+-- Kolom email mencatat alamat saat token diterbitkan, sehingga token verifikasi
+-- tidak berlaku lagi jika user mengganti email setelahnya.
+CREATE TABLE user_tokens (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id     UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose     VARCHAR(30)  NOT NULL CHECK (purpose IN ('password_reset', 'email_verification')),
+    token_hash  TEXT         NOT NULL UNIQUE,
+    email       VARCHAR(255) NOT NULL,
+    expires_at  TIMESTAMPTZ  NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens (user_id, purpose);
@@ -0,0 +1,12 @@
+-- Penghitung login gagal per akun (email) dan per alamat IP. Disimpan di Postgres
+-- agar batasnya berlaku sama di semua instance API.
+CREATE TABLE login_throttles (
+    scope           VARCHAR(10)  NOT NULL CHECK (scope IN ('account', 'ip')),
+    key             VARCHAR(255) NOT NULL,
+    failed_attempts INTEGER      NOT NULL DEFAULT 0,
+    last_failed_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    blocked_until   TIMESTAMPTZ,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_login_throttles_blocked_until ON login_throttles (blocked_until);
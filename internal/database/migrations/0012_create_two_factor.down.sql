@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS platform_settings;
+DROP TABLE IF EXISTS two_factor_backup_codes;
+DROP TABLE IF EXISTS user_two_factor;
@@ -0,0 +1,30 @@
+-- Secret TOTP disimpan terenkripsi (AES-GCM). enabled_at NULL berarti pendaftaran belum dikonfirmasi.
+-- last_used_step mencegah kode yang sama dipakai dua kali.
+CREATE TABLE user_two_factor (
+    user_id          UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret_encrypted TEXT        NOT NULL,
+    enabled_at       TIMESTAMPTZ,
+    last_used_step   BIGINT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Kode cadangan sekali pakai jika perangkat authenticator hilang. Hanya hash yang disimpan.
+CREATE TABLE two_factor_backup_codes (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  TEXT        NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
+
+-- Pengaturan platform yang bisa diubah admin saat runtime
+CREATE TABLE platform_settings (
+    key        VARCHAR(100) PRIMARY KEY,
+    value      TEXT         NOT NULL,
+    updated_by UUID         REFERENCES users (id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO platform_settings (key, value) VALUES ('require_2fa_for_admin', 'false');
@@ -0,0 +1,16 @@
+-- API key untuk integrasi antar sistem (misal software armada milik vendor).
+-- Hanya hash key yang disimpan; prefix disimpan agar user bisa mengenali key-nya.
+CREATE TABLE api_keys (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(20)  NOT NULL,
+    key_hash     TEXT         NOT NULL UNIQUE,
+    scopes       TEXT[]       NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    expires_at   TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
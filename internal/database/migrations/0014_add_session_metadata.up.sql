@@ -0,0 +1,6 @@
+-- Informasi perangkat untuk daftar sesi aktif (GET /auth/sessions).
+-- last_seen_at diperbarui saat access token dipakai (paling sering sekali per menit) dan saat refresh.
+ALTER TABLE sessions
+    ADD COLUMN user_agent   TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN ip_address   VARCHAR(45) NOT NULL DEFAULT '',
+    ADD COLUMN last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
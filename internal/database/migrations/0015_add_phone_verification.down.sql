@@ -0,0 +1,6 @@
+-- Normalisasi nomor telepon ke E.164 tidak dibatalkan
+DROP TABLE IF EXISTS phone_otps;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS require_verified_phone,
+    DROP COLUMN IF EXISTS phone_verified_at;
@@ -0,0 +1,30 @@
+-- Nomor telepon disimpan dalam format E.164. Nomor lama yang jelas bernomor Indonesia dinormalkan;
+-- sisanya dibiarkan apa adanya dan akan dinormalkan saat user mengubah profilnya.
+UPDATE users SET phone_number = CASE
+        WHEN d ~ '^0[1-9][0-9]{7,11}$' THEN '+62' || substr(d, 2)
+        WHEN d ~ '^62[1-9][0-9]{7,11}$' THEN '+' || d
+        WHEN d ~ '^\+62[1-9][0-9]{7,11}$' THEN d
+        ELSE phone_number
+    END
+FROM (SELECT id AS uid, regexp_replace(phone_number, '[\s().-]', '', 'g') AS d FROM users) AS cleaned
+WHERE users.id = cleaned.uid;
+
+ALTER TABLE users
+    ADD COLUMN phone_verified_at      TIMESTAMPTZ,
+    -- Pengaturan vendor: customer wajib punya nomor terverifikasi sebelum booking kendaraannya
+    ADD COLUMN require_verified_phone BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Kode OTP verifikasi nomor telepon. Hanya hash kode yang disimpan; attempts membatasi tebakan.
+CREATE TABLE phone_otps (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    code_hash    TEXT        NOT NULL,
+    attempts     INT         NOT NULL DEFAULT 0,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    consumed_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_phone_otps_user_created ON phone_otps (user_id, created_at DESC);
+CREATE INDEX idx_phone_otps_phone_created ON phone_otps (phone_number, created_at DESC);
@@ -0,0 +1,24 @@
+-- Akun penyedia login eksternal (OpenID Connect, misal Google) yang terhubung ke user.
+-- subject adalah ID user di penyedia tersebut dan tidak pernah berubah, berbeda dengan email.
+CREATE TABLE user_identities (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id       UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider      VARCHAR(50)  NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL,
+    last_login_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+-- State login OIDC yang sedang berjalan. code_verifier (PKCE) dan nonce hanya disimpan di server,
+-- sedangkan state dikirim ke penyedia dan kembali lewat callback.
+CREATE TABLE oidc_login_states (
+    state_hash    TEXT PRIMARY KEY,
+    provider      VARCHAR(50) NOT NULL,
+    nonce         TEXT        NOT NULL,
+    code_verifier TEXT        NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
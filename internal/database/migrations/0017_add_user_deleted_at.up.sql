@@ -0,0 +1,3 @@
+-- Akun yang dihapus sendiri oleh user (atau admin) dianonimkan, bukan dihapus, agar booking dan
+-- transaksi penjualan yang dibutuhkan untuk pembukuan tetap utuh. deleted_at menandai akun tersebut.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_vehicles_model_trgm;
+DROP INDEX IF EXISTS idx_vehicles_brand_trgm;
+DROP INDEX IF EXISTS idx_vehicles_search_vector;
+DROP TRIGGER IF EXISTS trg_vehicles_search_vector ON vehicles;
+DROP FUNCTION IF EXISTS vehicles_search_vector();
+ALTER TABLE vehicles DROP COLUMN IF EXISTS search_vector;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS vehicle_search;
@@ -0,0 +1,34 @@
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Konfigurasi full-text search: stemmer bahasa Indonesia, dengan aksen dihapus lebih dulu
+-- sehingga "citroën" cocok dengan "citroen".
+CREATE TEXT SEARCH CONFIGURATION vehicle_search (COPY = indonesian);
+ALTER TEXT SEARCH CONFIGURATION vehicle_search
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, indonesian_stem;
+
+ALTER TABLE vehicles ADD COLUMN search_vector tsvector;
+
+-- Bobot: merek & model (A) > fitur & lokasi (B) > deskripsi (C)
+CREATE FUNCTION vehicles_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('vehicle_search', COALESCE(NEW.brand, '') || ' ' || COALESCE(NEW.model, '')), 'A') ||
+        setweight(to_tsvector('vehicle_search', COALESCE(array_to_string(NEW.features, ' '), '') || ' ' || COALESCE(NEW.location, '')), 'B') ||
+        setweight(to_tsvector('vehicle_search', COALESCE(NEW.description, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_vehicles_search_vector
+    BEFORE INSERT OR UPDATE OF brand, model, features, location, description ON vehicles
+    FOR EACH ROW EXECUTE FUNCTION vehicles_search_vector();
+
+-- Isi search_vector untuk kendaraan yang sudah ada (memicu trigger di atas)
+UPDATE vehicles SET brand = brand;
+
+CREATE INDEX idx_vehicles_search_vector ON vehicles USING GIN (search_vector);
+
+-- Indeks trigram agar filter brand/model dengan ILIKE '%...%' dan saran "did you mean" bisa memakai indeks
+CREATE INDEX idx_vehicles_brand_trgm ON vehicles USING GIN (brand gin_trgm_ops);
+CREATE INDEX idx_vehicles_model_trgm ON vehicles USING GIN (model gin_trgm_ops);
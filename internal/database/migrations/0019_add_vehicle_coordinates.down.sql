@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_vehicles_coordinates;
+ALTER TABLE vehicles
+    DROP CONSTRAINT IF EXISTS vehicles_coordinates_pair,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,7 @@
+ALTER TABLE vehicles
+    ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT vehicles_coordinates_pair CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+-- Filter radius & bounding box menyaring kandidat lewat rentang lintang/bujur sebelum menghitung jarak
+CREATE INDEX idx_vehicles_coordinates ON vehicles (latitude, longitude) WHERE latitude IS NOT NULL;
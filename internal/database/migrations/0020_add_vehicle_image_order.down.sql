@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_vehicle_images_vehicle_id;
+CREATE INDEX idx_vehicle_images_vehicle_id ON vehicle_images (vehicle_id);
+DROP INDEX IF EXISTS uq_vehicle_images_primary;
+ALTER TABLE vehicle_images
+    DROP COLUMN IF EXISTS storage_key,
+    DROP COLUMN IF EXISTS sort_order;
@@ -0,0 +1,32 @@
+ALTER TABLE vehicle_images
+    ADD COLUMN sort_order  INT  NOT NULL DEFAULT 0,
+    ADD COLUMN storage_key TEXT;
+
+-- Gambar Cloudinary lama: public ID diambil dari URL (.../upload/v123/<public_id>.<ext>) agar bisa dihapus
+UPDATE vehicle_images
+SET storage_key = (regexp_match(image_url, '/upload/(v[0-9]+/)?(.+)\.[^./]+$'))[2]
+WHERE image_url LIKE 'https://res.cloudinary.com/%';
+
+-- Urutan awal mengikuti waktu upload
+UPDATE vehicle_images vi
+SET sort_order = ordered.position
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY vehicle_id ORDER BY created_at, id) - 1 AS position
+      FROM vehicle_images) AS ordered
+WHERE vi.id = ordered.id;
+
+-- Setiap kendaraan yang punya gambar memiliki tepat satu gambar utama (gambar pertama jika belum ada)
+UPDATE vehicle_images SET is_primary = FALSE
+WHERE is_primary AND id NOT IN (
+    SELECT DISTINCT ON (vehicle_id) id FROM vehicle_images WHERE is_primary ORDER BY vehicle_id, sort_order
+);
+UPDATE vehicle_images SET is_primary = TRUE
+WHERE id IN (
+    SELECT DISTINCT ON (vehicle_id) id FROM vehicle_images
+    WHERE vehicle_id NOT IN (SELECT vehicle_id FROM vehicle_images WHERE is_primary)
+    ORDER BY vehicle_id, sort_order
+);
+
+CREATE UNIQUE INDEX uq_vehicle_images_primary ON vehicle_images (vehicle_id) WHERE is_primary;
+
+DROP INDEX IF EXISTS idx_vehicle_images_vehicle_id;
+CREATE INDEX idx_vehicle_images_vehicle_id ON vehicle_images (vehicle_id, sort_order);
@@ -0,0 +1,5 @@
+ALTER TABLE vehicle_images
+    DROP COLUMN IF EXISTS card_key,
+    DROP COLUMN IF EXISTS card_url,
+    DROP COLUMN IF EXISTS thumbnail_key,
+    DROP COLUMN IF EXISTS thumbnail_url;
@@ -0,0 +1,7 @@
+-- Rendition kecil dari setiap gambar. image_url & storage_key tetap menunjuk ke rendition "full".
+-- Gambar lama tidak punya rendition; query memakai image_url sebagai gantinya.
+ALTER TABLE vehicle_images
+    ADD COLUMN thumbnail_url TEXT,
+    ADD COLUMN thumbnail_key TEXT,
+    ADD COLUMN card_url      TEXT,
+    ADD COLUMN card_key      TEXT;
@@ -0,0 +1,14 @@
+ALTER TABLE vehicles
+    DROP CONSTRAINT IF EXISTS vehicles_maintenance_window,
+    DROP CONSTRAINT IF EXISTS vehicles_status_check,
+    ALTER COLUMN status SET DEFAULT 'available';
+
+UPDATE vehicles
+SET status = CASE status WHEN 'published' THEN 'available' WHEN 'maintenance' THEN 'available' WHEN 'sold' THEN 'sold' ELSE 'unavailable' END;
+
+ALTER TABLE vehicles
+    DROP COLUMN IF EXISTS maintenance_end,
+    DROP COLUMN IF EXISTS maintenance_start,
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS status_changed_at;
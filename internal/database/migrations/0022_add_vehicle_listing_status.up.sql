@@ -0,0 +1,26 @@
+-- Status listing menjadi state machine: draft -> published <-> maintenance, archived, dan sold (final).
+-- Status lama dipetakan: available -> published, unavailable (listing vendor yang akunnya dihapus) -> archived.
+ALTER TABLE vehicles
+    ADD COLUMN status_changed_at TIMESTAMPTZ,
+    ADD COLUMN published_at TIMESTAMPTZ,
+    ADD COLUMN archived_at TIMESTAMPTZ,
+    ADD COLUMN maintenance_start DATE,
+    ADD COLUMN maintenance_end DATE;
+
+UPDATE vehicles
+SET status = CASE status WHEN 'available' THEN 'published' WHEN 'sold' THEN 'sold' ELSE 'archived' END,
+    status_changed_at = updated_at;
+UPDATE vehicles SET published_at = created_at WHERE status IN ('published', 'sold');
+UPDATE vehicles SET archived_at = updated_at WHERE status = 'archived';
+
+-- Jendela maintenance hanya ada (dan wajib ada) selama status maintenance
+ALTER TABLE vehicles
+    ALTER COLUMN status SET DEFAULT 'draft',
+    ALTER COLUMN status_changed_at SET DEFAULT NOW(),
+    ALTER COLUMN status_changed_at SET NOT NULL,
+    ADD CONSTRAINT vehicles_status_check CHECK (status IN ('draft', 'published', 'maintenance', 'archived', 'sold')),
+    ADD CONSTRAINT vehicles_maintenance_window CHECK (
+        (status = 'maintenance') = (maintenance_start IS NOT NULL)
+        AND (maintenance_start IS NULL) = (maintenance_end IS NULL)
+        AND maintenance_end >= maintenance_start
+    );
@@ -0,0 +1,10 @@
+-- Token tantangan 2FA (mfa_verify) yang sudah dipakai, dicatat per jti agar tidak bisa dipakai ulang.
+-- Baris boleh dihapus setelah expires_at karena token-nya sendiri sudah tidak berlaku.
+CREATE TABLE used_challenges (
+    jti        UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_used_challenges_expires_at ON used_challenges (expires_at);
@@ -0,0 +1,7 @@
+-- Email disimpan dalam huruf kecil; unique index pada LOWER(email) mencegah akun kembar yang
+-- hanya berbeda huruf besar/kecil. Migrasi gagal jika data lama sudah berisi akun kembar seperti itu.
+UPDATE users SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email));
+UPDATE user_tokens SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email));
+
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_lower_key ON users (LOWER(email));
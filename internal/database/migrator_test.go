@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestEmbeddedMigrationsArePairedAndOrdered(t *testing.T) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]bool{}
+	for _, entry := range entries {
+		files[entry.Name()] = true
+	}
+	for name := range files {
+		if base, ok := strings.CutSuffix(name, ".up.sql"); ok && !files[base+".down.sql"] {
+			t.Errorf("%s has no matching .down.sql", name)
+		}
+		if base, ok := strings.CutSuffix(name, ".down.sql"); ok && !files[base+".up.sql"] {
+			t.Errorf("%s has no matching .up.sql", name)
+		}
+	}
+
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations)*2 != len(files) {
+		t.Errorf("loaded %d migrations from %d files", len(migrations), len(files))
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration #%d has version %d, want %d (versions must be sequential)", i, m.Version, i+1)
+		}
+		if strings.TrimSpace(m.UpSQL) == "" || strings.TrimSpace(m.DownSQL) == "" {
+			t.Errorf("migration %d_%s has an empty up or down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	t.Run("sorted by numeric version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"migrations/10_add_index.up.sql":     file("CREATE INDEX"),
+			"migrations/10_add_index.down.sql":   file("DROP INDEX"),
+			"migrations/2_create_users.up.sql":   file("CREATE TABLE users"),
+			"migrations/2_create_users.down.sql": file("DROP TABLE users"),
+			"migrations/README.md":               file("ignored"),
+		}
+		migrations, err := loadMigrations(fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []Migration{
+			{Version: 2, Name: "create_users", UpSQL: "CREATE TABLE users", DownSQL: "DROP TABLE users"},
+			{Version: 10, Name: "add_index", UpSQL: "CREATE INDEX", DownSQL: "DROP INDEX"},
+		}
+		if fmt.Sprint(migrations) != fmt.Sprint(want) {
+			t.Errorf("migrations = %v, want %v", migrations, want)
+		}
+	})
+
+	invalid := map[string]fstest.MapFS{
+		"missing down": {
+			"migrations/1_create_users.up.sql": file("CREATE TABLE users"),
+		},
+		"missing up": {
+			"migrations/1_create_users.down.sql": file("DROP TABLE users"),
+		},
+		"no name": {
+			"migrations/1.up.sql":   file("SELECT 1"),
+			"migrations/1.down.sql": file("SELECT 1"),
+		},
+		"invalid version": {
+			"migrations/v1_create_users.up.sql":   file("CREATE TABLE users"),
+			"migrations/v1_create_users.down.sql": file("DROP TABLE users"),
+		},
+		"conflicting names": {
+			"migrations/1_create_users.up.sql":      file("CREATE TABLE users"),
+			"migrations/1_create_accounts.down.sql": file("DROP TABLE accounts"),
+		},
+	}
+	for name, fsys := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadMigrations(fsys); err == nil {
+				t.Error("loadMigrations succeeded, want an error")
+			}
+		})
+	}
+}
+
+// testMigrator membuat database kosong sementara di server TEST_DB_SOURCE, agar migrasi turun
+// sampai versi 0 tidak mengganggu test lain yang memakai database tersebut.
+func testMigrator(t *testing.T) (*Migrator, *pgxpool.Pool) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DB_SOURCE")
+	if dsn == "" {
+		t.Skip("TEST_DB_SOURCE is not set")
+	}
+	ctx := context.Background()
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close(context.Background()) })
+
+	dbName := "migrator_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+dbName); err != nil {
+		t.Skipf("cannot create a scratch database: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec(context.Background(), "DROP DATABASE IF EXISTS "+dbName+" WITH (FORCE)") })
+
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ConnConfig.Database = dbName
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator, db
+}
+
+func versions(migrations []Migration) []int64 {
+	result := make([]int64, len(migrations))
+	for i, m := range migrations {
+		result[i] = m.Version
+	}
+	return result
+}
+
+func appliedCount(t *testing.T, m *Migrator) int {
+	t.Helper()
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			count++
+		}
+	}
+	return count
+}
+
+func TestMigratorToBothDirections(t *testing.T) {
+	migrator, db := testMigrator(t)
+	ctx := context.Background()
+	latest := migrator.LatestVersion()
+
+	if err := migrator.EnsureUpToDate(ctx); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("EnsureUpToDate on an empty database = %v, want ErrSchemaOutdated", err)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != int(latest) {
+		t.Fatalf("Up applied %v, want all %d migrations", versions(applied), latest)
+	}
+	if err := migrator.EnsureUpToDate(ctx); err != nil {
+		t.Fatalf("EnsureUpToDate after Up: %v", err)
+	}
+	if again, err := migrator.Up(ctx); err != nil || len(again) != 0 {
+		t.Fatalf("second Up = %v, %v, want no changes", versions(again), err)
+	}
+
+	// Turun ke versi 5: migrasi di atasnya dibatalkan dari yang terbaru
+	reverted, err := migrator.To(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions(reverted); len(got) != int(latest)-5 || got[0] != latest || got[len(got)-1] != 6 {
+		t.Errorf("To(5) reverted %v, want %d..6", got, latest)
+	}
+	if n := appliedCount(t, migrator); n != 5 {
+		t.Errorf("%d migrations applied after To(5), want 5", n)
+	}
+	if err := migrator.EnsureUpToDate(ctx); !errors.Is(err, ErrSchemaOutdated) {
+		t.Errorf("EnsureUpToDate after To(5) = %v, want ErrSchemaOutdated", err)
+	}
+
+	// Naik lagi ke versi 8: hanya 6..8 yang diterapkan, berurutan
+	raised, err := migrator.To(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(versions(raised)); got != "[6 7 8]" {
+		t.Errorf("To(8) applied %s, want [6 7 8]", got)
+	}
+
+	// Versi 0 membatalkan semuanya sehingga tabel aplikasi ikut hilang
+	if _, err := migrator.To(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if n := appliedCount(t, migrator); n != 0 {
+		t.Errorf("%d migrations applied after To(0), want 0", n)
+	}
+	var usersTable *string
+	if err := db.QueryRow(ctx, `SELECT to_regclass('users')::text`).Scan(&usersTable); err != nil {
+		t.Fatal(err)
+	}
+	if usersTable != nil {
+		t.Error("users table still exists after To(0)")
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up after To(0): %v", err)
+	}
+	if err := migrator.EnsureUpToDate(ctx); err != nil {
+		t.Errorf("EnsureUpToDate after migrating up again: %v", err)
+	}
+}
+
+func TestMigratorRejectsInvalidTargets(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Target yang tidak valid ditolak sebelum menyentuh database
+	migrator := &Migrator{migrations: migrations}
+	ctx := context.Background()
+
+	if _, err := migrator.To(ctx, migrator.LatestVersion()+1); err == nil {
+		t.Error("To an unknown version succeeded")
+	}
+	if _, err := migrator.Down(ctx, 0); err == nil {
+		t.Error("Down(0) succeeded")
+	}
+}
@@ -0,0 +1,21 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound dikembalikan jika alamat tidak dikenali oleh geocoder
+var ErrNotFound = errors.New("geocode: address not found")
+
+// Point adalah koordinat dalam derajat desimal (WGS84)
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Geocoder mengubah alamat teks bebas menjadi koordinat. Provider sungguhan (misal Google Maps
+// atau Nominatim) cukup mengimplementasikan interface ini; StaticGeocoder dipakai untuk development.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (Point, error)
+}
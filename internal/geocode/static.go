@@ -0,0 +1,51 @@
+package geocode
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+type place struct {
+	name  string
+	point Point
+}
+
+// sultraPlaces adalah perkiraan titik pusat kota/kabupaten di Sulawesi Tenggara
+var sultraPlaces = []place{
+	{name: "kendari", point: Point{Latitude: -3.9985, Longitude: 122.5130}},
+	{name: "baubau", point: Point{Latitude: -5.4700, Longitude: 122.6000}},
+	{name: "bau bau", point: Point{Latitude: -5.4700, Longitude: 122.6000}},
+	{name: "kolaka utara", point: Point{Latitude: -3.5000, Longitude: 121.3500}},
+	{name: "kolaka", point: Point{Latitude: -4.0500, Longitude: 121.5900}},
+	{name: "konawe selatan", point: Point{Latitude: -4.3600, Longitude: 122.3800}},
+	{name: "konawe", point: Point{Latitude: -3.8600, Longitude: 122.0400}},
+	{name: "unaaha", point: Point{Latitude: -3.8600, Longitude: 122.0400}},
+	{name: "raha", point: Point{Latitude: -4.8400, Longitude: 122.7200}},
+	{name: "muna", point: Point{Latitude: -4.8400, Longitude: 122.7200}},
+	{name: "bombana", point: Point{Latitude: -4.6400, Longitude: 121.9000}},
+	{name: "wakatobi", point: Point{Latitude: -5.3200, Longitude: 123.5900}},
+	{name: "buton", point: Point{Latitude: -5.4900, Longitude: 122.8700}},
+}
+
+// StaticGeocoder mengenali nama kota/kabupaten di Sulawesi Tenggara dari daftar tetap, tanpa
+// memanggil layanan luar. Alamat cocok jika memuat nama tempat sebagai kata utuh, misal
+// "Jl. Ahmad Yani, Kendari"; nama yang lebih spesifik ("Kolaka Utara") didahulukan.
+type StaticGeocoder struct{}
+
+func NewStaticGeocoder() *StaticGeocoder {
+	return &StaticGeocoder{}
+}
+
+func (g *StaticGeocoder) Geocode(ctx context.Context, address string) (Point, error) {
+	words := strings.FieldsFunc(strings.ToLower(address), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	normalized := " " + strings.Join(words, " ") + " "
+	for _, p := range sultraPlaces {
+		if strings.Contains(normalized, " "+p.name+" ") {
+			return p.point, nil
+		}
+	}
+	return Point{}, ErrNotFound
+}
@@ -0,0 +1,52 @@
+package geocode
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStaticGeocoder(t *testing.T) {
+	g := NewStaticGeocoder()
+	tests := []struct {
+		address string
+		want    string // nama tempat yang diharapkan, kosong jika tidak ditemukan
+	}{
+		{"Jl. Ahmad Yani, Kendari", "kendari"},
+		{"KENDARI", "kendari"},
+		{"Kota Bau-Bau, Sulawesi Tenggara", "bau bau"},
+		{"Lasusua, Kolaka Utara", "kolaka utara"},
+		{"Jl. Pemuda, Kolaka", "kolaka"},
+		{"Andoolo, Konawe Selatan", "konawe selatan"},
+		{"Kendarikota", ""},
+		{"Makassar, Sulawesi Selatan", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			point, err := g.Geocode(context.Background(), tt.address)
+			if tt.want == "" {
+				if err != ErrNotFound {
+					t.Errorf("Geocode(%q) = %+v, %v; want ErrNotFound", tt.address, point, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Geocode(%q) error = %v", tt.address, err)
+			}
+			if want := placePoint(t, tt.want); point != want {
+				t.Errorf("Geocode(%q) = %+v, want %s %+v", tt.address, point, tt.want, want)
+			}
+		})
+	}
+}
+
+func placePoint(t *testing.T, name string) Point {
+	t.Helper()
+	for _, p := range sultraPlaces {
+		if p.name == name {
+			return p.point
+		}
+	}
+	t.Fatalf("unknown place %q", name)
+	return Point{}
+}
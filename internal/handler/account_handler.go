@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// ExportData mengunduh semua data pribadi user sebagai arsip ZIP, atau JSON biasa dengan ?format=json
+func (h *AccountHandler) ExportData(ctx *gin.Context) {
+	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	export, err := h.accountService.ExportData(ctx, currentUserID)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to export account data", http.StatusInternalServerError, err)
+		return
+	}
+
+	if ctx.Query("format") == "json" {
+		helper.APIResponse(ctx, "Account data exported successfully", http.StatusOK, export)
+		return
+	}
+
+	filename := fmt.Sprintf("sultra-otomotif-data-%s.zip", export.ExportedAt.Format("20060102"))
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Status(http.StatusOK)
+	if err := writeExportZip(ctx.Writer, export); err != nil {
+		// Header sudah terkirim; yang bisa dilakukan hanya memutus arsip di tengah jalan
+		ctx.Error(err)
+	}
+}
+
+// writeExportZip menulis setiap bagian data sebagai file JSON terpisah di dalam arsip
+func writeExportZip(w http.ResponseWriter, export model.AccountExport) error {
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"bookings.json", export.Bookings},
+		{"purchases.json", export.Purchases},
+		{"sales.json", export.Sales},
+		{"vehicles.json", export.Vehicles},
+		{"reviews.json", export.Reviews},
+		{"conversations.json", export.Conversations},
+		{"messages.json", export.Messages},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// DeleteAccount menghapus (menganonimkan) akun user yang sedang login
+func (h *AccountHandler) DeleteAccount(ctx *gin.Context) {
+	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	var input model.DeleteAccountInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.accountService.DeleteAccount(ctx, currentUserID, input); err != nil {
+		helper.ErrorResponse(ctx, "Failed to delete account", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Account deleted successfully", http.StatusOK, nil)
+}
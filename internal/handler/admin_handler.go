@@ -2,8 +2,8 @@ package handler
 
 import (
 	"net/http"
-	"strings"
 	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -19,12 +19,17 @@ func NewAdminHandler(adminService service.AdminService) *AdminHandler {
 }
 
 func (h *AdminHandler) GetVendors(ctx *gin.Context) {
-	vendors, err := h.adminService.GetVendors(ctx)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	vendors, err := h.adminService.GetVendors(ctx, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch vendors", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched all vendors", http.StatusOK, vendors)
+	helper.PageResponse(ctx, "Successfully fetched all vendors", http.StatusOK, vendors)
 }
 
 func (h *AdminHandler) VerifyVendor(ctx *gin.Context) {
@@ -36,24 +41,41 @@ func (h *AdminHandler) VerifyVendor(ctx *gin.Context) {
 
 	updatedVendor, err := h.adminService.VerifyVendor(ctx, vendorID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusNotFound, err)
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusBadRequest, err)
-		}
+		helper.ErrorResponse(ctx, "Failed to verify vendor", http.StatusInternalServerError, err)
 		return
 	}
 
 	helper.APIResponse(ctx, "Vendor verified successfully", http.StatusOK, updatedVendor)
 }
 
+func (h *AdminHandler) UnverifyVendor(ctx *gin.Context) {
+	vendorID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid vendor ID", http.StatusBadRequest, err)
+		return
+	}
+
+	updatedVendor, err := h.adminService.UnverifyVendor(ctx, vendorID)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to unverify vendor", http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.APIResponse(ctx, "Vendor verification revoked successfully", http.StatusOK, updatedVendor)
+}
+
 func (h *AdminHandler) GetAllUsers(ctx *gin.Context) {
-	users, err := h.adminService.GetAllUsers(ctx)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	users, err := h.adminService.GetAllUsers(ctx, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch users", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched all users", http.StatusOK, users)
+	helper.PageResponse(ctx, "Successfully fetched all users", http.StatusOK, users)
 }
 
 // FUNGSI BARU:
@@ -72,13 +94,33 @@ func (h *AdminHandler) DeleteUser(ctx *gin.Context) {
 	helper.APIResponse(ctx, "User deleted successfully", http.StatusOK, nil)
 }
 
+// RevokeUserSessions mengeluarkan user dari semua perangkat
+func (h *AdminHandler) RevokeUserSessions(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid user ID", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.adminService.RevokeUserSessions(ctx, userID); err != nil {
+		helper.ErrorResponse(ctx, "Failed to revoke user sessions", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "All sessions of the user have been revoked", http.StatusOK, nil)
+}
+
 func (h *AdminHandler) GetAllVehicles(ctx *gin.Context) {
-	vehicles, err := h.adminService.GetAllVehicles(ctx)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	vehicles, err := h.adminService.GetAllVehicles(ctx, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch vehicles", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched all vehicles", http.StatusOK, vehicles)
+	helper.PageResponse(ctx, "Successfully fetched all vehicles", http.StatusOK, vehicles)
 }
 
 // FUNGSI BARU:
@@ -94,5 +136,54 @@ func (h *AdminHandler) DeleteVehicle(ctx *gin.Context) {
 		helper.ErrorResponse(ctx, "Failed to delete vehicle", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Vehicle deleted successfully", http.StatusOK, nil)
+	helper.APIResponse(ctx, "Vehicle archived successfully", http.StatusOK, nil)
+}
+
+func (h *AdminHandler) GetLoginLocks(ctx *gin.Context) {
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	locks, err := h.adminService.GetLoginLocks(ctx, page)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to fetch login locks", http.StatusInternalServerError, err)
+		return
+	}
+	helper.PageResponse(ctx, "Successfully fetched login locks", http.StatusOK, locks)
+}
+
+// ClearLoginLock membuka blokir login untuk satu akun (scope "account", key email) atau IP (scope "ip")
+func (h *AdminHandler) ClearLoginLock(ctx *gin.Context) {
+	if err := h.adminService.ClearLoginLock(ctx, ctx.Param("scope"), ctx.Param("key")); err != nil {
+		helper.ErrorResponse(ctx, "Failed to clear login lock", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Login lock cleared successfully", http.StatusOK, nil)
+}
+
+func (h *AdminHandler) GetSettings(ctx *gin.Context) {
+	settings, err := h.adminService.GetSettings(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to fetch platform settings", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Successfully fetched platform settings", http.StatusOK, settings)
+}
+
+// UpdateSettings mengubah pengaturan platform, misal mewajibkan 2FA untuk semua admin
+func (h *AdminHandler) UpdateSettings(ctx *gin.Context) {
+	var input model.UpdatePlatformSettingsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+	adminID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	settings, err := h.adminService.UpdateSettings(ctx, input, adminID)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to update platform settings", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Platform settings updated successfully", http.StatusOK, settings)
 }
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+func (h *APIKeyHandler) ListKeys(ctx *gin.Context) {
+	userID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	keys, err := h.apiKeyService.ListKeys(ctx, userID)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to fetch API keys", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Successfully fetched API keys", http.StatusOK, keys)
+}
+
+// CreateKey membuat API key baru. Key lengkap hanya ditampilkan di response ini.
+func (h *APIKeyHandler) CreateKey(ctx *gin.Context) {
+	var input model.CreateAPIKeyInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateKey(ctx, currentSubject(ctx), input)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to create API key", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "API key created, store it now because it will not be shown again", http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) RevokeKey(ctx *gin.Context) {
+	keyID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid API key ID", http.StatusBadRequest, err)
+		return
+	}
+	userID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	if err := h.apiKeyService.RevokeKey(ctx, userID, keyID); err != nil {
+		helper.ErrorResponse(ctx, "Failed to revoke API key", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "API key revoked successfully", http.StatusOK, nil)
+}
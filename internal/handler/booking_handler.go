@@ -2,7 +2,6 @@ package handler
 
 import (
 	"net/http"
-	"strings"
 	"sultra-otomotif-api/internal/helper"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/service"
@@ -36,12 +35,8 @@ func (h *BookingHandler) CreateBooking(ctx *gin.Context) {
 
 	booking, err := h.bookingService.CreateBooking(ctx, input, currentUserID)
 	if err != nil {
-		// Beri status code yang lebih sesuai jika kendaraan tidak tersedia
-		if err.Error() == "vehicle is not available for the selected dates" {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusConflict, err) // 409 Conflict
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusBadRequest, err)
-		}
+		// Status code (misal 409 jika kendaraan tidak tersedia) ditentukan oleh jenis error dari service
+		helper.ErrorResponse(ctx, "Failed to create booking", http.StatusInternalServerError, err)
 		return
 	}
 	helper.APIResponse(ctx, "Booking created successfully, waiting for payment", http.StatusCreated, booking)
@@ -86,23 +81,33 @@ func (h *BookingHandler) PaymentCallback(ctx *gin.Context) {
 func (h *BookingHandler) GetMyBookings(ctx *gin.Context) {
 	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
 
-	bookings, err := h.bookingService.GetBookingsByUserID(ctx, currentUserID)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	bookings, err := h.bookingService.GetBookingsByUserID(ctx, currentUserID, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch bookings", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched user bookings", http.StatusOK, bookings)
+	helper.PageResponse(ctx, "Successfully fetched user bookings", http.StatusOK, bookings)
 }
 
 func (h *BookingHandler) GetVendorBookings(ctx *gin.Context) {
 	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
 
-	bookings, err := h.bookingService.GetBookingsByOwnerID(ctx, currentUserID)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	bookings, err := h.bookingService.GetBookingsByOwnerID(ctx, currentUserID, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch vendor bookings", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched vendor bookings", http.StatusOK, bookings)
+	helper.PageResponse(ctx, "Successfully fetched vendor bookings", http.StatusOK, bookings)
 }
 
 func (h *BookingHandler) GetBookingByID(ctx *gin.Context) {
@@ -112,15 +117,9 @@ func (h *BookingHandler) GetBookingByID(ctx *gin.Context) {
 		return
 	}
 
-	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
-
-	booking, err := h.bookingService.GetBookingByID(ctx, id, currentUserID)
+	booking, err := h.bookingService.GetBookingByID(ctx, id, currentSubject(ctx))
 	if err != nil {
-		if err.Error() == "forbidden: you are not authorized to view this booking" {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusForbidden, err)
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusNotFound, err)
-		}
+		helper.ErrorResponse(ctx, "Failed to fetch booking", http.StatusInternalServerError, err)
 		return
 	}
 	helper.APIResponse(ctx, "Successfully fetched booking detail", http.StatusOK, booking)
@@ -139,17 +138,9 @@ func (h *BookingHandler) UpdateBookingStatus(ctx *gin.Context) {
 		return
 	}
 
-	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
-
-	updatedBooking, err := h.bookingService.UpdateBookingStatus(ctx, id, currentUserID, input.Status)
+	updatedBooking, err := h.bookingService.UpdateBookingStatus(ctx, id, currentSubject(ctx), input.Status)
 	if err != nil {
-		if strings.HasPrefix(err.Error(), "forbidden") {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusForbidden, err)
-		} else if strings.HasPrefix(err.Error(), "invalid status transition") {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusConflict, err) // 409 Conflict
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusNotFound, err)
-		}
+		helper.ErrorResponse(ctx, "Failed to update booking status", http.StatusInternalServerError, err)
 		return
 	}
 
@@ -2,8 +2,8 @@ package handler
 
 import (
 	"net/http"
-	"strings"
 	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -25,11 +25,17 @@ func (h *ChatHandler) StartConversation(ctx *gin.Context) {
 		return
 	}
 
-	customerID := ctx.MustGet("currentUserID").(uuid.UUID)
+	var input model.StartConversationInput
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&input); err != nil {
+			helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+			return
+		}
+	}
 
-	conversation, err := h.chatService.StartConversation(ctx, customerID, vehicleID)
+	conversation, err := h.chatService.StartConversation(ctx, currentSubject(ctx), vehicleID, input)
 	if err != nil {
-		helper.ErrorResponse(ctx, err.Error(), http.StatusConflict, err)
+		helper.ErrorResponse(ctx, "Failed to start conversation", http.StatusInternalServerError, err)
 		return
 	}
 
@@ -38,12 +44,17 @@ func (h *ChatHandler) StartConversation(ctx *gin.Context) {
 
 func (h *ChatHandler) ListConversations(ctx *gin.Context) {
 	userID := ctx.MustGet("currentUserID").(uuid.UUID)
-	conversations, err := h.chatService.GetConversationsForUser(ctx, userID)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	conversations, err := h.chatService.GetConversationsForUser(ctx, userID, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch conversations", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched user conversations", http.StatusOK, conversations)
+	helper.PageResponse(ctx, "Successfully fetched user conversations", http.StatusOK, conversations)
 }
 
 func (h *ChatHandler) GetMessages(ctx *gin.Context) {
@@ -53,15 +64,15 @@ func (h *ChatHandler) GetMessages(ctx *gin.Context) {
 		return
 	}
 
-	userID := ctx.MustGet("currentUserID").(uuid.UUID)
-	messages, err := h.chatService.GetMessagesForConversation(ctx, conversationID, userID)
+	page, err := helper.ParsePageRequest(ctx)
 	if err != nil {
-		if strings.HasPrefix(err.Error(), "forbidden") {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusForbidden, err)
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusNotFound, err)
-		}
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	messages, err := h.chatService.GetMessagesForConversation(ctx, conversationID, currentSubject(ctx), page)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to fetch messages", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched messages", http.StatusOK, messages)
+	helper.PageResponse(ctx, "Successfully fetched messages", http.StatusOK, messages)
 }
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+	"sultra-otomotif-api/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	keys *auth.KeySet
+}
+
+func NewJWKSHandler(keys *auth.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS mengembalikan kunci publik untuk verifikasi access token (format standar JWKS,
+// bukan dibungkus APIResponse) agar bisa dibaca langsung oleh library JWT di sisi klien.
+func (h *JWKSHandler) GetJWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcStateCookie menyimpan state login OIDC di browser yang memulai login. Cookie dikirim lintas
+// situs (frontend dan API bisa beda domain), sehingga frontend wajib memakai credentials: "include".
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// Providers menampilkan penyedia login yang aktif agar frontend bisa menampilkan tombolnya
+func (h *OIDCHandler) Providers(ctx *gin.Context) {
+	helper.APIResponse(ctx, "Login providers fetched successfully", http.StatusOK, h.oidcService.Providers())
+}
+
+// Authorize memulai login OIDC dan mengembalikan URL halaman login penyedia
+func (h *OIDCHandler) Authorize(ctx *gin.Context) {
+	authorization, err := h.oidcService.Authorize(ctx, ctx.Param("provider"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to start login", http.StatusInternalServerError, err)
+		return
+	}
+	setOIDCStateCookie(ctx, authorization.State, int(time.Until(authorization.ExpiresAt).Seconds()))
+	helper.APIResponse(ctx, "Login started", http.StatusOK, authorization)
+}
+
+// Callback menerima code & state yang diteruskan frontend dari redirect penyedia
+func (h *OIDCHandler) Callback(ctx *gin.Context) {
+	var input model.OIDCCallbackInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	browserState, _ := ctx.Cookie(oidcStateCookie)
+	// State hanya bisa dipakai sekali, jadi cookie-nya langsung dihapus apa pun hasilnya
+	setOIDCStateCookie(ctx, "", -1)
+
+	tokenResponse, err := h.oidcService.Callback(ctx, ctx.Param("provider"), input, browserState, helper.RequestMeta(ctx))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to login", http.StatusInternalServerError, err)
+		return
+	}
+
+	if tokenResponse.MFARequired {
+		helper.APIResponse(ctx, "Two-factor authentication required", http.StatusOK, tokenResponse)
+		return
+	}
+	helper.APIResponse(ctx, "Login successful", http.StatusOK, tokenResponse)
+}
+
+func setOIDCStateCookie(ctx *gin.Context, state string, maxAge int) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
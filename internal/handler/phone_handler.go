@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PhoneHandler struct {
+	phoneService service.PhoneVerificationService
+}
+
+func NewPhoneHandler(phoneService service.PhoneVerificationService) *PhoneHandler {
+	return &PhoneHandler{phoneService: phoneService}
+}
+
+// SendOTP mengirim kode verifikasi lewat SMS ke nomor telepon user yang sedang login
+func (h *PhoneHandler) SendOTP(ctx *gin.Context) {
+	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	sent, err := h.phoneService.SendOTP(ctx, currentUserID)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to send verification code", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Verification code sent", http.StatusOK, sent)
+}
+
+func (h *PhoneHandler) VerifyOTP(ctx *gin.Context) {
+	var input model.VerifyPhoneInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	user, err := h.phoneService.VerifyOTP(ctx, currentUserID, input)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to verify phone number", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Phone number verified successfully", http.StatusOK, user)
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"net/http"
-	"strings"
 	"sultra-otomotif-api/internal/helper"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/service"
@@ -39,18 +38,8 @@ func (h *ReviewHandler) CreateReview(ctx *gin.Context) {
 
 	review, err := h.reviewService.CreateReview(ctx, input, bookingID, currentUserID)
 	if err != nil {
-		// Memberikan status code yang lebih spesifik berdasarkan jenis error dari service
-		if strings.HasPrefix(err.Error(), "forbidden") {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusForbidden, err)
-		} else if err.Error() == "booking not found" {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusNotFound, err)
-		} else if err.Error() == "you can only review a completed booking" {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusConflict, err)
-		} else if strings.Contains(err.Error(), "duplicate key") { // Error dari unique constraint di DB
-			helper.ErrorResponse(ctx, "a review for this booking already exists", http.StatusConflict, err)
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusInternalServerError, err)
-		}
+		// Status code yang spesifik ditentukan oleh jenis error dari service
+		helper.ErrorResponse(ctx, "Failed to create review", http.StatusInternalServerError, err)
 		return
 	}
 
@@ -65,11 +54,16 @@ func (h *ReviewHandler) GetVehicleReviews(ctx *gin.Context) {
 		return
 	}
 
-	reviews, err := h.reviewService.GetReviewsByVehicleID(ctx, vehicleID)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	reviews, err := h.reviewService.GetReviewsByVehicleID(ctx, vehicleID, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch reviews", http.StatusInternalServerError, err)
 		return
 	}
 
-	helper.APIResponse(ctx, "Successfully fetched vehicle reviews", http.StatusOK, reviews)
+	helper.PageResponse(ctx, "Successfully fetched vehicle reviews", http.StatusOK, reviews)
 }
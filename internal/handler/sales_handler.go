@@ -28,7 +28,7 @@ func (h *SalesHandler) InitiatePurchase(ctx *gin.Context) {
 
 	transaction, err := h.salesService.InitiatePurchase(ctx, vehicleID, buyerID)
 	if err != nil {
-		helper.ErrorResponse(ctx, err.Error(), http.StatusConflict, err)
+		helper.ErrorResponse(ctx, "Failed to initiate purchase", http.StatusInternalServerError, err)
 		return
 	}
 
@@ -66,20 +66,30 @@ func (h *SalesHandler) PaymentCallback(ctx *gin.Context) {
 
 func (h *SalesHandler) GetMyPurchases(ctx *gin.Context) {
 	buyerID := ctx.MustGet("currentUserID").(uuid.UUID)
-	transactions, err := h.salesService.GetPurchasesByBuyerID(ctx, buyerID)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	transactions, err := h.salesService.GetPurchasesByBuyerID(ctx, buyerID, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch purchase history", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched purchase history", http.StatusOK, transactions)
+	helper.PageResponse(ctx, "Successfully fetched purchase history", http.StatusOK, transactions)
 }
 
 func (h *SalesHandler) GetMySales(ctx *gin.Context) {
 	sellerID := ctx.MustGet("currentUserID").(uuid.UUID)
-	transactions, err := h.salesService.GetSalesBySellerID(ctx, sellerID)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	transactions, err := h.salesService.GetSalesBySellerID(ctx, sellerID, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch sales history", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched sales history", http.StatusOK, transactions)
+	helper.PageResponse(ctx, "Successfully fetched sales history", http.StatusOK, transactions)
 }
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"sultra-otomotif-api/internal/authz"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentSubject mengambil user yang sedang login (diset oleh AuthMiddleware) untuk pengecekan policy
+func currentSubject(ctx *gin.Context) authz.Subject {
+	return ctx.MustGet("currentSubject").(authz.Subject)
+}
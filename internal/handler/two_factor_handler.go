@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+func (h *TwoFactorHandler) Status(ctx *gin.Context) {
+	userID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	status, err := h.twoFactorService.Status(ctx, userID)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to fetch two-factor status", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Successfully fetched two-factor status", http.StatusOK, status)
+}
+
+// Enroll membuat secret TOTP baru. 2FA belum aktif sampai dikonfirmasi lewat /auth/2fa/confirm.
+func (h *TwoFactorHandler) Enroll(ctx *gin.Context) {
+	userID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	enrollment, err := h.twoFactorService.Enroll(ctx, userID)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to start two-factor enrollment", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Scan the provisioning URI with your authenticator app, then confirm with a code", http.StatusOK, enrollment)
+}
+
+func (h *TwoFactorHandler) Confirm(ctx *gin.Context) {
+	var input model.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+	userID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	// Tanpa sesi berarti request memakai token tantangan login, jadi login diselesaikan sekalian
+	_, hasSession := ctx.Get("currentSessionID")
+
+	confirmation, err := h.twoFactorService.Confirm(ctx, userID, input.Code, !hasSession, helper.RequestMeta(ctx))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to confirm two-factor enrollment", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Two-factor authentication enabled, store the backup codes safely", http.StatusOK, confirmation)
+}
+
+// Verify menyelesaikan login dua langkah dengan token tantangan dari /auth/login
+func (h *TwoFactorHandler) Verify(ctx *gin.Context) {
+	var input model.TwoFactorVerifyInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	tokenResponse, err := h.twoFactorService.Verify(ctx, input, helper.RequestMeta(ctx))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to verify two-factor code", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Login successful", http.StatusOK, tokenResponse)
+}
+
+func (h *TwoFactorHandler) Disable(ctx *gin.Context) {
+	var input model.TwoFactorDisableInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+	userID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	if err := h.twoFactorService.Disable(ctx, userID, input); err != nil {
+		helper.ErrorResponse(ctx, "Failed to disable two-factor authentication", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Two-factor authentication disabled", http.StatusOK, nil)
+}
+
+func (h *TwoFactorHandler) RegenerateBackupCodes(ctx *gin.Context) {
+	var input model.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+	userID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	codes, err := h.twoFactorService.RegenerateBackupCodes(ctx, userID, input.Code)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to regenerate backup codes", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Backup codes regenerated, previous codes no longer work", http.StatusOK, gin.H{"backup_codes": codes})
+}
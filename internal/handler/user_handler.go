@@ -12,11 +12,11 @@ import (
 
 type UserHandler struct {
 	userService service.UserService
-	jwtSecret   string
+	authService service.AuthService
 }
 
-func NewUserHandler(userService service.UserService, jwtSecret string) *UserHandler {
-	return &UserHandler{userService: userService, jwtSecret: jwtSecret}
+func NewUserHandler(userService service.UserService, authService service.AuthService) *UserHandler {
+	return &UserHandler{userService: userService, authService: authService}
 }
 
 func (h *UserHandler) Register(ctx *gin.Context) {
@@ -29,7 +29,7 @@ func (h *UserHandler) Register(ctx *gin.Context) {
 
 	user, err := h.userService.RegisterUser(ctx, input)
 	if err != nil {
-		helper.ErrorResponse(ctx, err.Error(), http.StatusBadRequest, err)
+		helper.ErrorResponse(ctx, "Failed to register user", http.StatusInternalServerError, err)
 		return
 	}
 
@@ -55,22 +55,161 @@ func (h *UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, err := h.userService.LoginUser(ctx, input, h.jwtSecret)
+	tokenResponse, err := h.userService.LoginUser(ctx, input, helper.RequestMeta(ctx))
 	if err != nil {
-		helper.ErrorResponse(ctx, err.Error(), http.StatusUnauthorized, err)
+		helper.ErrorResponse(ctx, "Failed to login", http.StatusInternalServerError, err)
 		return
 	}
 
-	tokenResponse := model.AuthResponse{Token: token}
+	if tokenResponse.MFARequired {
+		helper.APIResponse(ctx, "Two-factor authentication required", http.StatusOK, tokenResponse)
+		return
+	}
 	helper.APIResponse(ctx, "Login successful", http.StatusOK, tokenResponse)
 }
 
+// Refresh menukar refresh token dengan pasangan access token & refresh token yang baru
+func (h *UserHandler) Refresh(ctx *gin.Context) {
+	var input model.RefreshTokenInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	tokenResponse, err := h.authService.RefreshTokens(ctx, input.RefreshToken, helper.RequestMeta(ctx))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to refresh token", http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.APIResponse(ctx, "Token refreshed successfully", http.StatusOK, tokenResponse)
+}
+
+// Logout mencabut sesi yang sedang dipakai sehingga access & refresh token-nya tidak berlaku lagi
+func (h *UserHandler) Logout(ctx *gin.Context) {
+	sessionID := ctx.MustGet("currentSessionID").(uuid.UUID)
+
+	if err := h.authService.Logout(ctx, sessionID); err != nil {
+		helper.ErrorResponse(ctx, "Failed to logout", http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.APIResponse(ctx, "Logout successful", http.StatusOK, nil)
+}
+
+// ListSessions menampilkan semua perangkat tempat user sedang login
+func (h *UserHandler) ListSessions(ctx *gin.Context) {
+	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+	sessionID := ctx.MustGet("currentSessionID").(uuid.UUID)
+
+	sessions, err := h.authService.ListSessions(ctx, currentUserID, sessionID)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to fetch sessions", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Successfully fetched active sessions", http.StatusOK, sessions)
+}
+
+// RevokeSession mengeluarkan satu perangkat dari jarak jauh, misal ponsel yang hilang
+func (h *UserHandler) RevokeSession(ctx *gin.Context) {
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid session ID", http.StatusBadRequest, err)
+		return
+	}
+	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	if err := h.authService.RevokeSession(ctx, currentUserID, sessionID); err != nil {
+		helper.ErrorResponse(ctx, "Failed to revoke session", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Session revoked successfully", http.StatusOK, nil)
+}
+
+// UpdateMe mengubah profil user yang sedang login (PATCH: hanya field yang dikirim yang diubah)
+func (h *UserHandler) UpdateMe(ctx *gin.Context) {
+	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+	sessionID := ctx.MustGet("currentSessionID").(uuid.UUID)
+
+	var input model.UpdateProfileInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(ctx, currentUserID, sessionID, input)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to update profile", http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.APIResponse(ctx, "Profile updated successfully", http.StatusOK, user)
+}
+
+// ForgotPassword mengirim link reset password. Response selalu sukses agar tidak membocorkan email yang terdaftar.
+func (h *UserHandler) ForgotPassword(ctx *gin.Context) {
+	var input model.ForgotPasswordInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.userService.RequestPasswordReset(ctx, input, helper.RequestMeta(ctx)); err != nil {
+		helper.ErrorResponse(ctx, "Failed to request password reset", http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.APIResponse(ctx, "If the email is registered, a password reset link has been sent", http.StatusOK, nil)
+}
+
+func (h *UserHandler) ResetPassword(ctx *gin.Context) {
+	var input model.ResetPasswordInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.userService.ResetPassword(ctx, input); err != nil {
+		helper.ErrorResponse(ctx, "Failed to reset password", http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.APIResponse(ctx, "Password has been reset, please login again", http.StatusOK, nil)
+}
+
+func (h *UserHandler) VerifyEmail(ctx *gin.Context) {
+	var input model.VerifyEmailInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.userService.VerifyEmail(ctx, input); err != nil {
+		helper.ErrorResponse(ctx, "Failed to verify email", http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.APIResponse(ctx, "Email verified successfully", http.StatusOK, nil)
+}
+
+// ResendVerification mengirim ulang email verifikasi untuk user yang sedang login
+func (h *UserHandler) ResendVerification(ctx *gin.Context) {
+	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+
+	if err := h.userService.SendEmailVerification(ctx, currentUserID, helper.RequestMeta(ctx)); err != nil {
+		helper.ErrorResponse(ctx, "Failed to send verification email", http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.APIResponse(ctx, "A verification email will be sent shortly", http.StatusOK, nil)
+}
+
 func (h *UserHandler) GetMe(ctx *gin.Context) {
 	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
 
 	user, err := h.userService.GetUserByID(ctx, currentUserID)
 	if err != nil {
-		helper.ErrorResponse(ctx, "Failed to get user profile", http.StatusNotFound, err)
+		helper.ErrorResponse(ctx, "Failed to get user profile", http.StatusInternalServerError, err)
 		return
 	}
 	helper.APIResponse(ctx, "Successfully fetched user profile", http.StatusOK, user)
@@ -2,8 +2,8 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
-	"strings"
 	"sultra-otomotif-api/internal/helper"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/service"
@@ -33,7 +33,7 @@ func (h *VehicleHandler) CreateVehicle(ctx *gin.Context) {
 
 	vehicle, err := h.vehicleService.CreateVehicle(ctx, input, currentUserID)
 	if err != nil {
-		helper.ErrorResponse(ctx, err.Error(), http.StatusInternalServerError, err)
+		helper.ErrorResponse(ctx, "Failed to create vehicle", http.StatusInternalServerError, err)
 		return
 	}
 	helper.APIResponse(ctx, "Vehicle created successfully", http.StatusCreated, vehicle)
@@ -46,12 +46,21 @@ func (h *VehicleHandler) GetAllVehicles(ctx *gin.Context) {
 		return
 	}
 
-	vehicles, err := h.vehicleService.GetAllVehicles(ctx, filter)
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+	result, err := h.vehicleService.GetAllVehicles(ctx, filter, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch all vehicles", http.StatusInternalServerError, err)
 		return
 	}
-	helper.APIResponse(ctx, "Successfully fetched all vehicles", http.StatusOK, vehicles)
+	if result.DidYouMean != "" {
+		helper.PageResponse(ctx, "Successfully fetched all vehicles", http.StatusOK, result.Page, gin.H{"did_you_mean": result.DidYouMean})
+		return
+	}
+	helper.PageResponse(ctx, "Successfully fetched all vehicles", http.StatusOK, result.Page)
 }
 
 func (h *VehicleHandler) GetVehicleByID(ctx *gin.Context) {
@@ -63,7 +72,7 @@ func (h *VehicleHandler) GetVehicleByID(ctx *gin.Context) {
 
 	vehicle, err := h.vehicleService.GetVehicleByID(ctx, id)
 	if err != nil {
-		helper.ErrorResponse(ctx, "Vehicle not found", http.StatusNotFound, err)
+		helper.ErrorResponse(ctx, "Failed to fetch vehicle", http.StatusInternalServerError, err)
 		return
 	}
 	helper.APIResponse(ctx, "Successfully fetched vehicle", http.StatusOK, vehicle)
@@ -82,44 +91,49 @@ func (h *VehicleHandler) UpdateVehicle(ctx *gin.Context) {
 		return
 	}
 
-	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
-
-	vehicle, err := h.vehicleService.UpdateVehicle(ctx, id, currentUserID, input)
+	vehicle, err := h.vehicleService.UpdateVehicle(ctx, id, currentSubject(ctx), input)
 	if err != nil {
-		// Cek jenis error untuk memberikan status code yang sesuai
-		if err.Error() == "forbidden: you are not the owner of this vehicle" {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusForbidden, err)
-		} else if err.Error() == "vehicle not found" {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusNotFound, err)
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusInternalServerError, err)
-		}
+		helper.ErrorResponse(ctx, "Failed to update vehicle", http.StatusInternalServerError, err)
 		return
 	}
 	helper.APIResponse(ctx, "Vehicle updated successfully", http.StatusOK, vehicle)
 }
 
-func (h *VehicleHandler) DeleteVehicle(ctx *gin.Context) {
+// UpdateVehicleStatus memindahkan listing ke status draft, published, maintenance atau archived
+func (h *VehicleHandler) UpdateVehicleStatus(ctx *gin.Context) {
 	id, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
 		helper.ErrorResponse(ctx, "Invalid vehicle ID", http.StatusBadRequest, err)
 		return
 	}
 
-	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
+	var input model.UpdateVehicleStatusInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+		return
+	}
 
-	err = h.vehicleService.DeleteVehicle(ctx, id, currentUserID)
+	vehicle, err := h.vehicleService.UpdateVehicleStatus(ctx, id, currentSubject(ctx), input)
 	if err != nil {
-		if err.Error() == "forbidden: you are not the owner of this vehicle" {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusForbidden, err)
-		} else if err.Error() == "vehicle not found" {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusNotFound, err)
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusInternalServerError, err)
-		}
+		helper.ErrorResponse(ctx, "Failed to update vehicle status", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Vehicle status updated successfully", http.StatusOK, vehicle)
+}
+
+func (h *VehicleHandler) DeleteVehicle(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid vehicle ID", http.StatusBadRequest, err)
 		return
 	}
-	helper.APIResponse(ctx, "Vehicle deleted successfully", http.StatusOK, nil)
+
+	err = h.vehicleService.DeleteVehicle(ctx, id, currentSubject(ctx))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to delete vehicle", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Vehicle archived successfully", http.StatusOK, nil)
 }
 
 func (h *VehicleHandler) UploadVehicleImage(ctx *gin.Context) {
@@ -129,8 +143,6 @@ func (h *VehicleHandler) UploadVehicleImage(ctx *gin.Context) {
 		return
 	}
 
-	currentUserID := ctx.MustGet("currentUserID").(uuid.UUID)
-
 	fileHeader, err := ctx.FormFile("image")
 	if err != nil {
 		helper.ErrorResponse(ctx, "Image file is required", http.StatusBadRequest, err)
@@ -146,18 +158,109 @@ func (h *VehicleHandler) UploadVehicleImage(ctx *gin.Context) {
 	defer file.Close() // Pastikan file ditutup setelah selesai
 
 	// Panggil service dengan file stream, bukan fileHeader atau filename
-	imageURL, err := h.vehicleService.UploadImage(ctx, vehicleID, currentUserID, file)
+	image, err := h.vehicleService.UploadImage(ctx, vehicleID, currentSubject(ctx), file)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to upload image", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Image uploaded successfully", http.StatusOK, image)
+}
+
+// UploadVehicleImages menerima banyak file pada field "images" dan mengembalikan hasil per file.
+// Status 207 dikirim jika ada file yang gagal.
+func (h *VehicleHandler) UploadVehicleImages(ctx *gin.Context) {
+	vehicleID, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
-		if strings.HasPrefix(err.Error(), "forbidden") {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusForbidden, err)
-		} else {
-			helper.ErrorResponse(ctx, err.Error(), http.StatusInternalServerError, err)
+		helper.ErrorResponse(ctx, "Invalid vehicle ID", http.StatusBadRequest, err)
+		return
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		helper.ErrorResponse(ctx, "Image files are required", http.StatusBadRequest, err)
+		return
+	}
+
+	results, err := h.vehicleService.UploadImages(ctx, vehicleID, currentSubject(ctx), form.File["images"])
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to upload images", http.StatusInternalServerError, err)
+		return
+	}
+
+	uploaded := 0
+	for _, result := range results {
+		if result.Success {
+			uploaded++
 		}
+	}
+	if uploaded < len(results) {
+		helper.APIResponse(ctx, fmt.Sprintf("%d of %d images uploaded", uploaded, len(results)), http.StatusMultiStatus, results)
+		return
+	}
+	helper.APIResponse(ctx, "Images uploaded successfully", http.StatusOK, results)
+}
+
+func (h *VehicleHandler) DeleteVehicleImage(ctx *gin.Context) {
+	vehicleID, imageID, ok := parseVehicleImageIDs(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.vehicleService.DeleteImage(ctx, vehicleID, imageID, currentSubject(ctx)); err != nil {
+		helper.ErrorResponse(ctx, "Failed to delete image", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Image deleted successfully", http.StatusOK, nil)
+}
+
+func (h *VehicleHandler) SetPrimaryVehicleImage(ctx *gin.Context) {
+	vehicleID, imageID, ok := parseVehicleImageIDs(ctx)
+	if !ok {
+		return
+	}
+
+	images, err := h.vehicleService.SetPrimaryImage(ctx, vehicleID, imageID, currentSubject(ctx))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to set primary image", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Primary image updated successfully", http.StatusOK, images)
+}
+
+func (h *VehicleHandler) ReorderVehicleImages(ctx *gin.Context) {
+	vehicleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid vehicle ID", http.StatusBadRequest, err)
+		return
+	}
+
+	var input model.ReorderImagesInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		helper.ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
 		return
 	}
 
-	response := gin.H{"image_url": imageURL}
-	helper.APIResponse(ctx, "Image uploaded successfully", http.StatusOK, response)
+	images, err := h.vehicleService.ReorderImages(ctx, vehicleID, currentSubject(ctx), input)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Failed to reorder images", http.StatusInternalServerError, err)
+		return
+	}
+	helper.APIResponse(ctx, "Images reordered successfully", http.StatusOK, images)
+}
+
+// parseVehicleImageIDs membaca parameter :id dan :image_id; response error sudah dikirim jika false
+func parseVehicleImageIDs(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	vehicleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid vehicle ID", http.StatusBadRequest, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	imageID, err := uuid.Parse(ctx.Param("image_id"))
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid image ID", http.StatusBadRequest, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return vehicleID, imageID, true
 }
 
 func (h *VehicleHandler) GetMyListings(ctx *gin.Context) {
@@ -174,12 +277,18 @@ func (h *VehicleHandler) GetMyListings(ctx *gin.Context) {
 		return
 	}
 
+	page, err := helper.ParsePageRequest(ctx)
+	if err != nil {
+		helper.ErrorResponse(ctx, "Invalid pagination parameters", http.StatusBadRequest, err)
+		return
+	}
+
 	// Panggil service dengan ID pemilik
-	vehicles, err := h.vehicleService.GetVehiclesByOwnerID(ctx, ownerID)
+	vehicles, err := h.vehicleService.GetVehiclesByOwnerID(ctx, ownerID, page)
 	if err != nil {
 		helper.ErrorResponse(ctx, "Failed to fetch listings", http.StatusInternalServerError, err)
 		return
 	}
 
-	helper.APIResponse(ctx, "Successfully fetched user listings", http.StatusOK, vehicles)
+	helper.PageResponse(ctx, "Successfully fetched user listings", http.StatusOK, vehicles)
 }
@@ -0,0 +1,40 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sultra-otomotif-api/internal/apperror"
+	"time"
+)
+
+// bindError mengubah error dari ShouldBindJSON/ShouldBindQuery (JSON rusak, tipe field salah,
+// tanggal, angka atau boolean yang tidak bisa diparse) menjadi apperror.Validation dengan alasan
+// yang aman dikirim ke klien. Mengembalikan nil jika err bukan error binding.
+func bindError(err error) *apperror.Error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		timeErr   *time.ParseError
+		numErr    *strconv.NumError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return apperror.Validation("empty_body", "request body is empty")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperror.Validation("malformed_json", "request body is not valid JSON")
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return apperror.Validation("invalid_field_type", "request body must be a JSON object")
+		}
+		return apperror.Validation("invalid_field_type", fmt.Sprintf("field %s must be of type %s", typeErr.Field, typeErr.Type.Kind())).
+			WithField(typeErr.Field, "type")
+	case errors.As(err, &timeErr):
+		return apperror.Validation("invalid_date", fmt.Sprintf("invalid date %q, expected format %s", timeErr.Value, timeErr.Layout))
+	case errors.As(err, &numErr):
+		return apperror.Validation("invalid_value", fmt.Sprintf("invalid value %q", numErr.Num))
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package helper
+
+import "strings"
+
+// NormalizeEmail mengubah email ke huruf kecil tanpa spasi di ujung, sehingga "Budi@Example.com"
+// dan "budi@example.com" dianggap akun yang sama
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
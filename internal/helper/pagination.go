@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrInvalidCursor = apperror.Validation("invalid_cursor", "invalid pagination cursor").WithField("cursor", "use the next_cursor value from the previous response")
+	ErrInvalidLimit  = apperror.Validation("invalid_limit", "invalid page size").WithField("limit", "must be between 1 and "+strconv.Itoa(model.MaxPageLimit))
+)
+
+// ParsePageRequest membaca query ?limit= dan ?cursor=. Limit default 20 dan maksimal 100.
+func ParsePageRequest(ctx *gin.Context) (model.PageRequest, error) {
+	page := model.PageRequest{Limit: model.DefaultPageLimit}
+
+	if raw := ctx.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > model.MaxPageLimit {
+			return model.PageRequest{}, ErrInvalidLimit
+		}
+		page.Limit = limit
+	}
+
+	if raw := ctx.Query("cursor"); raw != "" {
+		cursor, err := DecodeCursor(raw)
+		if err != nil {
+			return model.PageRequest{}, err
+		}
+		page.After = cursor
+	}
+	return page, nil
+}
+
+// EncodeCursor mengubah cursor menjadi string opaque yang aman untuk query string
+func EncodeCursor(cursor *model.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor membaca kembali string dari EncodeCursor
+func DecodeCursor(raw string) (*model.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor model.Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
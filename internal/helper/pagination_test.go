@@ -0,0 +1,49 @@
+package helper
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 7, 1, 8, 30, 0, 123456000, time.UTC)
+	price := 150000.5
+	cursors := map[string]*model.Cursor{
+		"time":  {Time: &createdAt, ID: uuid.New()},
+		"value": {Value: &price, ID: uuid.New(), Sort: "price_asc:sale_price"},
+		"null":  {ID: uuid.New(), Scope: "vendor"},
+		"key":   {Key: "toyota", ID: uuid.New()},
+	}
+	for name, cursor := range cursors {
+		t.Run(name, func(t *testing.T) {
+			encoded := EncodeCursor(cursor)
+			decoded, err := DecodeCursor(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, cursor) {
+				t.Errorf("DecodeCursor(EncodeCursor(c)) = %+v, want %+v", decoded, cursor)
+			}
+		})
+	}
+}
+
+func TestEncodeCursorNil(t *testing.T) {
+	if got := EncodeCursor(nil); got != "" {
+		t.Errorf("EncodeCursor(nil) = %q, want empty", got)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, raw := range []string{"%%%", base64.RawURLEncoding.EncodeToString([]byte("not json")), "eyJpZCI6MX0"} {
+		if _, err := DecodeCursor(raw); err != ErrInvalidCursor {
+			t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", raw, err)
+		}
+	}
+}
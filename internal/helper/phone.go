@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// defaultCountryCode dipakai untuk nomor lokal tanpa kode negara (misal 0812..., 812...)
+const defaultCountryCode = "62"
+
+// NormalizePhoneNumber mengubah nomor telepon ke format E.164 (misal "+6281234567890").
+// Spasi, tanda hubung, titik, dan kurung diabaikan. Nomor lokal Indonesia ("0812...", "812...")
+// dan "62812..." dianggap berkode negara +62; nomor berawalan "+" atau "00" dianggap internasional.
+func NormalizePhoneNumber(raw string) (string, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+
+	var digits string
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+		digits = cleaned[1:]
+	case strings.HasPrefix(cleaned, "00"):
+		digits = cleaned[2:]
+	case strings.HasPrefix(cleaned, "0"):
+		digits = defaultCountryCode + cleaned[1:]
+	case strings.HasPrefix(cleaned, defaultCountryCode):
+		digits = cleaned
+	default:
+		digits = defaultCountryCode + cleaned
+	}
+
+	if digits == "" || digits[0] == '0' || strings.Trim(digits, "0123456789") != "" {
+		return "", ErrInvalidPhoneNumber
+	}
+	// E.164 maksimal 15 digit. Nomor Indonesia: kode negara + 8 s.d. 12 digit, tidak diawali 0.
+	if len(digits) > 15 || len(digits) < 8 {
+		return "", ErrInvalidPhoneNumber
+	}
+	if strings.HasPrefix(digits, defaultCountryCode) {
+		national := digits[len(defaultCountryCode):]
+		if len(national) < 8 || len(national) > 12 || national[0] == '0' {
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+	return "+" + digits, nil
+}
@@ -0,0 +1,41 @@
+package helper
+
+import "testing"
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "081234567890", want: "+6281234567890"},
+		{raw: "0812-3456-7890", want: "+6281234567890"},
+		{raw: "812 3456 7890", want: "+6281234567890"},
+		{raw: "+62 812 3456 7890", want: "+6281234567890"},
+		{raw: "6281234567890", want: "+6281234567890"},
+		{raw: "0062 812.3456.7890", want: "+6281234567890"},
+		{raw: "(0401) 312345", want: "+62401312345"},
+		{raw: "  +1 (415) 555-2671 ", want: "+14155552671"},
+		{raw: "", wantErr: true},
+		{raw: "0812abc4567", wantErr: true},
+		{raw: "0812", wantErr: true},
+		{raw: "+62 0812 3456 7890", wantErr: true},
+		{raw: "0812345678901234", wantErr: true},
+		{raw: "+1234567890123456", wantErr: true},
+		{raw: "+0812345678", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := NormalizePhoneNumber(tt.raw)
+			if tt.wantErr {
+				if err != ErrInvalidPhoneNumber {
+					t.Errorf("NormalizePhoneNumber(%q) = %q, %v; want ErrInvalidPhoneNumber", tt.raw, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizePhoneNumber(%q) = %q, %v; want %q", tt.raw, got, err, tt.want)
+			}
+		})
+	}
+}
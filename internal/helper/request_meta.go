@@ -0,0 +1,15 @@
+package helper
+
+import (
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMeta mengambil IP & user agent klien untuk diteruskan ke layer service
+func RequestMeta(ctx *gin.Context) model.RequestMeta {
+	return model.RequestMeta{
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
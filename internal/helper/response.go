@@ -1,23 +1,142 @@
 package helper
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
 
 // APIResponse adalah format standar response JSON
 func APIResponse(ctx *gin.Context, message string, statusCode int, data interface{}) {
-	jsonResponse := gin.H{
+	ctx.JSON(statusCode, envelope(message, statusCode, data))
+}
+
+// PageResponse sama seperti APIResponse untuk endpoint list, ditambah next_cursor & has_more.
+// Halaman berikutnya diambil dengan mengirim next_cursor sebagai query ?cursor=.
+// extra berisi field tambahan di level atas response (misal did_you_mean pada pencarian).
+func PageResponse[T any](ctx *gin.Context, message string, statusCode int, page model.Page[T], extra ...gin.H) {
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+	jsonResponse := envelope(message, statusCode, items)
+	jsonResponse["next_cursor"] = EncodeCursor(page.Next)
+	jsonResponse["has_more"] = page.HasMore
+	for _, fields := range extra {
+		for key, value := range fields {
+			jsonResponse[key] = value
+		}
+	}
+	ctx.JSON(statusCode, jsonResponse)
+}
+
+func envelope(message string, statusCode int, data interface{}) gin.H {
+	return gin.H{
 		"status_code": statusCode,
 		"message":     message,
 		"data":        data,
 	}
-	ctx.JSON(statusCode, jsonResponse)
 }
 
-// ErrorResponse adalah format standar untuk response error
+// ErrorResponse adalah format standar untuk response error.
+// Jika err adalah *apperror.Error, status code, pesan, code dan details diambil dari error tersebut
+// sehingga handler tidak perlu mencocokkan isi pesan error. Untuk apperror selain KindInternal hanya
+// pesannya yang dikirim ke klien, tanpa error penyebab yang dibungkus; error lain (query database,
+// library, dll.) cukup dicatat di log. Error binding pada status 400 diubah menjadi apperror.Validation.
 func ErrorResponse(ctx *gin.Context, message string, statusCode int, err error) {
+	code := defaultErrorCode(statusCode)
+	var details map[string]string
+	exposedError := ""
+
+	if statusCode == http.StatusBadRequest {
+		if bindErr := bindError(err); bindErr != nil {
+			err = bindErr
+		}
+	}
+
+	if appErr, ok := apperror.As(err); ok {
+		if appErr.Kind != apperror.KindInternal {
+			exposedError = appErr.Message
+		}
+		statusCode = apperror.HTTPStatus(appErr.Kind)
+		message = appErr.Message
+		code = appErr.Code
+		details = appErr.Fields
+		if appErr.RetryAfter > 0 {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
+	} else {
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			code = "validation_failed"
+			details = make(map[string]string, len(validationErrs))
+			for _, fe := range validationErrs {
+				details[toSnakeCase(fe.Field())] = fe.Tag()
+			}
+		}
+	}
+
 	jsonResponse := gin.H{
 		"status_code": statusCode,
 		"message":     message,
-		"error":       err.Error(),
+		"code":        code,
+	}
+	if err != nil {
+		if exposedError != "" {
+			jsonResponse["error"] = exposedError
+		} else {
+			log.Printf("error: %s %s -> %d: %v", ctx.Request.Method, ctx.Request.URL.Path, statusCode, err)
+		}
+	}
+	if len(details) > 0 {
+		jsonResponse["details"] = details
 	}
 	ctx.JSON(statusCode, jsonResponse)
 }
+
+func defaultErrorCode(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusTooManyRequests:
+		return "too_many_requests"
+	default:
+		return "internal_error"
+	}
+}
+
+// toSnakeCase mengubah nama field struct (misal "PhoneNumber", "VehicleID") menjadi nama field JSON ("phone_number", "vehicle_id")
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	isUpper := func(r rune) bool { return r >= 'A' && r <= 'Z' }
+
+	var b strings.Builder
+	for i, r := range runes {
+		if isUpper(r) {
+			prevLower := i > 0 && !isUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && !isUpper(runes[i+1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+func errorResponseBody(t *testing.T, message string, statusCode int, err error) (int, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/test", nil)
+
+	ErrorResponse(ctx, message, statusCode, err)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, body
+}
+
+func TestErrorResponseExposesDomainErrors(t *testing.T) {
+	notFound := apperror.NotFound("vehicle_not_found", "vehicle not found")
+	status, body := errorResponseBody(t, "Failed to get vehicle", http.StatusInternalServerError, notFound)
+
+	if status != http.StatusNotFound || body["code"] != "vehicle_not_found" || body["message"] != "vehicle not found" {
+		t.Errorf("got %d %v", status, body)
+	}
+	if body["error"] != "vehicle not found" {
+		t.Errorf("error = %v, want the domain error message", body["error"])
+	}
+}
+
+func TestErrorResponseHidesInternalErrors(t *testing.T) {
+	cases := map[string]error{
+		"plain":    errors.New(`pq: relation "vehicles" does not exist`),
+		"internal": apperror.Internal("storage_failed", "failed to store image", errors.New("s3: access denied")),
+	}
+	for name, err := range cases {
+		t.Run(name, func(t *testing.T) {
+			status, body := errorResponseBody(t, "Failed to get vehicle", http.StatusInternalServerError, err)
+			if status != http.StatusInternalServerError {
+				t.Errorf("status = %d, want 500", status)
+			}
+			if _, ok := body["error"]; ok {
+				t.Errorf("response exposes error %q", body["error"])
+			}
+		})
+	}
+}
+
+func TestErrorResponseOmitsWrappedCause(t *testing.T) {
+	invalidToken := apperror.Unauthorized("invalid_token", "invalid token").Wrap(errors.New("token is malformed: could not base64 decode signature"))
+	_, body := errorResponseBody(t, "Unauthorized", http.StatusUnauthorized, invalidToken)
+
+	if body["error"] != "invalid token" {
+		t.Errorf("error = %q, want only the domain message", body["error"])
+	}
+}
+
+func TestErrorResponseReportsBindErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	type input struct {
+		Year      int       `json:"year" form:"year"`
+		StartDate time.Time `json:"-" form:"start_date" time_format:"2006-01-02"`
+	}
+	tests := []struct {
+		name     string
+		request  *http.Request
+		query    bool
+		wantCode string
+	}{
+		{"empty body", httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("")), false, "empty_body"},
+		{"malformed json", httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"year":`)), false, "malformed_json"},
+		{"invalid syntax", httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{year: 2020}`)), false, "malformed_json"},
+		{"wrong field type", httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"year":"2020"}`)), false, "invalid_field_type"},
+		{"unparsable date", httptest.NewRequest(http.MethodGet, "/test?start_date=17-10-2026", nil), true, "invalid_date"},
+		{"unparsable number", httptest.NewRequest(http.MethodGet, "/test?year=baru", nil), true, "invalid_value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(rec)
+			ctx.Request = tt.request
+
+			var in input
+			var err error
+			if tt.query {
+				err = ctx.ShouldBindQuery(&in)
+			} else {
+				err = ctx.ShouldBindJSON(&in)
+			}
+			if err == nil {
+				t.Fatal("binding succeeded, want an error")
+			}
+			ErrorResponse(ctx, "Invalid input data", http.StatusBadRequest, err)
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusBadRequest || body["code"] != tt.wantCode {
+				t.Errorf("got %d %v, want code %s", rec.Code, body, tt.wantCode)
+			}
+			if reason, _ := body["error"].(string); reason == "" {
+				t.Error("response has no reason for the client")
+			}
+		})
+	}
+}
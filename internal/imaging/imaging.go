@@ -0,0 +1,151 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	_ "image/png" // registrasi decoder PNG
+	"io"
+	"math"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registrasi decoder WebP
+)
+
+var (
+	ErrUnsupportedType = errors.New("imaging: only JPEG, PNG and WebP images are accepted")
+	ErrTooLarge        = errors.New("imaging: image file is too large")
+	ErrTooManyPixels   = errors.New("imaging: image dimensions are too large")
+	ErrInvalidImage    = errors.New("imaging: invalid or corrupt image")
+)
+
+// Nama rendition standar
+const (
+	RenditionThumbnail = "thumbnail"
+	RenditionCard      = "card"
+	RenditionFull      = "full"
+)
+
+// ContentType adalah tipe semua rendition yang dihasilkan
+const ContentType = "image/jpeg"
+
+// Rendition adalah satu ukuran gambar yang dihasilkan. Gambar dikecilkan (tidak pernah diperbesar)
+// agar muat di dalam MaxWidth x MaxHeight dengan rasio aspek tetap.
+type Rendition struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// DefaultRenditions: thumbnail untuk daftar kecil, card untuk katalog, full untuk halaman detail
+var DefaultRenditions = []Rendition{
+	{Name: RenditionThumbnail, MaxWidth: 320, MaxHeight: 240},
+	{Name: RenditionCard, MaxWidth: 800, MaxHeight: 600},
+	{Name: RenditionFull, MaxWidth: 1920, MaxHeight: 1920},
+}
+
+type Options struct {
+	MaxBytes   int64 // ukuran file maksimal
+	MaxPixels  int   // lebar x tinggi maksimal, dicek sebelum gambar di-decode
+	Quality    int   // kualitas JPEG 1-100
+	Renditions []Rendition
+}
+
+// Result adalah satu rendition yang sudah di-encode sebagai JPEG
+type Result struct {
+	Name   string
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Processor memvalidasi gambar upload dan menghasilkan rendition-nya. Semua rendition di-encode ulang
+// sebagai JPEG sehingga metadata EXIF (termasuk lokasi GPS) tidak ikut tersimpan; orientasi dari EXIF
+// diterapkan lebih dulu agar foto dari ponsel tidak tampil miring.
+type Processor struct {
+	opts Options
+}
+
+func NewProcessor(opts Options) *Processor {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 15 << 20
+	}
+	if opts.MaxPixels <= 0 {
+		opts.MaxPixels = 40_000_000
+	}
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = 85
+	}
+	if len(opts.Renditions) == 0 {
+		opts.Renditions = DefaultRenditions
+	}
+	return &Processor{opts: opts}
+}
+
+var acceptedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+func (p *Processor) Process(r io.Reader) ([]Result, error) {
+	data, err := io.ReadAll(io.LimitReader(r, p.opts.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > p.opts.MaxBytes {
+		return nil, ErrTooLarge
+	}
+	// Tipe dibaca dari isi file, bukan dari nama file atau header dari klien
+	if !acceptedTypes[http.DetectContentType(data)] {
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > p.opts.MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	orientation := jpegOrientation(data)
+	results := make([]Result, 0, len(p.opts.Renditions))
+	for _, rendition := range p.opts.Renditions {
+		maxWidth, maxHeight := rendition.MaxWidth, rendition.MaxHeight
+		if orientation >= 5 {
+			// Orientasi 5-8 memutar gambar 90 derajat, jadi batasnya ditukar sebelum diputar
+			maxWidth, maxHeight = maxHeight, maxWidth
+		}
+		img := orient(fit(src, maxWidth, maxHeight), orientation)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.opts.Quality}); err != nil {
+			return nil, err
+		}
+		bounds := img.Bounds()
+		results = append(results, Result{Name: rendition.Name, Data: buf.Bytes(), Width: bounds.Dx(), Height: bounds.Dy()})
+	}
+	return results, nil
+}
+
+// fit mengecilkan src agar muat di maxWidth x maxHeight. Hasilnya selalu RGBA baru dengan latar
+// putih, sehingga bagian transparan PNG/WebP tidak menjadi hitam saat di-encode sebagai JPEG.
+func fit(src image.Image, maxWidth, maxHeight int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	scale := math.Min(1, math.Min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height)))
+	dstWidth := max(1, int(math.Round(float64(width)*scale)))
+	dstHeight := max(1, int(math.Round(float64(height)*scale)))
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
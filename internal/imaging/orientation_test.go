@@ -0,0 +1,100 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"testing"
+)
+
+// exifJPEG membuat awal file JPEG dengan segmen APP1 EXIF berisi satu tag Orientation
+func exifJPEG(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := new(bytes.Buffer)
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	binary.Write(tiff, order, uint16(42))
+	binary.Write(tiff, order, uint32(8)) // offset IFD0
+	binary.Write(tiff, order, uint16(1)) // jumlah entry
+	binary.Write(tiff, order, uint16(0x0112))
+	binary.Write(tiff, order, uint16(3)) // SHORT
+	binary.Write(tiff, order, uint32(1))
+	binary.Write(tiff, order, orientation)
+	binary.Write(tiff, order, uint16(0))
+	binary.Write(tiff, order, uint32(0)) // tidak ada IFD berikutnya
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	data = binary.BigEndian.AppendUint16(data, uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0x00, 0x02)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	// Segmen APP0 (JFIF) sebelum EXIF harus dilewati
+	jfif := []byte{0xFF, 0xE0, 0x00, 0x06, 'J', 'F', 'I', 'F'}
+	withJFIF := append([]byte{0xFF, 0xD8}, jfif...)
+	withJFIF = append(withJFIF, exifJPEG(binary.BigEndian, 6)[2:]...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little endian", exifJPEG(binary.LittleEndian, 6), 6},
+		{"big endian", exifJPEG(binary.BigEndian, 8), 8},
+		{"after JFIF segment", withJFIF, 6},
+		{"out of range value", exifJPEG(binary.LittleEndian, 9), 1},
+		{"not a JPEG", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"no EXIF", []byte{0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02}, 1},
+		{"truncated segment", exifJPEG(binary.LittleEndian, 6)[:12], 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// Gambar 3x2 dengan piksel berlabel 1-6:
+	//   1 2 3
+	//   4 5 6
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := range 6 {
+		src.Pix[i*4] = uint8(i + 1)
+	}
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8 // baris demi baris hasil yang tampil tegak
+	}{
+		{1, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+		{2, [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{3, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{4, [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+		{5, [][]uint8{{1, 4}, {2, 5}, {3, 6}}},
+		{6, [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{7, [][]uint8{{6, 3}, {5, 2}, {4, 1}}},
+		{8, [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+	}
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+		if dst.Bounds().Dy() != len(tt.want) || dst.Bounds().Dx() != len(tt.want[0]) {
+			t.Errorf("orientation %d: size %v, want %dx%d", tt.orientation, dst.Bounds().Size(), len(tt.want[0]), len(tt.want))
+			continue
+		}
+		for y, row := range tt.want {
+			for x, label := range row {
+				if got := dst.Pix[dst.PixOffset(x, y)]; got != label {
+					t.Errorf("orientation %d: pixel (%d,%d) = %d, want %d", tt.orientation, x, y, got, label)
+				}
+			}
+		}
+	}
+}
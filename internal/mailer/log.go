@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// LogMailer tidak benar-benar mengirim email. Isi email ditulis ke log dengan token disensor, dan
+// jika dir diisi, disimpan utuh sebagai file .eml agar link bisa dibuka saat development atau diperiksa di test.
+type LogMailer struct {
+	dir string
+}
+
+func NewLogMailer(dir string) *LogMailer {
+	return &LogMailer{dir: dir}
+}
+
+var (
+	unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+	tokenParam      = regexp.MustCompile(`(?i)(token=)[^\s&]+`)
+)
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("mailer: to=%s subject=%q\n%s", msg.To, msg.Subject, redactTokens(msg.Body))
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	return os.WriteFile(filepath.Join(m.dir, name), buildMessage("no-reply@localhost", msg), 0o644)
+}
+
+// redactTokens menyensor nilai parameter token di link agar log tidak bisa dipakai untuk mengambil alih akun
+func redactTokens(body string) string {
+	return tokenParam.ReplaceAllString(body, "${1}[REDACTED]")
+}
@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogMailerWritesOutbox(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	m := NewLogMailer(dir)
+
+	msg := Message{To: "budi@example.com", Subject: "Verifikasi email", Body: "Halo Budi,\nklik link berikut."}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("outbox has %d files (%v), want 1", len(files), err)
+	}
+	name := files[0].Name()
+	if !strings.HasSuffix(name, "_budi_example.com.eml") {
+		t.Errorf("file name %q does not end with the sanitized recipient", name)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"To: budi@example.com\r\n", "Subject: Verifikasi email\r\n", "Halo Budi,\r\nklik link berikut."} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("message does not contain %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestLogMailerWithoutOutbox(t *testing.T) {
+	if err := NewLogMailer("").Send(context.Background(), Message{To: "a@example.com"}); err != nil {
+		t.Errorf("Send() error = %v, want nil", err)
+	}
+}
+
+func TestLogMailerRedactsTokensInLog(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	body := "Buka link berikut:\n\nhttps://app.example.com/reset-password?token=s3cr3t-t0ken&lang=id"
+	if err := NewLogMailer("").Send(context.Background(), Message{To: "a@example.com", Subject: "Reset", Body: body}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "s3cr3t-t0ken") {
+		t.Errorf("log contains the raw token:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "reset-password?token=[REDACTED]&lang=id") {
+		t.Errorf("log does not contain the redacted link:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,16 @@
+package mailer
+
+import "context"
+
+// Message adalah satu email teks biasa
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email transaksional (reset password, verifikasi email, dll).
+// Implementasinya dipilih dari konfigurasi: SMTPMailer untuk produksi, LogMailer untuk lokal.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -4,63 +4,114 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"sultra-otomotif-api/internal/authz"
 	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 )
 
-func AuthMiddleware(secretKey string) gin.HandlerFunc {
+// AuthMiddleware memverifikasi access token dan memastikan sesinya belum dicabut.
+// Integrasi antar sistem dapat memakai header X-API-Key sebagai pengganti access token;
+// permission-nya dibatasi oleh scope key tersebut dan tidak ada currentSessionID.
+func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
+		rawKey := c.GetHeader("X-API-Key")
+		if rawKey == "" {
+			authenticateSession(c, authService)
+			return
+		}
 
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			helper.ErrorResponse(c, "Authorization header is required", http.StatusUnauthorized, errors.New("missing bearer token"))
+		key, subject, err := authService.AuthenticateAPIKey(c, rawKey)
+		if err != nil {
+			helper.ErrorResponse(c, "Invalid API key", http.StatusUnauthorized, err)
 			c.Abort()
 			return
 		}
+		setCurrentUser(c, subject)
+		c.Set("currentAPIKeyID", key.ID)
+		c.Next()
+	}
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(secretKey), nil
-		})
+// SessionAuthMiddleware hanya menerima access token dari login. Dipakai untuk endpoint yang
+// mengelola akun dan sesi sendiri (logout, ubah profil, API key) yang tidak boleh diakses lewat API key.
+func SessionAuthMiddleware(authService service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticateSession(c, authService)
+	}
+}
 
-		if err != nil || !token.Valid {
-			helper.ErrorResponse(c, "Invalid token", http.StatusUnauthorized, err)
-			c.Abort()
+func authenticateSession(c *gin.Context, authService service.AuthService) {
+	tokenString, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	claims, err := authService.AuthenticateAccessToken(c, tokenString, helper.RequestMeta(c))
+	if err != nil {
+		helper.ErrorResponse(c, "Invalid token", http.StatusUnauthorized, err)
+		c.Abort()
+		return
+	}
+
+	// Set data user ke context agar bisa diakses oleh handler selanjutnya
+	setCurrentUser(c, authz.Subject{UserID: claims.UserID, Role: claims.Role})
+	c.Set("currentSessionID", claims.SessionID)
+	c.Next()
+}
+
+// EnrollmentAuthMiddleware dipakai di endpoint pendaftaran 2FA. Selain access token biasa, middleware ini
+// menerima token tantangan "mfa_enroll" dari login admin yang diwajibkan 2FA tapi belum mendaftar.
+// Request dengan token tantangan tidak punya currentSessionID.
+func EnrollmentAuthMiddleware(authService service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := bearerToken(c)
+		if !ok {
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			helper.ErrorResponse(c, "Failed to parse claims", http.StatusUnauthorized, errors.New("invalid claims format"))
-			c.Abort()
+		claims, err := authService.AuthenticateAccessToken(c, tokenString, helper.RequestMeta(c))
+		if err == nil {
+			setCurrentUser(c, authz.Subject{UserID: claims.UserID, Role: claims.Role})
+			c.Set("currentSessionID", claims.SessionID)
+			c.Next()
 			return
 		}
 
-		userID, err := uuid.Parse(claims["user_id"].(string))
-		if err != nil {
-			helper.ErrorResponse(c, "Invalid user ID in token", http.StatusUnauthorized, err)
+		challenge, challengeErr := authService.ParseChallenge(tokenString, service.ChallengePurposeMFAEnroll)
+		if challengeErr != nil {
+			helper.ErrorResponse(c, "Invalid token", http.StatusUnauthorized, err)
 			c.Abort()
 			return
 		}
-
-		// Set data user ke context agar bisa diakses oleh handler selanjutnya
-		c.Set("currentUserID", userID)
-		c.Set("currentUserRole", claims["role"].(string))
+		setCurrentUser(c, authz.Subject{UserID: challenge.UserID, Role: challenge.Role})
 		c.Next()
 	}
 }
 
-// Middleware untuk mengecek role
-func RoleMiddleware(requiredRole string) gin.HandlerFunc {
+func bearerToken(c *gin.Context) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		helper.ErrorResponse(c, "Authorization header is required", http.StatusUnauthorized, errors.New("missing bearer token"))
+		c.Abort()
+		return "", false
+	}
+	return strings.TrimPrefix(authHeader, "Bearer "), true
+}
+
+func setCurrentUser(c *gin.Context, subject authz.Subject) {
+	c.Set("currentUserID", subject.UserID)
+	c.Set("currentUserRole", subject.Role)
+	c.Set("currentSubject", subject)
+}
+
+// RequirePermission mengizinkan request jika user memiliki salah satu permission yang disebutkan.
+// Pengecekan kepemilikan resource (cakupan "own") dilakukan di layer service lewat policy authz.
+func RequirePermission(perms ...authz.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("currentUserRole")
-		if !exists || userRole.(string) != requiredRole {
+		subject, exists := c.Get("currentSubject")
+		if !exists || !subject.(authz.Subject).CanAny(perms...) {
 			helper.ErrorResponse(c, "You are not authorized to perform this action", http.StatusForbidden, errors.New("insufficient privileges"))
 			c.Abort()
 			return
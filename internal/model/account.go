@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// AccountExport berisi semua data pribadi seorang user, untuk diunduh lewat /auth/me/export
+type AccountExport struct {
+	ExportedAt    time.Time          `json:"exported_at"`
+	Profile       User               `json:"profile"`
+	Bookings      []Booking          `json:"bookings"`
+	Purchases     []SalesTransaction `json:"purchases"`
+	Sales         []SalesTransaction `json:"sales"`
+	Vehicles      []Vehicle          `json:"vehicles"`
+	Reviews       []Review           `json:"reviews"`
+	Conversations []Conversation     `json:"conversations"`
+	Messages      []Message          `json:"messages"`
+}
+
+// DeleteAccountInput mengonfirmasi penghapusan akun. Password wajib untuk akun yang punya password
+// (akun dari login OIDC bisa belum punya).
+type DeleteAccountInput struct {
+	CurrentPassword string `json:"current_password"`
+}
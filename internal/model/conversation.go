@@ -14,3 +14,9 @@ type Conversation struct {
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
+
+// StartConversationInput hanya dipakai vendor untuk memilih customer yang diajak bicara.
+// Customer tidak perlu mengirim body.
+type StartConversationInput struct {
+	CustomerID *uuid.UUID `json:"customer_id"`
+}
@@ -1,14 +1,117 @@
 package model
 
+import (
+	"strings"
+	"time"
+)
+
+// VehicleFilter adalah parameter query pencarian kendaraan. Field yang kosong/nol tidak dipakai sebagai filter.
 type VehicleFilter struct {
-	Type         string `form:"type"`
+	Type         string `form:"type" binding:"omitempty,oneof=mobil motor"`
 	Brand        string `form:"brand"`
 	Model        string `form:"model"`
-	Transmission string `form:"transmission"`
-	MinYear      int    `form:"min_year"`
-	MaxPrice     int    `form:"max_price"`
-	Search       string `form:"search"`
-	Sort         string `form:"sort"`
-	IsForSale    bool   `form:"is_for_sale"`
-	IsForRent    bool   `form:"is_for_rent"`
+	Transmission string `form:"transmission" binding:"omitempty,oneof=matic manual"`
+	Fuel         string `form:"fuel" binding:"omitempty,oneof=bensin diesel listrik"`
+	Color        string `form:"color"`
+	Location     string `form:"location"`
+	MinYear      int    `form:"min_year" binding:"omitempty,min=0"`
+	MaxYear      int    `form:"max_year" binding:"omitempty,gtefield=MinYear"`
+	// MinPrice & MaxPrice memakai harga sewa harian jika is_for_rent diisi, selain itu harga jual
+	MinPrice       float64 `form:"min_price" binding:"omitempty,min=0"`
+	MaxPrice       float64 `form:"max_price" binding:"omitempty,gtefield=MinPrice"`
+	MinSalePrice   float64 `form:"min_sale_price" binding:"omitempty,min=0"`
+	MaxSalePrice   float64 `form:"max_sale_price" binding:"omitempty,gtefield=MinSalePrice"`
+	MinRentalPrice float64 `form:"min_rental_price" binding:"omitempty,min=0"`
+	MaxRentalPrice float64 `form:"max_rental_price" binding:"omitempty,gtefield=MinRentalPrice"`
+	// Query adalah kata kunci full-text search (sintaks seperti mesin pencari: "frasa", -kata, or).
+	// Search adalah nama lama parameter yang sama dan hanya dipakai jika q kosong.
+	Query  string `form:"q"`
+	Search string `form:"search"`
+	// Sort: price_asc, price_desc, year_asc, year_desc, distance (butuh lat & lng), atau relevance
+	// (bawaan jika q diisi). SortBy adalah alias Sort.
+	Sort      string `form:"sort"`
+	SortBy    string `form:"sort_by"`
+	IsForSale bool   `form:"is_for_sale"`
+	IsForRent bool   `form:"is_for_rent"`
+	// StartDate & EndDate (YYYY-MM-DD) hanya menampilkan kendaraan sewa yang belum dibooking pada rentang tersebut
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02" binding:"required_with=EndDate"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02" binding:"required_with=StartDate,omitempty,gtefield=StartDate"`
+	// Lat & Lng adalah titik asal untuk radius_km, sort=distance dan distance_km di hasil
+	Lat      *float64 `form:"lat" binding:"required_with=Lng RadiusKm,omitempty,min=-90,max=90"`
+	Lng      *float64 `form:"lng" binding:"required_with=Lat,omitempty,min=-180,max=180"`
+	RadiusKm float64  `form:"radius_km" binding:"omitempty,gt=0,max=1000"`
+	// Bounding box (misal area peta yang sedang dilihat); keempat nilai harus diisi bersamaan
+	MinLat *float64 `form:"min_lat" binding:"required_with=MaxLat MinLng MaxLng,omitempty,min=-90,max=90"`
+	MaxLat *float64 `form:"max_lat" binding:"required_with=MinLat MinLng MaxLng,omitempty,min=-90,max=90,gtefield=MinLat"`
+	MinLng *float64 `form:"min_lng" binding:"required_with=MinLat MaxLat MaxLng,omitempty,min=-180,max=180"`
+	MaxLng *float64 `form:"max_lng" binding:"required_with=MinLat MaxLat MinLng,omitempty,min=-180,max=180,gtefield=MinLng"`
+}
+
+// SortOrder mengembalikan urutan yang diminta lewat sort, atau sort_by jika sort kosong
+func (f VehicleFilter) SortOrder() string {
+	if f.Sort != "" {
+		return f.Sort
+	}
+	return f.SortBy
+}
+
+// EffectiveSort mengembalikan urutan yang benar-benar dipakai pencarian: distance tanpa titik asal dan
+// relevance tanpa kata kunci diabaikan, lalu relevance menjadi bawaan jika q diisi dan "newest"
+// (terbaru dibuat) jika tidak.
+func (f VehicleFilter) EffectiveSort() string {
+	switch sort := f.SortOrder(); sort {
+	case "price_asc", "price_desc", "year_asc", "year_desc":
+		return sort
+	case "distance":
+		if f.HasOrigin() {
+			return sort
+		}
+	}
+	if f.SearchQuery() != "" {
+		return "relevance"
+	}
+	return "newest"
+}
+
+// CursorKey menandai urutan yang dipakai sebuah cursor, termasuk kolom harga untuk urutan harga,
+// agar cursor dari satu urutan tidak dipakai untuk urutan lain
+func (f VehicleFilter) CursorKey() string {
+	sort := f.EffectiveSort()
+	if strings.HasPrefix(sort, "price_") {
+		return sort + ":" + f.PriceColumn()
+	}
+	return sort
+}
+
+// HasOrigin menandakan titik asal lat/lng diisi sehingga jarak bisa dihitung
+func (f VehicleFilter) HasOrigin() bool {
+	return f.Lat != nil && f.Lng != nil
+}
+
+// HasDateRange menandakan pencarian dibatasi pada kendaraan yang bisa disewa di rentang tanggal tertentu
+func (f VehicleFilter) HasDateRange() bool {
+	return !f.StartDate.IsZero() && !f.EndDate.IsZero()
+}
+
+// PriceColumn menentukan kolom harga untuk filter min/max_price dan pengurutan harga
+func (f VehicleFilter) PriceColumn() string {
+	if f.IsForRent || f.HasDateRange() {
+		return "rental_price_daily"
+	}
+	return "sale_price"
+}
+
+// SearchQuery mengembalikan kata kunci pencarian dari q, atau dari search untuk klien lama
+func (f VehicleFilter) SearchQuery() string {
+	if q := strings.TrimSpace(f.Query); q != "" {
+		return q
+	}
+	return strings.TrimSpace(f.Search)
+}
+
+// VehicleSearchResult adalah hasil GET /vehicles. DidYouMean diisi jika kata kunci tidak
+// menemukan apa pun dan Page berisi hasil pencarian dengan kata kunci saran tersebut.
+type VehicleSearchResult struct {
+	Page       Page[Vehicle]
+	DidYouMean string
 }
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Scope penghitung login gagal
+const (
+	ThrottleScopeAccount = "account"
+	ThrottleScopeIP      = "ip"
+)
+
+// Permintaan email yang ikut dibatasi lewat tabel yang sama dengan login. Kuncinya diberi
+// prefix aksi agar tidak tercampur dengan penghitung login gagal.
+const (
+	ThrottleActionPasswordReset     = "password_reset"
+	ThrottleActionEmailVerification = "email_verification"
+)
+
+// ThrottleActions berisi semua prefix aksi email di atas. Penghitungnya bukan blokir login,
+// sehingga tidak ditampilkan di daftar blokir untuk admin.
+var ThrottleActions = []string{ThrottleActionPasswordReset, ThrottleActionEmailVerification}
+
+type LoginThrottle struct {
+	Scope          string     `json:"scope"`
+	Key            string     `json:"key"`
+	FailedAttempts int        `json:"failed_attempts"`
+	LastFailedAt   time.Time  `json:"last_failed_at"`
+	BlockedUntil   *time.Time `json:"blocked_until,omitempty"`
+}
+
+// RequestMeta berisi informasi klien dari request HTTP yang dibutuhkan layer service
+type RequestMeta struct {
+	IPAddress string
+	UserAgent string
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageRequest adalah parameter pagination keyset: jumlah item per halaman dan posisi item terakhir
+// halaman sebelumnya. Limit 0 berarti tanpa batas, hanya untuk pemakaian internal (misal ekspor data).
+type PageRequest struct {
+	Limit int
+	After *Cursor
+}
+
+// Cursor menyimpan nilai kolom urutan dari item terakhir sebuah halaman. Klien hanya melihatnya
+// sebagai string opaque (next_cursor). Field yang terisi tergantung urutan list-nya.
+// Sort mencatat urutan saat cursor dibuat untuk list yang urutannya bisa dipilih klien.
+type Cursor struct {
+	Time  *time.Time `json:"t,omitempty"`
+	Value *float64   `json:"v,omitempty"` // misal harga atau tahun; nil berarti NULL
+	ID    uuid.UUID  `json:"id"`
+	Scope string     `json:"s,omitempty"`
+	Key   string     `json:"k,omitempty"`
+	Sort  string     `json:"o,omitempty"`
+}
+
+// Page adalah satu halaman hasil list. Next terisi jika HasMore.
+type Page[T any] struct {
+	Items   []T
+	Next    *Cursor
+	HasMore bool
+}
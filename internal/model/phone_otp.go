@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PhoneOTP adalah kode sekali pakai untuk memverifikasi nomor telepon. PhoneNumber mencatat nomor
+// saat kode dikirim, sehingga kode tidak berlaku lagi jika user mengganti nomornya.
+type PhoneOTP struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	PhoneNumber string
+	CodeHash    string
+	Attempts    int
+	ExpiresAt   time.Time
+	ConsumedAt  *time.Time
+	CreatedAt   time.Time
+}
+
+// OTPSendLimit membatasi pengiriman OTP per user dan per nomor telepon: jeda minimal antar
+// pengiriman dan jumlah maksimal dalam satu jam
+type OTPSendLimit struct {
+	Cooldown   time.Duration
+	MaxPerHour int
+}
+
+type VerifyPhoneInput struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// PhoneOTPSent dikembalikan setelah OTP dikirim, agar frontend bisa menampilkan hitung mundur
+type PhoneOTPSent struct {
+	PhoneNumber string    `json:"phone_number"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ResendAt    time.Time `json:"resend_at"`
+}
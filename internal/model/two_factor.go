@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TwoFactor struct {
+	UserID          uuid.UUID
+	SecretEncrypted string
+	EnabledAt       *time.Time
+	LastUsedStep    *int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type TwoFactorStatus struct {
+	Enabled              bool       `json:"enabled"`
+	EnabledAt            *time.Time `json:"enabled_at,omitempty"`
+	Required             bool       `json:"required"`
+	BackupCodesRemaining int        `json:"backup_codes_remaining"`
+}
+
+// TwoFactorEnrollment dikembalikan saat mulai mendaftar 2FA. ProvisioningURI dijadikan QR code
+// oleh frontend; Secret ditampilkan untuk dimasukkan manual jika kamera tidak tersedia.
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorConfirmation berisi kode cadangan (hanya ditampilkan sekali). Auth terisi jika
+// pendaftaran dilakukan di tengah login (2FA diwajibkan), sehingga login langsung selesai.
+type TwoFactorConfirmation struct {
+	BackupCodes []string      `json:"backup_codes"`
+	Auth        *AuthResponse `json:"auth,omitempty"`
+}
+
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorVerifyInput struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code berisi kode 6 digit dari aplikasi authenticator atau salah satu kode cadangan
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorDisableInput struct {
+	// Password boleh kosong untuk akun tanpa password (hanya login lewat OIDC)
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// PlatformSettings adalah pengaturan platform yang bisa diubah admin
+type PlatformSettings struct {
+	Require2FAForAdmin bool `json:"require_2fa_for_admin"`
+}
+
+type UpdatePlatformSettingsInput struct {
+	Require2FAForAdmin *bool `json:"require_2fa_for_admin"`
+}
@@ -15,8 +15,16 @@ type User struct {
 	Role         string     `json:"role"`
 	IsVerified   bool       `json:"is_verified"`
 	VerifiedAt   *time.Time `json:"verified_at,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	// EmailVerifiedAt terisi setelah user mengonfirmasi email lewat link verifikasi
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// PhoneVerifiedAt terisi setelah user memasukkan OTP yang dikirim ke PhoneNumber
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+	// RequireVerifiedPhone adalah pengaturan vendor: customer harus punya nomor terverifikasi untuk booking
+	RequireVerifiedPhone bool `json:"require_verified_phone"`
+	// DeletedAt terisi jika akun sudah dihapus; data pribadinya sudah dianonimkan
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
 
 type RegisterUserInput struct {
@@ -27,11 +35,35 @@ type RegisterUserInput struct {
 	Role        string `json:"role" binding:"required,oneof=customer vendor"`
 }
 
+// UpdateProfileInput berisi field profil yang ingin diubah; field yang tidak dikirim tidak diubah.
+// Mengganti email atau password wajib menyertakan current_password.
+type UpdateProfileInput struct {
+	FullName        *string `json:"full_name" binding:"omitempty,min=1"`
+	PhoneNumber     *string `json:"phone_number" binding:"omitempty,min=1"`
+	Email           *string `json:"email" binding:"omitempty,email"`
+	NewPassword     *string `json:"new_password" binding:"omitempty,min=6"`
+	CurrentPassword string  `json:"current_password"`
+	// RequireVerifiedPhone hanya bisa diubah oleh vendor
+	RequireVerifiedPhone *bool `json:"require_verified_phone"`
+}
+
 type LoginUserInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string    `json:"token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitzero"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+}
+
+// LoginResponse adalah hasil POST /auth/login. Jika akun memakai 2FA (atau wajib mendaftar 2FA),
+// token belum diterbitkan; klien harus melanjutkan dengan ChallengeToken ke endpoint /auth/2fa.
+type LoginResponse struct {
+	AuthResponse
+	MFARequired           bool       `json:"mfa_required"`
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required,omitempty"`
+	ChallengeToken        string     `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt    *time.Time `json:"challenge_expires_at,omitempty"`
 }
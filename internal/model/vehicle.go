@@ -9,10 +9,50 @@ import (
 	"github.com/google/uuid"
 )
 
+// VehicleImage adalah satu foto kendaraan. ImageURL adalah rendition ukuran penuh; ThumbnailURL dan
+// CardURL adalah versi kecilnya (sama dengan ImageURL untuk gambar yang diupload sebelum ada rendition).
 type VehicleImage struct {
-	ID        uuid.UUID `json:"id"`
-	ImageURL  string    `json:"image_url"`
-	IsPrimary bool      `json:"is_primary"`
+	ID           uuid.UUID `json:"id"`
+	VehicleID    uuid.UUID `json:"-"`
+	ImageURL     string    `json:"image_url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CardURL      string    `json:"card_url"`
+	IsPrimary    bool      `json:"is_primary"`
+	SortOrder    int       `json:"sort_order"`
+	// Key file di penyimpanan, kosong untuk gambar dari URL luar
+	StorageKey   string `json:"-"`
+	ThumbnailKey string `json:"-"`
+	CardKey      string `json:"-"`
+}
+
+// StorageKeys mengembalikan key semua rendition yang tersimpan di penyimpanan
+func (vi VehicleImage) StorageKeys() []string {
+	var keys []string
+	for _, key := range []string{vi.StorageKey, vi.ThumbnailKey, vi.CardKey} {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ImageUploadResult adalah hasil satu file pada upload banyak gambar sekaligus. Image diisi jika
+// berhasil, Error jika gagal; file lain dalam request yang sama tidak terpengaruh.
+type ImageUploadResult struct {
+	Filename string            `json:"filename"`
+	Success  bool              `json:"success"`
+	Image    *VehicleImage     `json:"image,omitempty"`
+	Error    *ImageUploadError `json:"error,omitempty"`
+}
+
+type ImageUploadError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ReorderImagesInput berisi ID semua gambar kendaraan dalam urutan tampil yang baru
+type ReorderImagesInput struct {
+	ImageIDs []uuid.UUID `json:"image_ids" binding:"required,min=1"`
 }
 type Vehicle struct {
 	ID                 uuid.UUID     `json:"id"`
@@ -26,6 +66,11 @@ type Vehicle struct {
 	Transmission       string        `json:"transmission"`
 	Fuel               string        `json:"fuel"`
 	Status             string        `json:"status"`
+	StatusChangedAt    time.Time     `json:"status_changed_at"`
+	PublishedAt        *time.Time    `json:"published_at,omitempty"` // pertama kali ditampilkan di pencarian
+	ArchivedAt         *time.Time    `json:"archived_at,omitempty"`
+	MaintenanceStart   *time.Time    `json:"maintenance_start,omitempty"` // hanya terisi selama status maintenance
+	MaintenanceEnd     *time.Time    `json:"maintenance_end,omitempty"`
 	Description        *string       `json:"description,omitempty"` // <-- Pointer
 	IsForSale          bool          `json:"is_for_sale"`
 	SalePrice          *float64      `json:"sale_price,omitempty"` // <-- Pointer
@@ -35,9 +80,13 @@ type Vehicle struct {
 	RentalPriceMonthly *float64      `json:"rental_price_monthly,omitempty"` // <-- Pointer
 	Location           *string       `json:"location,omitempty"`
 	Features           []string      `json:"features,omitempty"`
+	Latitude           *float64      `json:"latitude,omitempty"`
+	Longitude          *float64      `json:"longitude,omitempty"`
+	DistanceKm         *float64      `json:"distance_km,omitempty"` // jarak dari titik lat/lng pencarian
 	Images             VehicleImages `json:"images"`
 	CreatedAt          time.Time     `json:"created_at"`
 	UpdatedAt          time.Time     `json:"updated_at"`
+	SearchRank         float64       `json:"-"` // skor relevansi, hanya terisi saat pencarian dengan q
 }
 
 type CreateVehicleInput struct {
@@ -58,6 +107,41 @@ type CreateVehicleInput struct {
 	RentalPriceMonthly float64  `json:"rental_price_monthly"`
 	Location           string   `json:"location"`
 	Features           []string `json:"features"`
+	// Koordinat opsional; jika kosong, koordinat dicari dari Location lewat geocoder
+	Latitude  *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	// Status awal saat membuat listing (default published); diabaikan saat update,
+	// perubahan status lewat UpdateVehicleStatusInput
+	Status string `json:"status" binding:"omitempty,oneof=draft published"`
+}
+
+// UpdateVehicleStatusInput memindahkan listing ke status lain. Tanggal maintenance (YYYY-MM-DD) hanya
+// dipakai untuk status maintenance: maintenance_end wajib, maintenance_start default hari ini.
+type UpdateVehicleStatusInput struct {
+	Status           string `json:"status" binding:"required,oneof=draft published maintenance archived"`
+	MaintenanceStart string `json:"maintenance_start"`
+	MaintenanceEnd   string `json:"maintenance_end"`
+}
+
+// MaintenanceOverlaps mengecek apakah rentang tanggal (inklusif) bertabrakan dengan jendela maintenance
+func (v Vehicle) MaintenanceOverlaps(start, end time.Time) bool {
+	if v.Status != "maintenance" || v.MaintenanceStart == nil || v.MaintenanceEnd == nil {
+		return false
+	}
+	return !start.After(*v.MaintenanceEnd) && !end.Before(*v.MaintenanceStart)
+}
+
+// IsListed mengecek apakah listing tampil di pencarian: published, atau maintenance yang jendelanya
+// belum dimulai atau sudah lewat. Sama dengan listedVehicleCondition.
+func (v Vehicle) IsListed() bool {
+	if v.Status == "published" {
+		return true
+	}
+	if v.Status != "maintenance" || v.MaintenanceStart == nil || v.MaintenanceEnd == nil {
+		return false
+	}
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	return !v.MaintenanceOverlaps(today, today)
 }
 
 type VehicleImages []VehicleImage
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func date(offset int) *time.Time {
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	d := today.AddDate(0, 0, offset)
+	return &d
+}
+
+func TestMaintenanceOverlaps(t *testing.T) {
+	inMaintenance := Vehicle{Status: "maintenance", MaintenanceStart: date(5), MaintenanceEnd: date(7)}
+	tests := []struct {
+		name       string
+		vehicle    Vehicle
+		start, end int
+		want       bool
+	}{
+		{"before the window", inMaintenance, 1, 4, false},
+		{"ends on the first day", inMaintenance, 1, 5, true},
+		{"inside the window", inMaintenance, 6, 6, true},
+		{"covers the window", inMaintenance, 0, 10, true},
+		{"starts on the last day", inMaintenance, 7, 9, true},
+		{"after the window", inMaintenance, 8, 9, false},
+		{"published vehicle", Vehicle{Status: "published"}, 5, 7, false},
+		{"stale window on published vehicle", Vehicle{Status: "published", MaintenanceStart: date(5), MaintenanceEnd: date(7)}, 5, 7, false},
+	}
+	for _, tt := range tests {
+		if got := tt.vehicle.MaintenanceOverlaps(*date(tt.start), *date(tt.end)); got != tt.want {
+			t.Errorf("%s: MaintenanceOverlaps() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsListed(t *testing.T) {
+	tests := []struct {
+		name    string
+		vehicle Vehicle
+		want    bool
+	}{
+		{"published", Vehicle{Status: "published"}, true},
+		{"draft", Vehicle{Status: "draft"}, false},
+		{"archived", Vehicle{Status: "archived"}, false},
+		{"sold", Vehicle{Status: "sold"}, false},
+		{"maintenance scheduled next week", Vehicle{Status: "maintenance", MaintenanceStart: date(7), MaintenanceEnd: date(9)}, true},
+		{"maintenance starting today", Vehicle{Status: "maintenance", MaintenanceStart: date(0), MaintenanceEnd: date(2)}, false},
+		{"maintenance ending today", Vehicle{Status: "maintenance", MaintenanceStart: date(-2), MaintenanceEnd: date(0)}, false},
+		{"maintenance already over", Vehicle{Status: "maintenance", MaintenanceStart: date(-5), MaintenanceEnd: date(-1)}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.vehicle.IsListed(); got != tt.want {
+			t.Errorf("%s: IsListed() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+
+	"sultra-otomotif-api/internal/oidc"
+	"sultra-otomotif-api/internal/oidc/oidctest"
+)
+
+const testRedirectURL = "http://localhost:3000/auth/callback/mock"
+
+var testIdentity = oidctest.Identity{Subject: "user-1", Email: "budi@example.com", EmailVerified: true, Name: "Budi"}
+
+func newTestProvider(t *testing.T) (*oidc.Provider, *oidctest.IdP) {
+	t.Helper()
+	idp := oidctest.NewIdP("client-1")
+	t.Cleanup(idp.Close)
+	provider := oidc.NewProvider(oidc.Config{
+		Name:        "mock",
+		Issuer:      idp.Issuer(),
+		ClientID:    "client-1",
+		RedirectURL: testRedirectURL,
+	}, nil)
+	return provider, idp
+}
+
+func TestNewPKCE(t *testing.T) {
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if want := base64.RawURLEncoding.EncodeToString(sum[:]); challenge != want {
+		t.Errorf("challenge = %q, want S256 of verifier %q", challenge, want)
+	}
+	if len(verifier) < 43 {
+		t.Errorf("verifier length = %d, RFC 7636 requires at least 43", len(verifier))
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	provider, idp := newTestProvider(t)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != idp.Issuer()+"/authorize" {
+		t.Errorf("endpoint = %q, want the discovered authorization endpoint", got)
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-1",
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := u.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	ctx := context.Background()
+
+	login := func(t *testing.T, provider *oidc.Provider, idp *oidctest.IdP, nonce, challenge string) string {
+		t.Helper()
+		authURL, err := provider.AuthCodeURL(ctx, "state-1", nonce, challenge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, _, err := idp.Login(authURL, testIdentity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	t.Run("valid code, verifier and nonce", func(t *testing.T) {
+		provider, idp := newTestProvider(t)
+		verifier, challenge, _ := oidc.NewPKCE()
+		code := login(t, provider, idp, "nonce-1", challenge)
+
+		claims, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := oidc.Claims{Subject: "user-1", Email: "budi@example.com", EmailVerified: true, Name: "Budi"}
+		if claims != want {
+			t.Errorf("claims = %+v, want %+v", claims, want)
+		}
+	})
+
+	t.Run("wrong PKCE verifier", func(t *testing.T) {
+		provider, idp := newTestProvider(t)
+		_, challenge, _ := oidc.NewPKCE()
+		otherVerifier, _, _ := oidc.NewPKCE()
+		code := login(t, provider, idp, "nonce-1", challenge)
+
+		if _, err := provider.Exchange(ctx, code, otherVerifier, "nonce-1"); !errors.Is(err, oidc.ErrExchangeFailed) {
+			t.Errorf("err = %v, want ErrExchangeFailed", err)
+		}
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		provider, idp := newTestProvider(t)
+		verifier, challenge, _ := oidc.NewPKCE()
+		code := login(t, provider, idp, "nonce-from-attacker", challenge)
+
+		if _, err := provider.Exchange(ctx, code, verifier, "nonce-1"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+			t.Errorf("err = %v, want ErrInvalidIDToken", err)
+		}
+	})
+
+	t.Run("code reused", func(t *testing.T) {
+		provider, idp := newTestProvider(t)
+		verifier, challenge, _ := oidc.NewPKCE()
+		code := login(t, provider, idp, "nonce-1", challenge)
+
+		if _, err := provider.Exchange(ctx, code, verifier, "nonce-1"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := provider.Exchange(ctx, code, verifier, "nonce-1"); !errors.Is(err, oidc.ErrExchangeFailed) {
+			t.Errorf("err = %v, want ErrExchangeFailed", err)
+		}
+	})
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewIdP("client-1")
+	defer idp.Close()
+
+	provider := oidc.NewProvider(oidc.Config{Name: "mock", Issuer: idp.Issuer() + "/other", ClientID: "client-1"}, nil)
+	if _, err := provider.AuthCodeURL(context.Background(), "s", "n", "c"); err == nil {
+		t.Error("AuthCodeURL succeeded with a mismatched issuer, want error")
+	}
+}
@@ -0,0 +1,214 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrExchangeFailed = errors.New("oidc: authorization code exchange failed")
+	ErrInvalidIDToken = errors.New("oidc: invalid id token")
+)
+
+// Claims adalah identitas user yang sudah diverifikasi dari ID token
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"` // sebagian penyedia mengirim string "true"
+	Name          string `json:"name"`
+}
+
+// VerifyIDToken memverifikasi tanda tangan, issuer, audience, masa berlaku, dan nonce ID token
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (Claims, error) {
+	if _, err := p.getDiscovery(ctx); err != nil {
+		return Claims{}, err
+	}
+
+	var c idTokenClaims
+	_, err := jwt.ParseWithClaims(rawIDToken, &c,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.keys.get(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	// Google mengirim issuer dengan atau tanpa skema "https://"
+	if c.Issuer != p.cfg.Issuer && "https://"+c.Issuer != p.cfg.Issuer {
+		return Claims{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, c.Issuer)
+	}
+	if c.Nonce == "" || c.Nonce != nonce {
+		return Claims{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if c.Subject == "" {
+		return Claims{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	verified := false
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = strings.EqualFold(v, "true")
+	}
+	return Claims{
+		Subject:       c.Subject,
+		Email:         strings.TrimSpace(c.Email),
+		EmailVerified: verified,
+		Name:          c.Name,
+	}, nil
+}
+
+// keyCache menyimpan kunci publik penyedia dari jwks_uri. Jika token memakai kid yang belum dikenal
+// (penyedia merotasi kunci), JWKS diambil ulang, paling sering sekali per menit.
+type keyCache struct {
+	uri    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func newKeyCache(uri string, client *http.Client) *keyCache {
+	return &keyCache{uri: uri, client: client}
+}
+
+func (kc *keyCache) get(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	if key, ok := kc.lookup(kid); ok {
+		return key, nil
+	}
+	if time.Since(kc.fetchedAt) < time.Minute {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if err := kc.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := kc.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookup mencari kunci berdasarkan kid; token tanpa kid hanya diterima jika JWKS berisi satu kunci
+func (kc *keyCache) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(kc.keys) == 1 {
+		for _, key := range kc.keys {
+			return key, true
+		}
+	}
+	key, ok := kc.keys[kid]
+	return key, ok
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (kc *keyCache) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, kc.uri, nil)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := doJSON(kc.client, req, &set); err != nil {
+		return fmt.Errorf("oidc: fetch JWKS: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue // lewati jenis kunci yang tidak didukung
+		}
+		keys[jwk.Kid] = key
+	}
+	kc.keys = keys
+	kc.fetchedAt = time.Now()
+	return nil
+}
+
+func (j jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key model.APIKey) (model.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (model.APIKey, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+type apiKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, last_used_at, expires_at, revoked_at, created_at`
+
+func scanAPIKey(row pgx.Row, k *model.APIKey) error {
+	return row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.Scopes, &k.LastUsedAt, &k.ExpiresAt, &k.RevokedAt, &k.CreatedAt)
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, k model.APIKey) (model.APIKey, error) {
+	query := `INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, expires_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)
+              RETURNING ` + apiKeyColumns
+	var created model.APIKey
+	err := scanAPIKey(r.db.QueryRow(ctx, query, k.ID, k.UserID, k.Name, k.Prefix, k.KeyHash, k.Scopes, k.ExpiresAt), &created)
+	return created, err
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	var k model.APIKey
+	err := scanAPIKey(r.db.QueryRow(ctx, query, keyHash), &k)
+	return k, err
+}
+
+func (r *apiKeyRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		var k model.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke mencabut key milik user tertentu. Mengembalikan false jika key tidak ditemukan atau sudah dicabut.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// TouchLastUsed memperbarui last_used_at paling sering sekali per menit agar
+// integrasi yang sangat aktif tidak menulis ke database di setiap request.
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE api_keys SET last_used_at = NOW()
+              WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"sultra-otomotif-api/internal/model"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -13,10 +15,11 @@ import (
 type BookingRepository interface {
 	IsVehicleAvailable(ctx context.Context, vehicleID uuid.UUID, startDate, endDate time.Time) (bool, error)
 	Create(ctx context.Context, booking model.Booking) (model.Booking, error)
-	FindBookingsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Booking, error)
+	FindBookingsByUserID(ctx context.Context, userID uuid.UUID, page model.PageRequest) (model.Page[model.Booking], error)
 	FindBookingByID(ctx context.Context, bookingID uuid.UUID) (model.Booking, error)
 	UpdateStatus(ctx context.Context, bookingID uuid.UUID, status string) error
-	FindBookingsByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]model.Booking, error)
+	Confirm(ctx context.Context, bookingID uuid.UUID) (bool, error)
+	FindBookingsByOwnerID(ctx context.Context, ownerID uuid.UUID, page model.PageRequest) (model.Page[model.Booking], error)
 }
 
 // bookingRepository adalah implementasi dari interface di atas
@@ -29,13 +32,17 @@ func NewBookingRepository(db *pgxpool.Pool) BookingRepository {
 	return &bookingRepository{db: db}
 }
 
+// bookingOverlapCondition cocok dengan booking aktif (alias b) yang tumpang tindih dengan rentang tanggal
+// di dua placeholder yang diberikan. Dipakai juga oleh filter tanggal di pencarian kendaraan.
+const bookingOverlapCondition = `b.status IN ('confirmed', 'rented_out')
+              AND (b.start_date, b.end_date) OVERLAPS ($%d, $%d)`
+
 // IsVehicleAvailable mengecek apakah ada booking lain yang tumpang tindih pada rentang tanggal tertentu
 func (r *bookingRepository) IsVehicleAvailable(ctx context.Context, vehicleID uuid.UUID, startDate, endDate time.Time) (bool, error) {
 	var count int
-	query := `SELECT count(*) FROM bookings
-              WHERE vehicle_id = $1
-              AND status IN ('confirmed', 'rented_out')
-              AND (start_date, end_date) OVERLAPS ($2, $3)`
+	query := `SELECT count(*) FROM bookings b
+              WHERE b.vehicle_id = $1
+              AND ` + fmt.Sprintf(bookingOverlapCondition, 2, 3)
 
 	err := r.db.QueryRow(ctx, query, vehicleID, startDate, endDate).Scan(&count)
 	if err != nil {
@@ -61,26 +68,15 @@ func (r *bookingRepository) Create(ctx context.Context, b model.Booking) (model.
 	return b, nil
 }
 
-// FindBookingsByUserID mengambil semua data booking milik seorang user
-func (r *bookingRepository) FindBookingsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Booking, error) {
-	var bookings []model.Booking
-	query := `SELECT id, user_id, vehicle_id, start_date, end_date, total_price, status, created_at, updated_at FROM bookings WHERE user_id = $1 ORDER BY created_at DESC`
-
-	rows, err := r.db.Query(ctx, query, userID)
-	if err != nil {
-		return nil, err
+// FindBookingsByUserID mengambil data booking milik seorang user, terbaru lebih dulu
+func (r *bookingRepository) FindBookingsByUserID(ctx context.Context, userID uuid.UUID, page model.PageRequest) (model.Page[model.Booking], error) {
+	args := []interface{}{userID}
+	query := `SELECT id, user_id, vehicle_id, start_date, end_date, total_price, status, created_at, updated_at FROM bookings WHERE user_id = $1`
+	if page.After != nil {
+		query += " AND " + beforeTime("created_at", "id", page.After, &args)
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var b model.Booking
-		err := rows.Scan(&b.ID, &b.UserID, &b.VehicleID, &b.StartDate, &b.EndDate, &b.TotalPrice, &b.Status, &b.CreatedAt, &b.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		bookings = append(bookings, b)
-	}
-	return bookings, nil
+	query += " ORDER BY created_at DESC, id DESC" + limitClause(page)
+	return r.queryPage(ctx, page, query, args...)
 }
 
 // FindBookingByID mengambil satu data booking berdasarkan ID-nya
@@ -102,28 +98,62 @@ func (r *bookingRepository) UpdateStatus(ctx context.Context, bookingID uuid.UUI
 	return err
 }
 
-func (r *bookingRepository) FindBookingsByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]model.Booking, error) {
-	var bookings []model.Booking
+// Confirm mengubah status booking menjadi confirmed, kecuali jika tanggalnya bertabrakan dengan jendela
+// maintenance kendaraan (mengembalikan false). Baris kendaraan dikunci agar jendela maintenance tidak
+// dijadwalkan di antara pengecekan dan perubahan status.
+func (r *bookingRepository) Confirm(ctx context.Context, bookingID uuid.UUID) (bool, error) {
+	confirmed := false
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		lock := `SELECT 1 FROM vehicles WHERE id = (SELECT vehicle_id FROM bookings WHERE id = $1) FOR UPDATE`
+		if _, err := tx.Exec(ctx, lock, bookingID); err != nil {
+			return err
+		}
+		query := `UPDATE bookings b SET status = 'confirmed', updated_at = NOW()
+                  FROM vehicles v
+                  WHERE b.id = $1 AND v.id = b.vehicle_id
+                  AND NOT (v.status = 'maintenance' AND b.start_date <= v.maintenance_end AND b.end_date >= v.maintenance_start)`
+		tag, err := tx.Exec(ctx, query, bookingID)
+		if err != nil {
+			return err
+		}
+		confirmed = tag.RowsAffected() == 1
+		return nil
+	})
+	return confirmed, err
+}
+
+func (r *bookingRepository) FindBookingsByOwnerID(ctx context.Context, ownerID uuid.UUID, page model.PageRequest) (model.Page[model.Booking], error) {
 	// Query ini menggunakan JOIN untuk menghubungkan tabel bookings dan vehicles
+	args := []interface{}{ownerID}
 	query := `SELECT b.id, b.user_id, b.vehicle_id, b.start_date, b.end_date, b.total_price, b.status, b.created_at, b.updated_at
               FROM bookings b
               JOIN vehicles v ON b.vehicle_id = v.id
-              WHERE v.owner_id = $1
-              ORDER BY b.created_at DESC`
+              WHERE v.owner_id = $1`
+	if page.After != nil {
+		query += " AND " + beforeTime("b.created_at", "b.id", page.After, &args)
+	}
+	query += " ORDER BY b.created_at DESC, b.id DESC" + limitClause(page)
+	return r.queryPage(ctx, page, query, args...)
+}
 
-	rows, err := r.db.Query(ctx, query, ownerID)
+func (r *bookingRepository) queryPage(ctx context.Context, page model.PageRequest, query string, args ...interface{}) (model.Page[model.Booking], error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return model.Page[model.Booking]{}, err
 	}
 	defer rows.Close()
 
+	var bookings []model.Booking
 	for rows.Next() {
 		var b model.Booking
 		err := rows.Scan(&b.ID, &b.UserID, &b.VehicleID, &b.StartDate, &b.EndDate, &b.TotalPrice, &b.Status, &b.CreatedAt, &b.UpdatedAt)
 		if err != nil {
-			return nil, err
+			return model.Page[model.Booking]{}, err
 		}
 		bookings = append(bookings, b)
 	}
-	return bookings, nil
+	if err := rows.Err(); err != nil {
+		return model.Page[model.Booking]{}, err
+	}
+	return newPage(bookings, page, func(b model.Booking) model.Cursor { return timeCursor(b.CreatedAt, b.ID) }), nil
 }
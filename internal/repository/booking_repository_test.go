@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDB membuka database dari TEST_DB_SOURCE dan menjalankan semua migrasi. Test dilewati jika
+// variabel tersebut kosong; jangan arahkan ke database yang berisi data penting.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DB_SOURCE")
+	if dsn == "" {
+		t.Skip("TEST_DB_SOURCE is not set")
+	}
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestBookingConfirmChecksMaintenanceWindow(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	day := func(offset int) time.Time { return today.AddDate(0, 0, offset) }
+
+	userID, vehicleID := uuid.New(), uuid.New()
+	if _, err := db.Exec(ctx, `INSERT INTO users (id, full_name, email, password_hash, phone_number, role)
+                               VALUES ($1, 'Vendor Test', $2, 'x', '+6281200000000', 'vendor')`, userID, userID.String()+"@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID) })
+	if _, err := db.Exec(ctx, `INSERT INTO vehicles (id, owner_id, brand, model, year, plate_number, vehicle_type, transmission, fuel,
+                                   is_for_rent, rental_price_daily, status, maintenance_start, maintenance_end)
+                               VALUES ($1, $2, 'Toyota', 'Avanza', 2022, $3, 'mobil', 'manual', 'bensin', TRUE, 300000, 'maintenance', $4, $5)`,
+		vehicleID, userID, "T "+vehicleID.String()[:8], day(5), day(7)); err != nil {
+		t.Fatal(err)
+	}
+
+	createBooking := func(start, end time.Time) uuid.UUID {
+		id := uuid.New()
+		if _, err := db.Exec(ctx, `INSERT INTO bookings (id, user_id, vehicle_id, start_date, end_date, total_price)
+                                   VALUES ($1, $2, $3, $4, $5, 300000)`, id, userID, vehicleID, start, end); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	repo := NewBookingRepository(db)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+		wantStatus string
+	}{
+		{"outside the window", day(1), day(2), true, "confirmed"},
+		{"overlapping the window", day(6), day(8), false, "pending_payment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := createBooking(tt.start, tt.end)
+			confirmed, err := repo.Confirm(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if confirmed != tt.want {
+				t.Errorf("Confirm() = %v, want %v", confirmed, tt.want)
+			}
+			booking, err := repo.FindBookingByID(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if booking.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", booking.Status, tt.wantStatus)
+			}
+		})
+	}
+
+	if confirmed, err := repo.Confirm(ctx, uuid.New()); err != nil || confirmed {
+		t.Errorf("Confirm(unknown booking) = %v, %v; want false, nil", confirmed, err)
+	}
+}
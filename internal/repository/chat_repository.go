@@ -13,8 +13,9 @@ type ChatRepository interface {
 	SaveMessage(ctx context.Context, msg model.Message) (model.Message, error)
 	FindOrCreateConversation(ctx context.Context, customerID, vendorID, vehicleID uuid.UUID) (model.Conversation, error)
 	FindConversationByID(ctx context.Context, conversationID uuid.UUID) (model.Conversation, error)
-	FindConversationsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Conversation, error)
-	FindMessagesByConversationID(ctx context.Context, conversationID uuid.UUID) ([]model.Message, error)
+	FindConversationsByUserID(ctx context.Context, userID uuid.UUID, page model.PageRequest) (model.Page[model.Conversation], error)
+	FindMessagesByConversationID(ctx context.Context, conversationID uuid.UUID, page model.PageRequest) (model.Page[model.Message], error)
+	FindMessagesByUserID(ctx context.Context, userID uuid.UUID) ([]model.Message, error)
 }
 
 type chatRepository struct {
@@ -65,29 +66,71 @@ func (r *chatRepository) FindConversationByID(ctx context.Context, conversationI
 	return convo, err
 }
 
-func (r *chatRepository) FindConversationsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Conversation, error) {
-	var conversations []model.Conversation
-	query := `SELECT id, customer_id, vendor_id, vehicle_id, created_at, updated_at FROM conversations WHERE customer_id = $1 OR vendor_id = $1 ORDER BY updated_at DESC`
-	rows, err := r.db.Query(ctx, query, userID)
+// FindConversationsByUserID mengambil percakapan seorang user, yang paling baru aktif lebih dulu
+func (r *chatRepository) FindConversationsByUserID(ctx context.Context, userID uuid.UUID, page model.PageRequest) (model.Page[model.Conversation], error) {
+	args := []interface{}{userID}
+	query := `SELECT id, customer_id, vendor_id, vehicle_id, created_at, updated_at FROM conversations WHERE (customer_id = $1 OR vendor_id = $1)`
+	if page.After != nil {
+		query += " AND " + beforeTime("updated_at", "id", page.After, &args)
+	}
+	query += " ORDER BY updated_at DESC, id DESC" + limitClause(page)
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return model.Page[model.Conversation]{}, err
 	}
 	defer rows.Close()
 
+	var conversations []model.Conversation
 	for rows.Next() {
 		var c model.Conversation
 		if err := rows.Scan(&c.ID, &c.CustomerID, &c.VendorID, &c.VehicleID, &c.CreatedAt, &c.UpdatedAt); err != nil {
-			return nil, err
+			return model.Page[model.Conversation]{}, err
 		}
 		conversations = append(conversations, c)
 	}
-	return conversations, nil
+	if err := rows.Err(); err != nil {
+		return model.Page[model.Conversation]{}, err
+	}
+	return newPage(conversations, page, func(c model.Conversation) model.Cursor { return timeCursor(c.UpdatedAt, c.ID) }), nil
 }
 
-func (r *chatRepository) FindMessagesByConversationID(ctx context.Context, conversationID uuid.UUID) ([]model.Message, error) {
+// FindMessagesByConversationID mengambil pesan sebuah percakapan, terbaru lebih dulu, sehingga
+// halaman berikutnya berisi pesan-pesan yang lebih lama
+func (r *chatRepository) FindMessagesByConversationID(ctx context.Context, conversationID uuid.UUID, page model.PageRequest) (model.Page[model.Message], error) {
+	args := []interface{}{conversationID}
+	query := `SELECT id, conversation_id, sender_id, recipient_id, content, is_read, created_at FROM messages WHERE conversation_id = $1`
+	if page.After != nil {
+		query += " AND " + beforeTime("created_at", "id", page.After, &args)
+	}
+	query += " ORDER BY created_at DESC, id DESC" + limitClause(page)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return model.Page[model.Message]{}, err
+	}
+	defer rows.Close()
+
+	var messages []model.Message
+	for rows.Next() {
+		var m model.Message
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.RecipientID, &m.Content, &m.IsRead, &m.CreatedAt); err != nil {
+			return model.Page[model.Message]{}, err
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return model.Page[model.Message]{}, err
+	}
+	return newPage(messages, page, func(m model.Message) model.Cursor { return timeCursor(m.CreatedAt, m.ID) }), nil
+}
+
+// FindMessagesByUserID mengambil semua pesan yang dikirim atau diterima seorang user
+func (r *chatRepository) FindMessagesByUserID(ctx context.Context, userID uuid.UUID) ([]model.Message, error) {
 	var messages []model.Message
-	query := `SELECT id, conversation_id, sender_id, recipient_id, content, is_read, created_at FROM messages WHERE conversation_id = $1 ORDER BY created_at ASC`
-	rows, err := r.db.Query(ctx, query, conversationID)
+	query := `SELECT id, conversation_id, sender_id, recipient_id, content, is_read, created_at FROM messages
+              WHERE sender_id = $1 OR recipient_id = $1 ORDER BY conversation_id, created_at ASC`
+	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -2,13 +2,20 @@ package repository
 
 import (
 	"context"
+	"sultra-otomotif-api/internal/model"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ImageRepository interface {
-	SaveVehicleImage(ctx context.Context, vehicleID uuid.UUID, imageURL string) error
+	SaveVehicleImage(ctx context.Context, image model.VehicleImage) (model.VehicleImage, error)
+	FindByID(ctx context.Context, id uuid.UUID) (model.VehicleImage, error)
+	FindByVehicleID(ctx context.Context, vehicleID uuid.UUID) ([]model.VehicleImage, error)
+	Delete(ctx context.Context, image model.VehicleImage) error
+	SetPrimary(ctx context.Context, vehicleID uuid.UUID, imageID uuid.UUID) error
+	Reorder(ctx context.Context, vehicleID uuid.UUID, imageIDs []uuid.UUID) (bool, error)
 }
 
 type imageRepository struct {
@@ -19,8 +26,173 @@ func NewImageRepository(db *pgxpool.Pool) ImageRepository {
 	return &imageRepository{db: db}
 }
 
-func (r *imageRepository) SaveVehicleImage(ctx context.Context, vehicleID uuid.UUID, imageURL string) error {
-	query := `INSERT INTO vehicle_images (id, vehicle_id, image_url, is_primary) VALUES (uuid_generate_v4(), $1, $2, false)` // is_primary akan kita handle nanti
-	_, err := r.db.Exec(ctx, query, vehicleID, imageURL)
+// lockVehicleImages mengunci baris kendaraan sehingga perubahan gambar utama pada kendaraan yang sama
+// berjalan bergantian dan setiap kendaraan tetap punya tepat satu gambar utama.
+func lockVehicleImages(ctx context.Context, tx pgx.Tx, vehicleID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `SELECT 1 FROM vehicles WHERE id = $1 FOR UPDATE`, vehicleID)
 	return err
 }
+
+// SaveVehicleImage menambahkan gambar di urutan terakhir. Gambar pertama sebuah kendaraan otomatis
+// menjadi gambar utama. ID dibuat otomatis jika kosong.
+func (r *imageRepository) SaveVehicleImage(ctx context.Context, image model.VehicleImage) (model.VehicleImage, error) {
+	if image.ID == uuid.Nil {
+		image.ID = uuid.New()
+	}
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := lockVehicleImages(ctx, tx, image.VehicleID); err != nil {
+			return err
+		}
+		query := `INSERT INTO vehicle_images (id, vehicle_id, image_url, storage_key, thumbnail_url, thumbnail_key, card_url, card_key, is_primary, sort_order)
+                  SELECT $1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''),
+                         COUNT(*) = 0, COALESCE(MAX(sort_order) + 1, 0)
+                  FROM vehicle_images WHERE vehicle_id = $2
+                  RETURNING is_primary, sort_order`
+		return tx.QueryRow(ctx, query, image.ID, image.VehicleID, image.ImageURL, image.StorageKey,
+			image.ThumbnailURL, image.ThumbnailKey, image.CardURL, image.CardKey).Scan(&image.IsPrimary, &image.SortOrder)
+	})
+	if image.ThumbnailURL == "" {
+		image.ThumbnailURL = image.ImageURL
+	}
+	if image.CardURL == "" {
+		image.CardURL = image.ImageURL
+	}
+	return image, err
+}
+
+// imageColumns dibaca oleh scanImage; rendition yang belum ada memakai image_url
+const imageColumns = `id, vehicle_id, image_url, is_primary, sort_order, COALESCE(storage_key, ''),
+              COALESCE(thumbnail_url, image_url), COALESCE(thumbnail_key, ''), COALESCE(card_url, image_url), COALESCE(card_key, '')`
+
+func scanImage(row pgx.Row, image *model.VehicleImage) error {
+	return row.Scan(&image.ID, &image.VehicleID, &image.ImageURL, &image.IsPrimary, &image.SortOrder, &image.StorageKey,
+		&image.ThumbnailURL, &image.ThumbnailKey, &image.CardURL, &image.CardKey)
+}
+
+func (r *imageRepository) FindByID(ctx context.Context, id uuid.UUID) (model.VehicleImage, error) {
+	var image model.VehicleImage
+	query := `SELECT ` + imageColumns + ` FROM vehicle_images WHERE id = $1`
+	err := scanImage(r.db.QueryRow(ctx, query, id), &image)
+	return image, err
+}
+
+// FindByVehicleID mengembalikan semua gambar kendaraan sesuai urutan tampil
+func (r *imageRepository) FindByVehicleID(ctx context.Context, vehicleID uuid.UUID) ([]model.VehicleImage, error) {
+	query := `SELECT ` + imageColumns + ` FROM vehicle_images WHERE vehicle_id = $1
+              ORDER BY sort_order, created_at`
+	rows, err := r.db.Query(ctx, query, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []model.VehicleImage
+	for rows.Next() {
+		var image model.VehicleImage
+		if err := scanImage(rows, &image); err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, rows.Err()
+}
+
+// Delete menghapus gambar. Jika yang dihapus adalah gambar utama, gambar berikutnya dalam urutan
+// menjadi gambar utama yang baru.
+func (r *imageRepository) Delete(ctx context.Context, image model.VehicleImage) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := lockVehicleImages(ctx, tx, image.VehicleID); err != nil {
+			return err
+		}
+		var wasPrimary bool
+		err := tx.QueryRow(ctx, `DELETE FROM vehicle_images WHERE id = $1 RETURNING is_primary`, image.ID).Scan(&wasPrimary)
+		if err != nil || !wasPrimary {
+			return err
+		}
+		query := `UPDATE vehicle_images SET is_primary = TRUE
+                  WHERE id = (SELECT id FROM vehicle_images WHERE vehicle_id = $1 ORDER BY sort_order, created_at LIMIT 1)`
+		_, err = tx.Exec(ctx, query, image.VehicleID)
+		return err
+	})
+}
+
+// SetPrimary menjadikan satu gambar sebagai gambar utama dan melepas status utama gambar lainnya
+func (r *imageRepository) SetPrimary(ctx context.Context, vehicleID uuid.UUID, imageID uuid.UUID) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := lockVehicleImages(ctx, tx, vehicleID); err != nil {
+			return err
+		}
+		// Dilepas dulu agar unique index gambar utama tidak bentrok di tengah statement
+		if _, err := tx.Exec(ctx, `UPDATE vehicle_images SET is_primary = FALSE WHERE vehicle_id = $1 AND is_primary`, vehicleID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `UPDATE vehicle_images SET is_primary = TRUE WHERE id = $1 AND vehicle_id = $2`, imageID, vehicleID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// Reorder menyimpan urutan baru; posisi gambar mengikuti indeksnya di imageIDs. Mengembalikan false
+// tanpa mengubah apa pun jika imageIDs tidak memuat setiap gambar kendaraan tepat sekali. Pengecekan
+// dan update berjalan di bawah kunci yang sama agar upload atau hapus gambar paralel tidak terlewat.
+func (r *imageRepository) Reorder(ctx context.Context, vehicleID uuid.UUID, imageIDs []uuid.UUID) (bool, error) {
+	matched := false
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := lockVehicleImages(ctx, tx, vehicleID); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, `SELECT id FROM vehicle_images WHERE vehicle_id = $1`, vehicleID)
+		if err != nil {
+			return err
+		}
+		var existing []uuid.UUID
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			existing = append(existing, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if !sameIDSet(existing, imageIDs) {
+			return nil
+		}
+
+		query := `UPDATE vehicle_images vi SET sort_order = o.position - 1
+                  FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+                  WHERE vi.id = o.id AND vi.vehicle_id = $1`
+		if _, err := tx.Exec(ctx, query, vehicleID, imageIDs); err != nil {
+			return err
+		}
+		matched = true
+		return nil
+	})
+	return matched, err
+}
+
+// sameIDSet memastikan ids memuat setiap anggota existing tepat sekali
+func sameIDSet(existing, ids []uuid.UUID) bool {
+	if len(existing) != len(ids) {
+		return false
+	}
+	remaining := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		remaining[id] = true
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
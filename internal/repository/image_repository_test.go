@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSameIDSet(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	existing := []uuid.UUID{a, b, c}
+	tests := []struct {
+		name string
+		ids  []uuid.UUID
+		want bool
+	}{
+		{"same order", []uuid.UUID{a, b, c}, true},
+		{"reordered", []uuid.UUID{c, a, b}, true},
+		{"missing image", []uuid.UUID{a, b}, false},
+		{"duplicate", []uuid.UUID{a, a, b}, false},
+		{"foreign image", []uuid.UUID{a, b, uuid.New()}, false},
+		{"extra image", []uuid.UUID{a, b, c, uuid.New()}, false},
+	}
+	for _, tt := range tests {
+		if got := sameIDSet(existing, tt.ids); got != tt.want {
+			t.Errorf("%s: sameIDSet() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"sultra-otomotif-api/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoginThrottleRepository menyimpan penghitung login gagal per akun dan per IP
+type LoginThrottleRepository interface {
+	FindBlocked(ctx context.Context, keys map[string]string) ([]model.LoginThrottle, error)
+	RecordFailure(ctx context.Context, scope, key string, window time.Duration) (int, error)
+	Block(ctx context.Context, scope, key string, until time.Time) error
+	Clear(ctx context.Context, scope, key string) (bool, error)
+	FindAllBlocked(ctx context.Context, page model.PageRequest) (model.Page[model.LoginThrottle], error)
+}
+
+type loginThrottleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginThrottleRepository(db *pgxpool.Pool) LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+// FindBlocked mengembalikan penghitung yang masih memblokir login untuk pasangan scope => key yang diberikan
+func (r *loginThrottleRepository) FindBlocked(ctx context.Context, keys map[string]string) ([]model.LoginThrottle, error) {
+	scopes := make([]string, 0, len(keys))
+	values := make([]string, 0, len(keys))
+	for scope, key := range keys {
+		scopes = append(scopes, scope)
+		values = append(values, key)
+	}
+
+	query := `SELECT t.scope, t.key, t.failed_attempts, t.last_failed_at, t.blocked_until
+              FROM login_throttles t
+              JOIN UNNEST($1::text[], $2::text[]) AS k(scope, key) ON t.scope = k.scope AND t.key = k.key
+              WHERE t.blocked_until > NOW()`
+	return r.query(ctx, query, scopes, values)
+}
+
+// RecordFailure menambah penghitung secara atomik dan mengembalikan jumlah gagal terbaru.
+// Penghitung dimulai ulang dari 1 jika kegagalan terakhir sudah lebih lama dari window.
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, scope, key string, window time.Duration) (int, error) {
+	var attempts int
+	query := `INSERT INTO login_throttles (scope, key, failed_attempts, last_failed_at)
+              VALUES ($1, $2, 1, NOW())
+              ON CONFLICT (scope, key) DO UPDATE SET
+                  failed_attempts = CASE
+                      WHEN login_throttles.last_failed_at < NOW() - $3::interval THEN 1
+                      ELSE login_throttles.failed_attempts + 1
+                  END,
+                  last_failed_at = NOW()
+              RETURNING failed_attempts`
+	err := r.db.QueryRow(ctx, query, scope, key, window).Scan(&attempts)
+	return attempts, err
+}
+
+func (r *loginThrottleRepository) Block(ctx context.Context, scope, key string, until time.Time) error {
+	query := `UPDATE login_throttles SET blocked_until = GREATEST(COALESCE(blocked_until, $3), $3) WHERE scope = $1 AND key = $2`
+	_, err := r.db.Exec(ctx, query, scope, key, until)
+	return err
+}
+
+// Clear menghapus penghitung. Mengembalikan false jika tidak ada yang dihapus.
+func (r *loginThrottleRepository) Clear(ctx context.Context, scope, key string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM login_throttles WHERE scope = $1 AND key = $2`, scope, key)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindAllBlocked mengambil akun & IP yang sedang diblokir, yang blokirnya paling lama lebih dulu.
+// Penghitung permintaan email (kunci ber-prefix aksi) tidak ikut ditampilkan.
+func (r *loginThrottleRepository) FindAllBlocked(ctx context.Context, page model.PageRequest) (model.Page[model.LoginThrottle], error) {
+	args := []interface{}{model.ThrottleActions}
+	query := `SELECT scope, key, failed_attempts, last_failed_at, blocked_until
+              FROM login_throttles
+              WHERE blocked_until > NOW() AND split_part(key, ':', 1) <> ALL($1::text[])`
+	if page.After != nil && page.After.Time != nil {
+		args = append(args, *page.After.Time, page.After.Scope, page.After.Key)
+		query += " AND (blocked_until, scope, key) < ($2, $3, $4)"
+	}
+	query += " ORDER BY blocked_until DESC, scope DESC, key DESC" + limitClause(page)
+
+	throttles, err := r.query(ctx, query, args...)
+	if err != nil {
+		return model.Page[model.LoginThrottle]{}, err
+	}
+	return newPage(throttles, page, func(t model.LoginThrottle) model.Cursor {
+		return model.Cursor{Time: t.BlockedUntil, Scope: t.Scope, Key: t.Key}
+	}), nil
+}
+
+func (r *loginThrottleRepository) query(ctx context.Context, query string, args ...interface{}) ([]model.LoginThrottle, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	throttles := []model.LoginThrottle{}
+	for rows.Next() {
+		var t model.LoginThrottle
+		if err := rows.Scan(&t.Scope, &t.Key, &t.FailedAttempts, &t.LastFailedAt, &t.BlockedUntil); err != nil {
+			return nil, err
+		}
+		throttles = append(throttles, t)
+	}
+	return throttles, rows.Err()
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestFindAllBlockedSkipsEmailRequestCounters(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewLoginThrottleRepository(db)
+	suffix := uuid.NewString()
+
+	keys := map[string]string{
+		"login account":      "budi-" + suffix + "@example.com",
+		"login ipv6":         "2001:db8::" + suffix[:4],
+		"password reset":     model.ThrottleActionPasswordReset + ":" + suffix,
+		"email verification": model.ThrottleActionEmailVerification + ":" + suffix,
+	}
+	for _, key := range keys {
+		if _, err := repo.RecordFailure(ctx, model.ThrottleScopeAccount, key, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Block(ctx, model.ThrottleScopeAccount, key, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { repo.Clear(context.Background(), model.ThrottleScopeAccount, key) })
+	}
+
+	listed := map[string]bool{}
+	page := model.PageRequest{Limit: 100}
+	for {
+		result, err := repo.FindAllBlocked(ctx, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, lock := range result.Items {
+			listed[lock.Key] = true
+		}
+		if !result.HasMore {
+			break
+		}
+		page.After = result.Next
+	}
+
+	for name, key := range keys {
+		want := name == "login account" || name == "login ipv6"
+		if listed[key] != want {
+			t.Errorf("%s listed = %v, want %v", name, listed[key], want)
+		}
+	}
+}
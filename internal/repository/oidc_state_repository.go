@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OIDCStateRepository menyimpan state login OIDC antara redirect ke penyedia dan callback
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state model.OIDCLoginState) error
+	Consume(ctx context.Context, stateHash, provider string) (model.OIDCLoginState, error)
+}
+
+type oidcStateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOIDCStateRepository(db *pgxpool.Pool) OIDCStateRepository {
+	return &oidcStateRepository{db: db}
+}
+
+// Create menyimpan state baru sekaligus membersihkan state yang sudah kedaluwarsa
+func (r *oidcStateRepository) Create(ctx context.Context, s model.OIDCLoginState) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM oidc_login_states WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	query := `INSERT INTO oidc_login_states (state_hash, provider, nonce, code_verifier, expires_at)
+              VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(ctx, query, s.StateHash, s.Provider, s.Nonce, s.CodeVerifier, s.ExpiresAt)
+	return err
+}
+
+// Consume mengambil dan menghapus state dalam satu query sehingga satu state hanya bisa dipakai sekali.
+// Mengembalikan pgx.ErrNoRows jika state tidak ada, untuk penyedia lain, atau sudah kedaluwarsa.
+func (r *oidcStateRepository) Consume(ctx context.Context, stateHash, provider string) (model.OIDCLoginState, error) {
+	var s model.OIDCLoginState
+	query := `DELETE FROM oidc_login_states
+              WHERE state_hash = $1 AND provider = $2 AND expires_at > NOW()
+              RETURNING state_hash, provider, nonce, code_verifier, expires_at`
+	err := r.db.QueryRow(ctx, query, stateHash, provider).Scan(&s.StateHash, &s.Provider, &s.Nonce, &s.CodeVerifier, &s.ExpiresAt)
+	return s, err
+}
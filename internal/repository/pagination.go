@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"fmt"
+	"sultra-otomotif-api/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// limitClause mengambil satu baris lebih dari limit untuk mengetahui apakah masih ada halaman berikutnya
+func limitClause(page model.PageRequest) string {
+	if page.Limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" LIMIT %d", page.Limit+1)
+}
+
+// newPage memotong hasil query (limit+1 baris) menjadi satu halaman beserta cursor item terakhirnya
+func newPage[T any](items []T, page model.PageRequest, cursorOf func(T) model.Cursor) model.Page[T] {
+	if page.Limit <= 0 || len(items) <= page.Limit {
+		return model.Page[T]{Items: items}
+	}
+	items = items[:page.Limit]
+	next := cursorOf(items[len(items)-1])
+	return model.Page[T]{Items: items, Next: &next, HasMore: true}
+}
+
+// timeCursor membuat cursor untuk list yang diurutkan berdasarkan (kolom waktu, id)
+func timeCursor(t time.Time, id uuid.UUID) model.Cursor {
+	return model.Cursor{Time: &t, ID: id}
+}
+
+// beforeTime menghasilkan kondisi keyset untuk urutan "kolom waktu DESC, id DESC",
+// yaitu baris-baris setelah cursor pada urutan tersebut
+func beforeTime(timeColumn, idColumn string, cursor *model.Cursor, args *[]interface{}) string {
+	var t time.Time
+	if cursor.Time != nil {
+		t = *cursor.Time
+	}
+	*args = append(*args, t, cursor.ID)
+	return fmt.Sprintf("(%s, %s) < ($%d, $%d)", timeColumn, idColumn, len(*args)-1, len(*args))
+}
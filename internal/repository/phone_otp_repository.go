@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sultra-otomotif-api/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PhoneOTPRepository menyimpan kode OTP verifikasi nomor telepon
+type PhoneOTPRepository interface {
+	CreateWithinLimit(ctx context.Context, otp model.PhoneOTP, limit model.OTPSendLimit) (bool, time.Duration, error)
+	FindActive(ctx context.Context, userID uuid.UUID) (model.PhoneOTP, error)
+	UseAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error)
+	Consume(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type phoneOTPRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPhoneOTPRepository(db *pgxpool.Pool) PhoneOTPRepository {
+	return &phoneOTPRepository{db: db}
+}
+
+// CreateWithinLimit menyimpan OTP baru dan membatalkan OTP lama milik user, kecuali jika pengiriman
+// ke user atau nomor tersebut masih dalam cooldown atau sudah mencapai batas per jam (mengembalikan
+// false beserta sisa waktu tunggu). Baris user dan nomor telepon dikunci selama transaksi agar
+// request paralel tidak bisa lolos di antara penghitungan dan penyimpanan.
+func (r *phoneOTPRepository) CreateWithinLimit(ctx context.Context, otp model.PhoneOTP, limit model.OTPSendLimit) (bool, time.Duration, error) {
+	created := false
+	var retryAfter time.Duration
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, otp.UserID); err != nil {
+			return err
+		}
+		// Nomor yang sama bisa dipakai user lain, jadi nomornya juga dikunci
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('phone_otp:' || $1))`, otp.PhoneNumber); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var count int
+		var latest *time.Time
+		query := `SELECT COUNT(*), MAX(created_at) FROM phone_otps
+                  WHERE (user_id = $1 OR phone_number = $2) AND created_at > $3`
+		if err := tx.QueryRow(ctx, query, otp.UserID, otp.PhoneNumber, now.Add(-time.Hour)).Scan(&count, &latest); err != nil {
+			return err
+		}
+		if latest != nil {
+			if wait := latest.Add(limit.Cooldown).Sub(now); wait > 0 {
+				retryAfter = wait
+				return nil
+			}
+		}
+		if count >= limit.MaxPerHour {
+			retryAfter = time.Hour
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE phone_otps SET consumed_at = NOW() WHERE user_id = $1 AND consumed_at IS NULL`, otp.UserID); err != nil {
+			return err
+		}
+		insert := `INSERT INTO phone_otps (id, user_id, phone_number, code_hash, expires_at) VALUES ($1, $2, $3, $4, $5)`
+		if _, err := tx.Exec(ctx, insert, otp.ID, otp.UserID, otp.PhoneNumber, otp.CodeHash, otp.ExpiresAt); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, retryAfter, err
+}
+
+// FindActive mengembalikan OTP terbaru yang belum dipakai dan belum kedaluwarsa, atau pgx.ErrNoRows
+func (r *phoneOTPRepository) FindActive(ctx context.Context, userID uuid.UUID) (model.PhoneOTP, error) {
+	var otp model.PhoneOTP
+	query := `SELECT id, user_id, phone_number, code_hash, attempts, expires_at, consumed_at, created_at
+              FROM phone_otps
+              WHERE user_id = $1 AND consumed_at IS NULL AND expires_at > NOW()
+              ORDER BY created_at DESC LIMIT 1`
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&otp.ID, &otp.UserID, &otp.PhoneNumber, &otp.CodeHash, &otp.Attempts, &otp.ExpiresAt, &otp.ConsumedAt, &otp.CreatedAt,
+	)
+	return otp, err
+}
+
+// UseAttempt mencatat satu tebakan untuk OTP. Mengembalikan false jika jatah tebakan sudah habis;
+// pengecekan dan penambahan dilakukan dalam satu UPDATE sehingga request paralel tidak bisa melewatinya.
+func (r *phoneOTPRepository) UseAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error) {
+	var attempts int
+	query := `UPDATE phone_otps SET attempts = attempts + 1
+              WHERE id = $1 AND attempts < $2
+              RETURNING attempts`
+	err := r.db.QueryRow(ctx, query, id, maxAttempts).Scan(&attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Consume menandai OTP terpakai. Mengembalikan false jika OTP sudah dipakai oleh request lain.
+func (r *phoneOTPRepository) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE phone_otps SET consumed_at = NOW() WHERE id = $1 AND consumed_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
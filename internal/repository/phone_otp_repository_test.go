@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateWithinLimitAllowsOneOfParallelSends(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewPhoneOTPRepository(db)
+
+	phone := "+62812" + uuid.NewString()[:7]
+	users := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, id := range users {
+		if _, err := db.Exec(ctx, `INSERT INTO users (id, full_name, email, password_hash, phone_number, role)
+                                   VALUES ($1, 'Customer Test', $2, 'x', $3, 'customer')`, id, id.String()+"@example.com", phone); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, id) })
+	}
+	limit := model.OTPSendLimit{Cooldown: time.Minute, MaxPerHour: 5}
+	newOTP := func(userID uuid.UUID) model.PhoneOTP {
+		return model.PhoneOTP{ID: uuid.New(), UserID: userID, PhoneNumber: phone, CodeHash: "hash", ExpiresAt: time.Now().Add(5 * time.Minute)}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, retryAfter, err := repo.CreateWithinLimit(ctx, newOTP(users[0]), limit)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !ok && retryAfter <= 0 {
+				t.Errorf("rejected send has retryAfter %v", retryAfter)
+			}
+			if ok {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if created != 1 {
+		t.Errorf("%d of 10 parallel sends created an OTP, want 1", created)
+	}
+
+	// Cooldown juga berlaku untuk user lain yang memakai nomor yang sama
+	if ok, _, err := repo.CreateWithinLimit(ctx, newOTP(users[1]), limit); err != nil || ok {
+		t.Errorf("send to the same number from another user = %v, %v, want rejected", ok, err)
+	}
+}
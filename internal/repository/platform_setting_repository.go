@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PlatformSettingRepository menyimpan pengaturan platform dalam bentuk key-value
+type PlatformSettingRepository interface {
+	GetAll(ctx context.Context) (map[string]string, error)
+	Set(ctx context.Context, key, value string, updatedBy uuid.UUID) error
+}
+
+type platformSettingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPlatformSettingRepository(db *pgxpool.Pool) PlatformSettingRepository {
+	return &platformSettingRepository{db: db}
+}
+
+func (r *platformSettingRepository) GetAll(ctx context.Context) (map[string]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT key, value FROM platform_settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		settings[key] = value
+	}
+	return settings, rows.Err()
+}
+
+func (r *platformSettingRepository) Set(ctx context.Context, key, value string, updatedBy uuid.UUID) error {
+	query := `INSERT INTO platform_settings (key, value, updated_by, updated_at) VALUES ($1, $2, $3, NOW())
+              ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW()`
+	_, err := r.db.Exec(ctx, query, key, value, updatedBy)
+	return err
+}
@@ -10,7 +10,8 @@ import (
 
 type ReviewRepository interface {
 	Create(ctx context.Context, review model.Review) (model.Review, error)
-	FindByVehicleID(ctx context.Context, vehicleID uuid.UUID) ([]model.Review, error)
+	FindByVehicleID(ctx context.Context, vehicleID uuid.UUID, page model.PageRequest) (model.Page[model.Review], error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.Review, error)
 }
 
 type reviewRepository struct{ db *pgxpool.Pool }
@@ -25,10 +26,40 @@ func (r *reviewRepository) Create(ctx context.Context, rev model.Review) (model.
 	return rev, err
 }
 
-func (r *reviewRepository) FindByVehicleID(ctx context.Context, vehicleID uuid.UUID) ([]model.Review, error) {
+// FindByVehicleID mengambil ulasan sebuah kendaraan, terbaru lebih dulu
+func (r *reviewRepository) FindByVehicleID(ctx context.Context, vehicleID uuid.UUID, page model.PageRequest) (model.Page[model.Review], error) {
+	args := []interface{}{vehicleID}
+	query := `SELECT id, booking_id, user_id, vehicle_id, rating, comment, created_at FROM reviews WHERE vehicle_id = $1`
+	if page.After != nil {
+		query += " AND " + beforeTime("created_at", "id", page.After, &args)
+	}
+	query += " ORDER BY created_at DESC, id DESC" + limitClause(page)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return model.Page[model.Review]{}, err
+	}
+	defer rows.Close()
+
+	var reviews []model.Review
+	for rows.Next() {
+		var rev model.Review
+		if err := rows.Scan(&rev.ID, &rev.BookingID, &rev.UserID, &rev.VehicleID, &rev.Rating, &rev.Comment, &rev.CreatedAt); err != nil {
+			return model.Page[model.Review]{}, err
+		}
+		reviews = append(reviews, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return model.Page[model.Review]{}, err
+	}
+	return newPage(reviews, page, func(rev model.Review) model.Cursor { return timeCursor(rev.CreatedAt, rev.ID) }), nil
+}
+
+// FindByUserID mengambil semua ulasan yang ditulis seorang user
+func (r *reviewRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.Review, error) {
 	var reviews []model.Review
-	query := `SELECT id, booking_id, user_id, vehicle_id, rating, comment, created_at FROM reviews WHERE vehicle_id = $1 ORDER BY created_at DESC`
-	rows, err := r.db.Query(ctx, query, vehicleID)
+	query := `SELECT id, booking_id, user_id, vehicle_id, rating, comment, created_at FROM reviews WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -12,8 +12,8 @@ type SalesRepository interface {
 	Create(ctx context.Context, transaction model.SalesTransaction) (model.SalesTransaction, error)
 	UpdateStatus(ctx context.Context, transactionID uuid.UUID, status string) (model.SalesTransaction, error)
 	FindByID(ctx context.Context, transactionID uuid.UUID) (model.SalesTransaction, error)
-	FindByBuyerID(ctx context.Context, buyerID uuid.UUID) ([]model.SalesTransaction, error)
-	FindBySellerID(ctx context.Context, sellerID uuid.UUID) ([]model.SalesTransaction, error)
+	FindByBuyerID(ctx context.Context, buyerID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error)
+	FindBySellerID(ctx context.Context, sellerID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error)
 }
 
 type salesRepository struct {
@@ -62,40 +62,39 @@ func (r *salesRepository) FindByID(ctx context.Context, transactionID uuid.UUID)
 	return t, err
 }
 
-func (r *salesRepository) FindByBuyerID(ctx context.Context, buyerID uuid.UUID) ([]model.SalesTransaction, error) {
-	var transactions []model.SalesTransaction
-	query := `SELECT id, vehicle_id, seller_id, buyer_id, agreed_price, status, created_at, updated_at FROM sales_transactions WHERE buyer_id = $1 ORDER BY created_at DESC`
-	rows, err := r.db.Query(ctx, query, buyerID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+func (r *salesRepository) FindByBuyerID(ctx context.Context, buyerID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error) {
+	return r.findByParty(ctx, "buyer_id", buyerID, page)
+}
 
-	for rows.Next() {
-		var t model.SalesTransaction
-		if err := rows.Scan(&t.ID, &t.VehicleID, &t.SellerID, &t.BuyerID, &t.AgreedPrice, &t.Status, &t.CreatedAt, &t.UpdatedAt); err != nil {
-			return nil, err
-		}
-		transactions = append(transactions, t)
-	}
-	return transactions, nil
+func (r *salesRepository) FindBySellerID(ctx context.Context, sellerID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error) {
+	return r.findByParty(ctx, "seller_id", sellerID, page)
 }
 
-func (r *salesRepository) FindBySellerID(ctx context.Context, sellerID uuid.UUID) ([]model.SalesTransaction, error) {
-	var transactions []model.SalesTransaction
-	query := `SELECT id, vehicle_id, seller_id, buyer_id, agreed_price, status, created_at, updated_at FROM sales_transactions WHERE seller_id = $1 ORDER BY created_at DESC`
-	rows, err := r.db.Query(ctx, query, sellerID)
+// findByParty mengambil transaksi milik pembeli atau penjual (column: buyer_id/seller_id), terbaru lebih dulu
+func (r *salesRepository) findByParty(ctx context.Context, column string, userID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error) {
+	args := []interface{}{userID}
+	query := `SELECT id, vehicle_id, seller_id, buyer_id, agreed_price, status, created_at, updated_at FROM sales_transactions WHERE ` + column + ` = $1`
+	if page.After != nil {
+		query += " AND " + beforeTime("created_at", "id", page.After, &args)
+	}
+	query += " ORDER BY created_at DESC, id DESC" + limitClause(page)
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return model.Page[model.SalesTransaction]{}, err
 	}
 	defer rows.Close()
 
+	var transactions []model.SalesTransaction
 	for rows.Next() {
 		var t model.SalesTransaction
 		if err := rows.Scan(&t.ID, &t.VehicleID, &t.SellerID, &t.BuyerID, &t.AgreedPrice, &t.Status, &t.CreatedAt, &t.UpdatedAt); err != nil {
-			return nil, err
+			return model.Page[model.SalesTransaction]{}, err
 		}
 		transactions = append(transactions, t)
 	}
-	return transactions, nil
+	if err := rows.Err(); err != nil {
+		return model.Page[model.SalesTransaction]{}, err
+	}
+	return newPage(transactions, page, func(t model.SalesTransaction) model.Cursor { return timeCursor(t.CreatedAt, t.ID) }), nil
 }
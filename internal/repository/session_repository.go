@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"sultra-otomotif-api/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SessionRepository menyimpan sesi login dan refresh token yang terikat padanya
+type SessionRepository interface {
+	Create(ctx context.Context, session model.Session) (model.Session, error)
+	FindByID(ctx context.Context, id uuid.UUID) (model.Session, error)
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]model.Session, error)
+	Extend(ctx context.Context, id uuid.UUID, expiresAt time.Time, meta model.RequestMeta) error
+	TouchLastSeen(ctx context.Context, id uuid.UUID, ipAddress string) error
+	Revoke(ctx context.Context, id uuid.UUID, reason string) error
+	RevokeForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID, reason string) (bool, error)
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID, reason string) error
+	RevokeOthersByUserID(ctx context.Context, userID uuid.UUID, keepSessionID uuid.UUID, reason string) error
+	CreateRefreshToken(ctx context.Context, token model.RefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, tokenHash string) (model.RefreshToken, error)
+	MarkRefreshTokenRotated(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type sessionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, s model.Session) (model.Session, error) {
+	query := `INSERT INTO sessions (id, user_id, expires_at, user_agent, ip_address)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING last_seen_at, created_at`
+	err := r.db.QueryRow(ctx, query, s.ID, s.UserID, s.ExpiresAt, s.UserAgent, s.IPAddress).Scan(&s.LastSeenAt, &s.CreatedAt)
+	return s, err
+}
+
+const sessionColumns = `id, user_id, expires_at, revoked_at, revoked_reason, user_agent, ip_address, last_seen_at, created_at`
+
+func scanSession(row pgx.Row, s *model.Session) error {
+	return row.Scan(&s.ID, &s.UserID, &s.ExpiresAt, &s.RevokedAt, &s.RevokedReason, &s.UserAgent, &s.IPAddress, &s.LastSeenAt, &s.CreatedAt)
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uuid.UUID) (model.Session, error) {
+	var s model.Session
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+	err := scanSession(r.db.QueryRow(ctx, query, id), &s)
+	return s, err
+}
+
+// FindActiveByUserID mengembalikan sesi yang belum dicabut dan belum kedaluwarsa, yang terakhir aktif lebih dulu
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions
+              WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+              ORDER BY last_seen_at DESC`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		var s model.Session
+		if err := scanSession(rows, &s); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// Extend memperpanjang masa berlaku sesi setiap kali refresh token dirotasi,
+// sekaligus mencatat perangkat & IP terakhir yang memakai sesi tersebut
+func (r *sessionRepository) Extend(ctx context.Context, id uuid.UUID, expiresAt time.Time, meta model.RequestMeta) error {
+	query := `UPDATE sessions SET expires_at = $1, user_agent = $2, ip_address = $3, last_seen_at = NOW()
+              WHERE id = $4 AND revoked_at IS NULL`
+	_, err := r.db.Exec(ctx, query, expiresAt, meta.UserAgent, meta.IPAddress, id)
+	return err
+}
+
+// TouchLastSeen memperbarui last_seen_at paling sering sekali per menit agar
+// tidak ada penulisan ke database di setiap request
+func (r *sessionRepository) TouchLastSeen(ctx context.Context, id uuid.UUID, ipAddress string) error {
+	query := `UPDATE sessions SET last_seen_at = NOW(), ip_address = $1
+              WHERE id = $2 AND last_seen_at < NOW() - INTERVAL '1 minute'`
+	_, err := r.db.Exec(ctx, query, ipAddress, id)
+	return err
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, reason string) error {
+	query := `UPDATE sessions SET revoked_at = NOW(), revoked_reason = $1 WHERE id = $2 AND revoked_at IS NULL`
+	_, err := r.db.Exec(ctx, query, reason, id)
+	return err
+}
+
+// RevokeForUser mencabut sesi hanya jika milik user tersebut. Mengembalikan false jika
+// sesi tidak ditemukan, milik user lain, atau sudah dicabut.
+func (r *sessionRepository) RevokeForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID, reason string) (bool, error) {
+	query := `UPDATE sessions SET revoked_at = NOW(), revoked_reason = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, reason, id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID, reason string) error {
+	query := `UPDATE sessions SET revoked_at = NOW(), revoked_reason = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	_, err := r.db.Exec(ctx, query, reason, userID)
+	return err
+}
+
+// RevokeOthersByUserID mencabut semua sesi user kecuali sesi yang sedang dipakai
+func (r *sessionRepository) RevokeOthersByUserID(ctx context.Context, userID uuid.UUID, keepSessionID uuid.UUID, reason string) error {
+	query := `UPDATE sessions SET revoked_at = NOW(), revoked_reason = $1 WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL`
+	_, err := r.db.Exec(ctx, query, reason, userID, keepSessionID)
+	return err
+}
+
+func (r *sessionRepository) CreateRefreshToken(ctx context.Context, t model.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, session_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)`
+	_, err := r.db.Exec(ctx, query, t.ID, t.SessionID, t.TokenHash, t.ExpiresAt)
+	return err
+}
+
+func (r *sessionRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	var t model.RefreshToken
+	query := `SELECT id, session_id, token_hash, expires_at, rotated_at, created_at FROM refresh_tokens WHERE token_hash = $1`
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(&t.ID, &t.SessionID, &t.TokenHash, &t.ExpiresAt, &t.RotatedAt, &t.CreatedAt)
+	return t, err
+}
+
+// MarkRefreshTokenRotated menandai token sudah dipakai. Mengembalikan false jika token
+// ternyata sudah dirotasi sebelumnya (misal dua request refresh yang balapan).
+func (r *sessionRepository) MarkRefreshTokenRotated(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE refresh_tokens SET rotated_at = NOW() WHERE id = $1 AND rotated_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
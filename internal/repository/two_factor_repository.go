@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"sultra-otomotif-api/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TwoFactorRepository menyimpan secret TOTP dan kode cadangan milik user
+type TwoFactorRepository interface {
+	FindByUserID(ctx context.Context, userID uuid.UUID) (model.TwoFactor, error)
+	UpsertPending(ctx context.Context, userID uuid.UUID, secretEncrypted string) error
+	Enable(ctx context.Context, userID uuid.UUID, step int64, backupCodeHashes []string) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	MarkStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ReplaceBackupCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	UseBackupCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountBackupCodes(ctx context.Context, userID uuid.UUID) (int, error)
+	ConsumeChallenge(ctx context.Context, jti, userID uuid.UUID, expiresAt time.Time) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTwoFactorRepository(db *pgxpool.Pool) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (model.TwoFactor, error) {
+	var tf model.TwoFactor
+	query := `SELECT user_id, secret_encrypted, enabled_at, last_used_step, created_at, updated_at
+              FROM user_two_factor WHERE user_id = $1`
+	err := r.db.QueryRow(ctx, query, userID).Scan(&tf.UserID, &tf.SecretEncrypted, &tf.EnabledAt, &tf.LastUsedStep, &tf.CreatedAt, &tf.UpdatedAt)
+	return tf, err
+}
+
+// UpsertPending menyimpan secret baru yang belum dikonfirmasi. Tidak menimpa 2FA yang sudah aktif.
+func (r *twoFactorRepository) UpsertPending(ctx context.Context, userID uuid.UUID, secretEncrypted string) error {
+	query := `INSERT INTO user_two_factor (user_id, secret_encrypted) VALUES ($1, $2)
+              ON CONFLICT (user_id) DO UPDATE
+              SET secret_encrypted = EXCLUDED.secret_encrypted, last_used_step = NULL, updated_at = NOW()
+              WHERE user_two_factor.enabled_at IS NULL`
+	_, err := r.db.Exec(ctx, query, userID, secretEncrypted)
+	return err
+}
+
+// Enable mengaktifkan 2FA dan menyimpan kode cadangan dalam satu transaksi
+func (r *twoFactorRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, backupCodeHashes []string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `UPDATE user_two_factor SET enabled_at = NOW(), last_used_step = $2, updated_at = NOW() WHERE user_id = $1`
+		if _, err := tx.Exec(ctx, query, userID, step); err != nil {
+			return err
+		}
+		return replaceBackupCodes(ctx, tx, userID, backupCodeHashes)
+	})
+}
+
+func (r *twoFactorRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM two_factor_backup_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM user_two_factor WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+// MarkStepUsed mencatat periode TOTP yang baru dipakai. Mengembalikan false jika periode itu
+// (atau yang lebih baru) sudah pernah dipakai, artinya kode sedang dipakai ulang.
+func (r *twoFactorRepository) MarkStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `UPDATE user_two_factor SET last_used_step = $2, updated_at = NOW()
+              WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)`
+	tag, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *twoFactorRepository) ReplaceBackupCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return replaceBackupCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+func (r *twoFactorRepository) UseBackupCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := `UPDATE two_factor_backup_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *twoFactorRepository) CountBackupCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM two_factor_backup_codes WHERE user_id = $1 AND used_at IS NULL`
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func replaceBackupCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM two_factor_backup_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	query := `INSERT INTO two_factor_backup_codes (user_id, code_hash) SELECT $1, UNNEST($2::text[])`
+	_, err := tx.Exec(ctx, query, userID, codeHashes)
+	return err
+}
+
+// ConsumeChallenge menandai token tantangan (berdasarkan jti) sebagai terpakai. Mengembalikan false
+// jika token itu sudah pernah dipakai. Catatan yang sudah kedaluwarsa ikut dibersihkan.
+func (r *twoFactorRepository) ConsumeChallenge(ctx context.Context, jti, userID uuid.UUID, expiresAt time.Time) (bool, error) {
+	var consumed bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM used_challenges WHERE expires_at < NOW()`); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `INSERT INTO used_challenges (jti, user_id, expires_at) VALUES ($1, $2, $3)
+              ON CONFLICT (jti) DO NOTHING`, jti, userID, expiresAt)
+		if err != nil {
+			return err
+		}
+		consumed = tag.RowsAffected() == 1
+		return nil
+	})
+	return consumed, err
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserIdentityRepository menyimpan akun penyedia login eksternal milik user
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity model.UserIdentity) (model.UserIdentity, error)
+	FindByProviderSubject(ctx context.Context, provider, subject string) (model.UserIdentity, error)
+	RecordLogin(ctx context.Context, identity model.UserIdentity) error
+}
+
+type userIdentityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserIdentityRepository(db *pgxpool.Pool) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, i model.UserIdentity) (model.UserIdentity, error) {
+	query := `INSERT INTO user_identities (id, user_id, provider, subject, email)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING last_login_at, created_at`
+	err := r.db.QueryRow(ctx, query, i.ID, i.UserID, i.Provider, i.Subject, i.Email).Scan(&i.LastLoginAt, &i.CreatedAt)
+	return i, err
+}
+
+func (r *userIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (model.UserIdentity, error) {
+	var i model.UserIdentity
+	query := `SELECT id, user_id, provider, subject, email, last_login_at, created_at
+              FROM user_identities WHERE provider = $1 AND subject = $2`
+	err := r.db.QueryRow(ctx, query, provider, subject).Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.LastLoginAt, &i.CreatedAt)
+	return i, err
+}
+
+// RecordLogin mencatat waktu login terakhir dan email terbaru dari penyedia
+func (r *userIdentityRepository) RecordLogin(ctx context.Context, i model.UserIdentity) error {
+	query := `UPDATE user_identities SET last_login_at = NOW(), email = $1 WHERE id = $2`
+	_, err := r.db.Exec(ctx, query, i.Email, i.ID)
+	return err
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"sultra-otomotif-api/internal/model"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -13,10 +15,14 @@ type UserRepository interface {
 	Save(ctx context.Context, user model.User) (model.User, error)
 	FindByEmail(ctx context.Context, email string) (model.User, error)
 	FindByID(ctx context.Context, id uuid.UUID) (model.User, error)
-	FindUsersByRole(ctx context.Context, role string) ([]model.User, error)
+	FindUsersByRole(ctx context.Context, role string, page model.PageRequest) (model.Page[model.User], error)
 	UpdateVerificationStatus(ctx context.Context, userID uuid.UUID, status bool) error
-	FindAll(ctx context.Context) ([]model.User, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	FindAll(ctx context.Context, page model.PageRequest) (model.Page[model.User], error)
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	UpdateProfile(ctx context.Context, user model.User) (model.User, error)
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) (bool, error)
+	MarkPhoneVerified(ctx context.Context, userID uuid.UUID, phoneNumber string) (bool, error)
+	Anonymize(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
 // userRepository adalah implementasi dari interface di atas
@@ -51,8 +57,9 @@ func (r *userRepository) Save(ctx context.Context, user model.User) (model.User,
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (model.User, error) {
 	var user model.User
-	query := `SELECT id, full_name, email, password_hash, phone_number, role, is_verified, verified_at, created_at, updated_at
-              FROM users WHERE email = $1`
+	query := `SELECT id, full_name, email, password_hash, phone_number, role, is_verified, verified_at, email_verified_at,
+                     phone_verified_at, require_verified_phone, deleted_at, created_at, updated_at
+              FROM users WHERE LOWER(email) = LOWER($1)`
 
 	err := r.db.QueryRow(ctx, query, email).Scan(
 		&user.ID,
@@ -63,6 +70,10 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (model.U
 		&user.Role,
 		&user.IsVerified,
 		&user.VerifiedAt,
+		&user.EmailVerifiedAt,
+		&user.PhoneVerifiedAt,
+		&user.RequireVerifiedPhone,
+		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -73,7 +84,8 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (model.U
 // FindByID mencari satu pengguna berdasarkan ID-nya.
 func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (model.User, error) {
 	var user model.User
-	query := `SELECT id, full_name, email, password_hash, phone_number, role, is_verified, verified_at, created_at, updated_at
+	query := `SELECT id, full_name, email, password_hash, phone_number, role, is_verified, verified_at, email_verified_at,
+                     phone_verified_at, require_verified_phone, deleted_at, created_at, updated_at
               FROM users WHERE id = $1`
 
 	err := r.db.QueryRow(ctx, query, id).Scan(
@@ -85,6 +97,10 @@ func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (model.User
 		&user.Role,
 		&user.IsVerified,
 		&user.VerifiedAt,
+		&user.EmailVerifiedAt,
+		&user.PhoneVerifiedAt,
+		&user.RequireVerifiedPhone,
+		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -92,37 +108,16 @@ func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (model.User
 	return user, err
 }
 
-// FindUsersByRole mencari semua pengguna dengan peran tertentu (misal: 'vendor').
-func (r *userRepository) FindUsersByRole(ctx context.Context, role string) ([]model.User, error) {
-	var users []model.User
+// FindUsersByRole mencari pengguna dengan peran tertentu (misal: 'vendor'), terbaru lebih dulu.
+func (r *userRepository) FindUsersByRole(ctx context.Context, role string, page model.PageRequest) (model.Page[model.User], error) {
+	args := []interface{}{role}
 	query := `SELECT id, full_name, email, phone_number, role, is_verified, verified_at, created_at
-              FROM users WHERE role = $1 ORDER BY created_at DESC`
-
-	rows, err := r.db.Query(ctx, query, role)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var user model.User
-		err := rows.Scan(
-			&user.ID,
-			&user.FullName,
-			&user.Email,
-			&user.PhoneNumber,
-			&user.Role,
-			&user.IsVerified,
-			&user.VerifiedAt,
-			&user.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, user)
+              FROM users WHERE role = $1`
+	if page.After != nil {
+		query += " AND " + beforeTime("created_at", "id", page.After, &args)
 	}
-
-	return users, nil
+	query += " ORDER BY created_at DESC, id DESC" + limitClause(page)
+	return r.queryListPage(ctx, page, query, args...)
 }
 
 // UpdateVerificationStatus mengubah status verifikasi seorang pengguna.
@@ -141,28 +136,135 @@ func (r *userRepository) UpdateVerificationStatus(ctx context.Context, userID uu
 	return err
 }
 
-func (r *userRepository) FindAll(ctx context.Context) ([]model.User, error) {
-	var users []model.User
-	query := `SELECT id, full_name, email, phone_number, role, is_verified, verified_at, created_at FROM users ORDER BY created_at DESC`
+func (r *userRepository) FindAll(ctx context.Context, page model.PageRequest) (model.Page[model.User], error) {
+	args := []interface{}{}
+	query := `SELECT id, full_name, email, phone_number, role, is_verified, verified_at, created_at FROM users`
+	if page.After != nil {
+		query += " WHERE " + beforeTime("created_at", "id", page.After, &args)
+	}
+	query += " ORDER BY created_at DESC, id DESC" + limitClause(page)
+	return r.queryListPage(ctx, page, query, args...)
+}
 
-	rows, err := r.db.Query(ctx, query)
+// queryListPage menjalankan query list user (kolom ringkas tanpa data sensitif) dan memotongnya per halaman
+func (r *userRepository) queryListPage(ctx context.Context, page model.PageRequest, query string, args ...interface{}) (model.Page[model.User], error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return model.Page[model.User]{}, err
 	}
 	defer rows.Close()
 
+	var users []model.User
 	for rows.Next() {
 		var user model.User
 		if err := rows.Scan(&user.ID, &user.FullName, &user.Email, &user.PhoneNumber, &user.Role, &user.IsVerified, &user.VerifiedAt, &user.CreatedAt); err != nil {
-			return nil, err
+			return model.Page[model.User]{}, err
 		}
 		users = append(users, user)
 	}
-	return users, nil
+	if err := rows.Err(); err != nil {
+		return model.Page[model.User]{}, err
+	}
+	return newPage(users, page, func(u model.User) model.Cursor { return timeCursor(u.CreatedAt, u.ID) }), nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
+func (r *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.Exec(ctx, query, passwordHash, userID)
 	return err
 }
+
+// UpdateProfile menyimpan data profil yang bisa diubah sendiri oleh user
+func (r *userRepository) UpdateProfile(ctx context.Context, user model.User) (model.User, error) {
+	query := `UPDATE users
+              SET full_name = $1, phone_number = $2, phone_verified_at = $3, email = $4, email_verified_at = $5,
+                  password_hash = $6, require_verified_phone = $7, updated_at = NOW()
+              WHERE id = $8
+              RETURNING updated_at`
+	err := r.db.QueryRow(ctx, query,
+		user.FullName,
+		user.PhoneNumber,
+		user.PhoneVerifiedAt,
+		user.Email,
+		user.EmailVerifiedAt,
+		user.PasswordHash,
+		user.RequireVerifiedPhone,
+		user.ID,
+	).Scan(&user.UpdatedAt)
+	return user, err
+}
+
+// MarkEmailVerified menandai email terverifikasi, tapi hanya jika email user masih sama dengan
+// email saat token diterbitkan. Mengembalikan false jika email sudah diganti sejak itu.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) (bool, error) {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+              WHERE id = $1 AND email = $2`
+	tag, err := r.db.Exec(ctx, query, userID, email)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// MarkPhoneVerified menandai nomor telepon terverifikasi jika nomor user masih sama dengan
+// nomor tujuan OTP. Mengembalikan false jika nomor sudah diganti sejak OTP dikirim.
+func (r *userRepository) MarkPhoneVerified(ctx context.Context, userID uuid.UUID, phoneNumber string) (bool, error) {
+	query := `UPDATE users SET phone_verified_at = NOW(), updated_at = NOW()
+              WHERE id = $1 AND phone_number = $2`
+	tag, err := r.db.Exec(ctx, query, userID, phoneNumber)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Anonymize menghapus data pribadi user tanpa menghapus barisnya, sehingga booking dan transaksi
+// penjualan yang mereferensikan user tetap ada. Kredensial, sesi dan token ikut dihapus, ulasan
+// dan pesan yang ditulis user dikosongkan, dan listing milik vendor ditarik dari pencarian.
+// Mengembalikan false jika user tidak ada atau sudah dihapus sebelumnya.
+func (r *userRepository) Anonymize(ctx context.Context, id uuid.UUID) (bool, error) {
+	anonymized := false
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var email string
+		query := `UPDATE users u
+                  SET full_name = 'Pengguna Terhapus', email = 'deleted-' || u.id || '@deleted.invalid',
+                      password_hash = '', phone_number = '', phone_verified_at = NULL, email_verified_at = NULL,
+                      require_verified_phone = FALSE, is_verified = FALSE, verified_at = NULL,
+                      deleted_at = NOW(), updated_at = NOW()
+                  FROM (SELECT id, email FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE) AS old
+                  WHERE u.id = old.id
+                  RETURNING old.email`
+		if err := tx.QueryRow(ctx, query, id).Scan(&email); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		cleanup := []string{
+			`DELETE FROM sessions WHERE user_id = $1`,
+			`DELETE FROM user_tokens WHERE user_id = $1`,
+			`DELETE FROM api_keys WHERE user_id = $1`,
+			`DELETE FROM user_identities WHERE user_id = $1`,
+			`DELETE FROM phone_otps WHERE user_id = $1`,
+			`DELETE FROM two_factor_backup_codes WHERE user_id = $1`,
+			`DELETE FROM user_two_factor WHERE user_id = $1`,
+			`UPDATE reviews SET comment = '' WHERE user_id = $1`,
+			`UPDATE messages SET content = '[pesan dihapus]' WHERE sender_id = $1`,
+			`UPDATE vehicles SET status = 'archived', maintenance_start = NULL, maintenance_end = NULL,
+                                 status_changed_at = NOW(), archived_at = NOW(), updated_at = NOW()
+             WHERE owner_id = $1 AND status IN ('draft', 'published', 'maintenance')`,
+		}
+		for _, q := range cleanup {
+			if _, err := tx.Exec(ctx, q, id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM login_throttles WHERE scope = 'account' AND key = LOWER($1)`, email); err != nil {
+			return err
+		}
+		anonymized = true
+		return nil
+	})
+	return anonymized, err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserTokenRepository menyimpan token sekali pakai untuk reset password & verifikasi email
+type UserTokenRepository interface {
+	Create(ctx context.Context, token model.UserToken) error
+	Consume(ctx context.Context, tokenHash string, purpose string) (model.UserToken, error)
+	InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error
+}
+
+type userTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserTokenRepository(db *pgxpool.Pool) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, t model.UserToken) error {
+	query := `INSERT INTO user_tokens (id, user_id, purpose, token_hash, email, expires_at)
+              VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(ctx, query, t.ID, t.UserID, t.Purpose, t.TokenHash, t.Email, t.ExpiresAt)
+	return err
+}
+
+// Consume menandai token terpakai dalam satu query, sehingga token yang sama tidak bisa
+// dipakai dua kali meskipun ada request yang balapan. Mengembalikan pgx.ErrNoRows jika
+// token tidak ada, sudah dipakai, atau sudah kedaluwarsa.
+func (r *userTokenRepository) Consume(ctx context.Context, tokenHash string, purpose string) (model.UserToken, error) {
+	var t model.UserToken
+	query := `UPDATE user_tokens SET consumed_at = NOW()
+              WHERE token_hash = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > NOW()
+              RETURNING id, user_id, purpose, token_hash, email, expires_at, consumed_at, created_at`
+	err := r.db.QueryRow(ctx, query, tokenHash, purpose).Scan(
+		&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.Email, &t.ExpiresAt, &t.ConsumedAt, &t.CreatedAt,
+	)
+	return t, err
+}
+
+// InvalidateAll membatalkan semua token aktif milik user untuk tujuan tertentu,
+// dipakai saat token baru diterbitkan atau setelah password berhasil direset.
+func (r *userTokenRepository) InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error {
+	query := `UPDATE user_tokens SET consumed_at = NOW() WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL`
+	_, err := r.db.Exec(ctx, query, userID, purpose)
+	return err
+}
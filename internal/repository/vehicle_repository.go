@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"sultra-otomotif-api/internal/model"
 
@@ -11,39 +12,74 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-const vehicleWithImagesQuery = `
+const vehicleSelectColumns = `
 	SELECT 
 		v.id, v.owner_id, v.brand, v.model, v.year, v.plate_number, v.color, 
-		v.vehicle_type, v.transmission, v.fuel, v.status, v.description, 
+		v.vehicle_type, v.transmission, v.fuel, v.status, v.status_changed_at, v.published_at,
+		v.archived_at, v.maintenance_start, v.maintenance_end, v.description, 
 		v.is_for_sale, v.sale_price, v.is_for_rent, v.rental_price_daily, 
 		v.rental_price_weekly, v.rental_price_monthly, v.location, v.features,
-		v.created_at, v.updated_at,
+		v.latitude, v.longitude, v.created_at, v.updated_at,
 		COALESCE(
-			(SELECT json_agg(json_build_object('id', vi.id, 'image_url', vi.image_url, 'is_primary', vi.is_primary))
+			(SELECT json_agg(json_build_object('id', vi.id, 'image_url', vi.image_url,
+			                                   'thumbnail_url', COALESCE(vi.thumbnail_url, vi.image_url),
+			                                   'card_url', COALESCE(vi.card_url, vi.image_url),
+			                                   'is_primary', vi.is_primary, 'sort_order', vi.sort_order)
+			                 ORDER BY vi.sort_order, vi.created_at)
 			 FROM vehicle_images vi WHERE vi.vehicle_id = v.id),
 			'[]'::json
-		) AS images
+		) AS images`
+
+const vehicleWithImagesQuery = vehicleSelectColumns + `
 	FROM vehicles v
 `
 
-func scanVehicle(row pgx.Row, v *model.Vehicle) error {
-	return row.Scan(
+// listedVehicleCondition memilih listing yang tampil di pencarian publik. Listing maintenance hanya
+// disembunyikan selama jendelanya berlangsung, sama seperti booking yang hanya ditolak di dalam jendela.
+const listedVehicleCondition = `(v.status = 'published' OR (v.status = 'maintenance' AND CURRENT_DATE NOT BETWEEN v.maintenance_start AND v.maintenance_end))`
+
+// vehicleSearchQuery adalah konfigurasi full-text search dari migrasi 0018
+const vehicleSearchQuery = "websearch_to_tsquery('vehicle_search', $%d)"
+
+// haversineDistance menghitung jarak lingkaran besar (km) dari titik asal ($lat, $lng) ke kendaraan
+const haversineDistance = `(6371 * 2 * asin(LEAST(1, sqrt(
+		power(sin(radians(v.latitude - $%[1]d) / 2), 2) +
+		cos(radians($%[1]d)) * cos(radians(v.latitude)) * power(sin(radians(v.longitude - $%[2]d) / 2), 2)))))`
+
+// kmPerDegree adalah panjang satu derajat lintang dalam km
+const kmPerDegree = 111.045
+
+// scanVehicle membaca satu baris hasil vehicleSelectColumns; extra untuk kolom tambahan setelah images
+func scanVehicle(row pgx.Row, v *model.Vehicle, extra ...interface{}) error {
+	dest := []interface{}{
 		&v.ID, &v.OwnerID, &v.Brand, &v.Model, &v.Year, &v.PlateNumber, &v.Color,
-		&v.VehicleType, &v.Transmission, &v.Fuel, &v.Status, &v.Description,
+		&v.VehicleType, &v.Transmission, &v.Fuel, &v.Status, &v.StatusChangedAt, &v.PublishedAt,
+		&v.ArchivedAt, &v.MaintenanceStart, &v.MaintenanceEnd, &v.Description,
 		&v.IsForSale, &v.SalePrice, &v.IsForRent, &v.RentalPriceDaily,
 		&v.RentalPriceWeekly, &v.RentalPriceMonthly, &v.Location, &v.Features,
-		&v.CreatedAt, &v.UpdatedAt, &v.Images,
-	)
+		&v.Latitude, &v.Longitude, &v.CreatedAt, &v.UpdatedAt, &v.Images,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// likeEscaper meloloskan karakter wildcard LIKE/ILIKE (escape default Postgres adalah backslash)
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern membuat pola ILIKE yang mencocokkan teks input secara harfiah di posisi mana pun
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
 }
 
 type VehicleRepository interface {
 	Create(ctx context.Context, vehicle model.Vehicle) (model.Vehicle, error)
-	FindAll(ctx context.Context, filter model.VehicleFilter) ([]model.Vehicle, error)
+	FindAll(ctx context.Context, filter model.VehicleFilter, page model.PageRequest) (model.Page[model.Vehicle], error)
 	FindByID(ctx context.Context, id uuid.UUID) (model.Vehicle, error)
 	Update(ctx context.Context, vehicle model.Vehicle) (model.Vehicle, error)
-	Delete(ctx context.Context, id uuid.UUID) error
-	FindAllAdmin(ctx context.Context) ([]model.Vehicle, error)
-	FindAllByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]model.Vehicle, error)
+	UpdateStatus(ctx context.Context, vehicle model.Vehicle, fromStatus string) (model.Vehicle, error)
+	ScheduleMaintenance(ctx context.Context, vehicle model.Vehicle, fromStatus string) (model.Vehicle, bool, error)
+	FindAllAdmin(ctx context.Context, page model.PageRequest) (model.Page[model.Vehicle], error)
+	FindAllByOwnerID(ctx context.Context, ownerID uuid.UUID, page model.PageRequest) (model.Page[model.Vehicle], error)
+	SuggestTerms(ctx context.Context, words []string) ([]string, error)
 }
 
 type vehicleRepository struct {
@@ -55,11 +91,12 @@ func NewVehicleRepository(db *pgxpool.Pool) VehicleRepository {
 }
 
 func (r *vehicleRepository) Create(ctx context.Context, v model.Vehicle) (model.Vehicle, error) {
-	query := `INSERT INTO vehicles (id, owner_id, brand, model, year, plate_number, color, vehicle_type, transmission, fuel, status, description, is_for_sale, sale_price, is_for_rent, rental_price_daily, rental_price_weekly, rental_price_monthly, location, features)
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
-              RETURNING created_at, updated_at`
+	query := `INSERT INTO vehicles (id, owner_id, brand, model, year, plate_number, color, vehicle_type, transmission, fuel, status, description, is_for_sale, sale_price, is_for_rent, rental_price_daily, rental_price_weekly, rental_price_monthly, location, features, latitude, longitude, maintenance_start, maintenance_end, published_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24,
+                      CASE WHEN $11 IN ('published', 'maintenance', 'sold') THEN NOW() END)
+              RETURNING created_at, updated_at, status_changed_at, published_at`
 
-	err := r.db.QueryRow(ctx, query, v.ID, v.OwnerID, v.Brand, v.Model, v.Year, v.PlateNumber, v.Color, v.VehicleType, v.Transmission, v.Fuel, v.Status, v.Description, v.IsForSale, v.SalePrice, v.IsForRent, v.RentalPriceDaily, v.RentalPriceWeekly, v.RentalPriceMonthly, v.Location, v.Features).Scan(&v.CreatedAt, &v.UpdatedAt)
+	err := r.db.QueryRow(ctx, query, v.ID, v.OwnerID, v.Brand, v.Model, v.Year, v.PlateNumber, v.Color, v.VehicleType, v.Transmission, v.Fuel, v.Status, v.Description, v.IsForSale, v.SalePrice, v.IsForRent, v.RentalPriceDaily, v.RentalPriceWeekly, v.RentalPriceMonthly, v.Location, v.Features, v.Latitude, v.Longitude, v.MaintenanceStart, v.MaintenanceEnd).Scan(&v.CreatedAt, &v.UpdatedAt, &v.StatusChangedAt, &v.PublishedAt)
 
 	if err != nil {
 		return model.Vehicle{}, err
@@ -67,61 +104,245 @@ func (r *vehicleRepository) Create(ctx context.Context, v model.Vehicle) (model.
 	return v, nil
 }
 
-func (r *vehicleRepository) FindAll(ctx context.Context, filter model.VehicleFilter) ([]model.Vehicle, error) {
-	var vehicles []model.Vehicle
-
-	conditions := []string{"v.status = 'available'"}
+func (r *vehicleRepository) FindAll(ctx context.Context, filter model.VehicleFilter, page model.PageRequest) (model.Page[model.Vehicle], error) {
+	// Pencarian publik hanya menampilkan listing yang sudah dipublikasikan
+	conditions := []string{listedVehicleCondition}
 	args := []interface{}{}
-	argID := 1
+	// where menambahkan kondisi dengan satu parameter; "?" diganti nomor placeholder berikutnya
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.Replace(condition, "?", fmt.Sprintf("$%d", len(args)), 1))
+	}
 
 	if filter.Type != "" {
-		conditions = append(conditions, fmt.Sprintf("v.vehicle_type = $%d", argID))
-		args = append(args, filter.Type)
-		argID++
+		where("v.vehicle_type = ?", filter.Type)
 	}
 	if filter.Brand != "" {
-		conditions = append(conditions, fmt.Sprintf("v.brand ILIKE $%d", argID))
-		args = append(args, "%"+filter.Brand+"%")
-		argID++
+		where("v.brand ILIKE ?", containsPattern(filter.Brand))
+	}
+	if filter.Model != "" {
+		where("v.model ILIKE ?", containsPattern(filter.Model))
 	}
 	if filter.Transmission != "" {
-		conditions = append(conditions, fmt.Sprintf("v.transmission = $%d", argID))
-		args = append(args, filter.Transmission)
-		argID++
+		where("v.transmission = ?", filter.Transmission)
+	}
+	if filter.Fuel != "" {
+		where("v.fuel = ?", filter.Fuel)
+	}
+	if filter.Color != "" {
+		where("v.color ILIKE ?", containsPattern(filter.Color))
+	}
+	if filter.Location != "" {
+		where("v.location ILIKE ?", containsPattern(filter.Location))
+	}
+	if filter.MinYear > 0 {
+		where("v.year >= ?", filter.MinYear)
+	}
+	if filter.MaxYear > 0 {
+		where("v.year <= ?", filter.MaxYear)
+	}
+	if filter.IsForSale {
+		conditions = append(conditions, "v.is_for_sale = TRUE")
+	}
+	if filter.IsForRent {
+		conditions = append(conditions, "v.is_for_rent = TRUE")
+	}
+	if filter.HasDateRange() {
+		// Semantik tumpang tindih sama dengan BookingRepository.IsVehicleAvailable
+		args = append(args, filter.StartDate, filter.EndDate)
+		conditions = append(conditions,
+			"v.is_for_rent = TRUE AND v.rental_price_daily IS NOT NULL",
+			"NOT EXISTS (SELECT 1 FROM bookings b WHERE b.vehicle_id = v.id AND "+
+				fmt.Sprintf(bookingOverlapCondition, len(args)-1, len(args))+")")
 	}
-	// ... tambahkan logika filter lain di sini jika perlu ...
 
-	finalQuery := vehicleWithImagesQuery
-	if len(conditions) > 0 {
-		finalQuery += " WHERE " + strings.Join(conditions, " AND ")
+	priceColumn := "v." + filter.PriceColumn()
+	if filter.MinPrice > 0 {
+		where(priceColumn+" >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		where(priceColumn+" <= ?", filter.MaxPrice)
+	}
+	if filter.MinSalePrice > 0 {
+		where("v.is_for_sale AND v.sale_price >= ?", filter.MinSalePrice)
+	}
+	if filter.MaxSalePrice > 0 {
+		where("v.is_for_sale AND v.sale_price <= ?", filter.MaxSalePrice)
+	}
+	if filter.MinRentalPrice > 0 {
+		where("v.is_for_rent AND v.rental_price_daily >= ?", filter.MinRentalPrice)
+	}
+	if filter.MaxRentalPrice > 0 {
+		where("v.is_for_rent AND v.rental_price_daily <= ?", filter.MaxRentalPrice)
+	}
+	if filter.MinLat != nil && filter.MaxLat != nil && filter.MinLng != nil && filter.MaxLng != nil {
+		where("v.latitude >= ?", *filter.MinLat)
+		where("v.latitude <= ?", *filter.MaxLat)
+		where("v.longitude >= ?", *filter.MinLng)
+		where("v.longitude <= ?", *filter.MaxLng)
 	}
 
-	orderBy := " ORDER BY v.created_at DESC"
-	switch filter.Sort {
-	case "price_asc":
-		orderBy = " ORDER BY v.sale_price ASC NULLS LAST"
-	case "price_desc":
-		orderBy = " ORDER BY v.sale_price DESC NULLS LAST"
-	case "year_desc":
-		orderBy = " ORDER BY v.year DESC"
+	// Jarak (km) hanya dihitung jika titik asal lat/lng diisi; urutan jarak tanpa titik asal diabaikan
+	distanceColumn := "NULL::float8"
+	sort := filter.EffectiveSort()
+	if filter.HasOrigin() {
+		args = append(args, *filter.Lat, *filter.Lng)
+		distanceColumn = fmt.Sprintf(haversineDistance, len(args)-1, len(args))
+		if filter.RadiusKm > 0 {
+			where(distanceColumn+" <= ?", filter.RadiusKm)
+			// Saring kandidat dengan kotak di sekitar lingkaran radius agar indeks koordinat terpakai
+			latDelta := filter.RadiusKm / kmPerDegree
+			where("v.latitude >= ?", *filter.Lat-latDelta)
+			where("v.latitude <= ?", *filter.Lat+latDelta)
+			if cosLat := math.Cos(*filter.Lat * math.Pi / 180); cosLat > 0.01 {
+				lngDelta := filter.RadiusKm / (kmPerDegree * cosLat)
+				where("v.longitude >= ?", *filter.Lng-lngDelta)
+				where("v.longitude <= ?", *filter.Lng+lngDelta)
+			}
+		}
 	}
-	finalQuery += orderBy
 
+	// Kata kunci dicocokkan dengan search_vector; tanpa kata kunci kolom rank bernilai 0.
+	// Urutan relevansi hanya berlaku jika ada kata kunci, dan menjadi urutan bawaannya.
+	rankColumn := "0::float8"
+	if q := filter.SearchQuery(); q != "" {
+		args = append(args, q)
+		tsQuery := fmt.Sprintf(vehicleSearchQuery, len(args))
+		conditions = append(conditions, "v.search_vector @@ "+tsQuery)
+		rankColumn = fmt.Sprintf("ts_rank_cd(v.search_vector, %s)::float8", tsQuery)
+	}
+
+	// Urutan harga mengikuti jenis listing yang dicari: harga sewa harian jika is_for_rent diisi.
+	// id selalu ikut diurutkan agar posisi cursor unik.
+	orderBy := " ORDER BY v.created_at DESC, v.id DESC"
+	cursorOf := func(v model.Vehicle) model.Cursor { return timeCursor(v.CreatedAt, v.ID) }
+	after := func(c *model.Cursor) string { return beforeTime("v.created_at", "v.id", c, &args) }
+	switch sort {
+	case "relevance":
+		orderBy = fmt.Sprintf(" ORDER BY %s DESC, v.id DESC", rankColumn)
+		cursorOf = func(v model.Vehicle) model.Cursor {
+			rank := v.SearchRank
+			return model.Cursor{Value: &rank, ID: v.ID}
+		}
+		after = func(c *model.Cursor) string {
+			var rank float64
+			if c.Value != nil {
+				rank = *c.Value
+			}
+			args = append(args, rank, c.ID)
+			return fmt.Sprintf("(%s, v.id) < ($%d, $%d)", rankColumn, len(args)-1, len(args))
+		}
+	case "distance":
+		conditions = append(conditions, "v.latitude IS NOT NULL")
+		orderBy = fmt.Sprintf(" ORDER BY %s ASC, v.id ASC", distanceColumn)
+		cursorOf = func(v model.Vehicle) model.Cursor { return model.Cursor{Value: v.DistanceKm, ID: v.ID} }
+		after = func(c *model.Cursor) string {
+			var distance float64
+			if c.Value != nil {
+				distance = *c.Value
+			}
+			args = append(args, distance, c.ID)
+			return fmt.Sprintf("(%s, v.id) > ($%d, $%d)", distanceColumn, len(args)-1, len(args))
+		}
+	case "price_asc", "price_desc":
+		op, dir := ">", "ASC"
+		if sort == "price_desc" {
+			op, dir = "<", "DESC"
+		}
+		orderBy = fmt.Sprintf(" ORDER BY %s %s NULLS LAST, v.id %s", priceColumn, dir, dir)
+		cursorOf = func(v model.Vehicle) model.Cursor {
+			price := v.SalePrice
+			if filter.PriceColumn() == "rental_price_daily" {
+				price = v.RentalPriceDaily
+			}
+			return model.Cursor{Value: price, ID: v.ID}
+		}
+		// Kendaraan tanpa harga (NULL) selalu berada di akhir urutan
+		after = func(c *model.Cursor) string {
+			if c.Value == nil {
+				args = append(args, c.ID)
+				return fmt.Sprintf("(%s IS NULL AND v.id %s $%d)", priceColumn, op, len(args))
+			}
+			args = append(args, *c.Value, c.ID)
+			return fmt.Sprintf("(%[1]s %[2]s $%[3]d OR (%[1]s = $%[3]d AND v.id %[2]s $%[4]d) OR %[1]s IS NULL)", priceColumn, op, len(args)-1, len(args))
+		}
+	case "year_desc", "year_asc":
+		op, dir := "<", "DESC"
+		if sort == "year_asc" {
+			op, dir = ">", "ASC"
+		}
+		orderBy = fmt.Sprintf(" ORDER BY v.year %s, v.id %s", dir, dir)
+		cursorOf = func(v model.Vehicle) model.Cursor {
+			year := float64(v.Year)
+			return model.Cursor{Value: &year, ID: v.ID}
+		}
+		after = func(c *model.Cursor) string {
+			var year int
+			if c.Value != nil {
+				year = int(*c.Value)
+			}
+			args = append(args, year, c.ID)
+			return fmt.Sprintf("(v.year, v.id) %s ($%d, $%d)", op, len(args)-1, len(args))
+		}
+	}
+	if page.After != nil {
+		conditions = append(conditions, after(page.After))
+	}
+
+	finalQuery := vehicleSelectColumns + ", " + rankColumn + " AS rank, " + distanceColumn + " AS distance_km FROM vehicles v WHERE " +
+		strings.Join(conditions, " AND ") + orderBy + limitClause(page)
 	rows, err := r.db.Query(ctx, finalQuery, args...)
 	if err != nil {
-		return nil, err
+		return model.Page[model.Vehicle]{}, err
 	}
 	defer rows.Close()
 
+	var vehicles []model.Vehicle
 	for rows.Next() {
 		var v model.Vehicle
-		if err := scanVehicle(rows, &v); err != nil {
-			return nil, err
+		if err := scanVehicle(rows, &v, &v.SearchRank, &v.DistanceKm); err != nil {
+			return model.Page[model.Vehicle]{}, err
 		}
 		vehicles = append(vehicles, v)
 	}
+	if err := rows.Err(); err != nil {
+		return model.Page[model.Vehicle]{}, err
+	}
+	sortKey := filter.CursorKey()
+	return newPage(vehicles, page, func(v model.Vehicle) model.Cursor {
+		cursor := cursorOf(v)
+		cursor.Sort = sortKey
+		return cursor
+	}), nil
+}
 
-	return vehicles, nil
+// SuggestTerms mencari padanan terdekat setiap kata dari merek/model kendaraan yang tersedia
+// berdasarkan kemiripan trigram. Kata tanpa padanan dikembalikan apa adanya.
+func (r *vehicleRepository) SuggestTerms(ctx context.Context, words []string) ([]string, error) {
+	query := `SELECT COALESCE((
+                  SELECT t.term
+                  FROM vehicles v, regexp_split_to_table(lower(v.brand || ' ' || v.model), '\s+') AS t(term)
+                  WHERE ` + listedVehicleCondition + ` AND (w.word <% v.brand OR w.word <% v.model)
+                  ORDER BY similarity(t.term, w.word) DESC, t.term
+                  LIMIT 1
+              ), w.word)
+              FROM unnest($1::text[]) WITH ORDINALITY AS w(word, pos)
+              ORDER BY w.pos`
+	rows, err := r.db.Query(ctx, query, words)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []string
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, term)
+	}
+	return suggestions, rows.Err()
 }
 
 func (r *vehicleRepository) FindByID(ctx context.Context, id uuid.UUID) (model.Vehicle, error) {
@@ -135,11 +356,12 @@ func (r *vehicleRepository) FindByID(ctx context.Context, id uuid.UUID) (model.V
 	return v, nil
 }
 
+// Update menyimpan data listing. Status tidak ikut diubah; perubahan status lewat UpdateStatus.
 func (r *vehicleRepository) Update(ctx context.Context, v model.Vehicle) (model.Vehicle, error) {
-	query := `UPDATE vehicles SET brand=$1, model=$2, year=$3, plate_number=$4, color=$5, vehicle_type=$6, transmission=$7, fuel=$8, status=$9, description=$10, is_for_sale=$11, sale_price=$12, is_for_rent=$13, rental_price_daily=$14, rental_price_weekly=$15, rental_price_monthly=$16, location=$17, features=$18, updated_at=NOW()
-              WHERE id=$19 RETURNING updated_at`
+	query := `UPDATE vehicles SET brand=$1, model=$2, year=$3, plate_number=$4, color=$5, vehicle_type=$6, transmission=$7, fuel=$8, description=$9, is_for_sale=$10, sale_price=$11, is_for_rent=$12, rental_price_daily=$13, rental_price_weekly=$14, rental_price_monthly=$15, location=$16, features=$17, latitude=$18, longitude=$19, updated_at=NOW()
+              WHERE id=$20 RETURNING updated_at`
 
-	err := r.db.QueryRow(ctx, query, v.Brand, v.Model, v.Year, v.PlateNumber, v.Color, v.VehicleType, v.Transmission, v.Fuel, v.Status, v.Description, v.IsForSale, v.SalePrice, v.IsForRent, v.RentalPriceDaily, v.RentalPriceWeekly, v.RentalPriceMonthly, v.Location, v.Features, v.ID).Scan(&v.UpdatedAt)
+	err := r.db.QueryRow(ctx, query, v.Brand, v.Model, v.Year, v.PlateNumber, v.Color, v.VehicleType, v.Transmission, v.Fuel, v.Description, v.IsForSale, v.SalePrice, v.IsForRent, v.RentalPriceDaily, v.RentalPriceWeekly, v.RentalPriceMonthly, v.Location, v.Features, v.Latitude, v.Longitude, v.ID).Scan(&v.UpdatedAt)
 
 	if err != nil {
 		return model.Vehicle{}, err
@@ -147,42 +369,92 @@ func (r *vehicleRepository) Update(ctx context.Context, v model.Vehicle) (model.
 	return v, nil
 }
 
-func (r *vehicleRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM vehicles WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
-	return err
+// UpdateStatus memindahkan listing dari fromStatus ke vehicle.Status beserta jendela maintenance-nya dan
+// mencatat waktu perubahannya. published_at hanya diisi saat pertama kali dipublikasikan. Mengembalikan
+// pgx.ErrNoRows jika status listing sudah bukan fromStatus (diubah oleh request lain).
+func (r *vehicleRepository) UpdateStatus(ctx context.Context, v model.Vehicle, fromStatus string) (model.Vehicle, error) {
+	err := r.db.QueryRow(ctx, vehicleStatusUpdateQuery, v.ID, v.Status, v.MaintenanceStart, v.MaintenanceEnd, fromStatus).
+		Scan(&v.StatusChangedAt, &v.PublishedAt, &v.ArchivedAt, &v.UpdatedAt)
+	if err != nil {
+		return model.Vehicle{}, err
+	}
+	return v, nil
 }
 
-func (r *vehicleRepository) FindAllAdmin(ctx context.Context) ([]model.Vehicle, error) {
-	var vehicles []model.Vehicle
-	query := vehicleWithImagesQuery + " ORDER BY v.created_at DESC"
-
-	rows, err := r.db.Query(ctx, query)
+// ScheduleMaintenance seperti UpdateStatus, tapi mengembalikan false tanpa mengubah apa pun jika jendela
+// maintenance bertabrakan dengan booking yang menunggu pembayaran atau aktif. Baris kendaraan dikunci
+// selama pengecekan, sehingga konfirmasi pembayaran booking kendaraan yang sama menunggu.
+func (r *vehicleRepository) ScheduleMaintenance(ctx context.Context, v model.Vehicle, fromStatus string) (model.Vehicle, bool, error) {
+	scheduled := false
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM vehicles WHERE id = $1 FOR UPDATE`, v.ID); err != nil {
+			return err
+		}
+		var booked bool
+		query := `SELECT EXISTS (SELECT 1 FROM bookings
+                  WHERE vehicle_id = $1 AND status IN ('pending_payment', 'confirmed', 'rented_out')
+                  AND start_date <= $3 AND end_date >= $2)`
+		if err := tx.QueryRow(ctx, query, v.ID, v.MaintenanceStart, v.MaintenanceEnd).Scan(&booked); err != nil {
+			return err
+		}
+		if booked {
+			return nil
+		}
+		scheduled = true
+		return tx.QueryRow(ctx, vehicleStatusUpdateQuery, v.ID, v.Status, v.MaintenanceStart, v.MaintenanceEnd, fromStatus).
+			Scan(&v.StatusChangedAt, &v.PublishedAt, &v.ArchivedAt, &v.UpdatedAt)
+	})
 	if err != nil {
-		return nil, err
+		return model.Vehicle{}, false, err
 	}
-	defer rows.Close()
+	return v, scheduled, nil
+}
 
-	for rows.Next() {
-		var v model.Vehicle
-		if err := scanVehicle(rows, &v); err != nil {
-			return nil, err
-		}
-		vehicles = append(vehicles, v)
+const vehicleStatusUpdateQuery = `UPDATE vehicles
+              SET status = $2, maintenance_start = $3, maintenance_end = $4, status_changed_at = NOW(),
+                  published_at = CASE WHEN $2 IN ('published', 'maintenance', 'sold') THEN COALESCE(published_at, NOW()) ELSE published_at END,
+                  archived_at = CASE WHEN $2 = 'archived' THEN NOW() END,
+                  updated_at = NOW()
+              WHERE id = $1 AND status = $5
+              RETURNING status_changed_at, published_at, archived_at, updated_at`
+
+func (r *vehicleRepository) FindAllAdmin(ctx context.Context, page model.PageRequest) (model.Page[model.Vehicle], error) {
+	args := []interface{}{}
+	query := vehicleWithImagesQuery
+	if page.After != nil {
+		query += " WHERE " + beforeTime("v.created_at", "v.id", page.After, &args)
 	}
-	return vehicles, nil
+	query += " ORDER BY v.created_at DESC, v.id DESC" + limitClause(page)
+	return r.queryVehiclePage(ctx, page, query, args...)
 }
 
-func (r *vehicleRepository) FindAllByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]model.Vehicle, error) {
-	var vehicles []model.Vehicle
-	query := vehicleWithImagesQuery + " WHERE v.owner_id = $1 ORDER BY v.created_at DESC"
+func (r *vehicleRepository) FindAllByOwnerID(ctx context.Context, ownerID uuid.UUID, page model.PageRequest) (model.Page[model.Vehicle], error) {
+	args := []interface{}{ownerID}
+	query := vehicleWithImagesQuery + " WHERE v.owner_id = $1"
+	if page.After != nil {
+		query += " AND " + beforeTime("v.created_at", "v.id", page.After, &args)
+	}
+	query += " ORDER BY v.created_at DESC, v.id DESC" + limitClause(page)
+	return r.queryVehiclePage(ctx, page, query, args...)
+}
+
+// queryVehiclePage menjalankan query list kendaraan yang diurutkan dari yang terbaru dan memotongnya per halaman
+func (r *vehicleRepository) queryVehiclePage(ctx context.Context, page model.PageRequest, query string, args ...interface{}) (model.Page[model.Vehicle], error) {
+	vehicles, err := r.queryVehicles(ctx, query, args...)
+	if err != nil {
+		return model.Page[model.Vehicle]{}, err
+	}
+	return newPage(vehicles, page, func(v model.Vehicle) model.Cursor { return timeCursor(v.CreatedAt, v.ID) }), nil
+}
 
-	rows, err := r.db.Query(ctx, query, ownerID)
+func (r *vehicleRepository) queryVehicles(ctx context.Context, query string, args ...interface{}) ([]model.Vehicle, error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var vehicles []model.Vehicle
 	for rows.Next() {
 		var v model.Vehicle
 		if err := scanVehicle(rows, &v); err != nil {
@@ -190,6 +462,5 @@ func (r *vehicleRepository) FindAllByOwnerID(ctx context.Context, ownerID uuid.U
 		}
 		vehicles = append(vehicles, v)
 	}
-
-	return vehicles, nil
+	return vehicles, rows.Err()
 }
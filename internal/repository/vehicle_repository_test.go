@@ -0,0 +1,19 @@
+package repository
+
+import "testing"
+
+func TestContainsPattern(t *testing.T) {
+	cases := map[string]string{
+		"avanza":   "%avanza%",
+		"100%":     `%100\%%`,
+		"b_1":      `%b\_1%`,
+		`C:\mobil`: `%C:\\mobil%`,
+		"":         "%%",
+		"%_":       `%\%\_%`,
+	}
+	for input, want := range cases {
+		if got := containsPattern(input); got != want {
+			t.Errorf("containsPattern(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
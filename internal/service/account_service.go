@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrAdminSelfDelete     = apperror.Forbidden("admin_self_delete", "admin accounts cannot be deleted through self-service")
+	ErrAccountHasOpenDeals = apperror.Conflict("account_has_open_transactions", "finish or cancel your active bookings and pending sales before deleting your account")
+)
+
+// Status booking & penjualan yang masih berjalan dan menahan penghapusan akun
+var (
+	openBookingStatuses = map[string]bool{"pending_payment": true, "confirmed": true, "rented_out": true}
+	openSaleStatuses    = map[string]bool{"payment_pending": true}
+)
+
+// AccountService menangani hak user atas datanya sendiri: ekspor data pribadi dan penghapusan akun
+type AccountService interface {
+	ExportData(ctx context.Context, userID uuid.UUID) (model.AccountExport, error)
+	DeleteAccount(ctx context.Context, userID uuid.UUID, input model.DeleteAccountInput) error
+}
+
+type accountService struct {
+	userRepo    repository.UserRepository
+	bookingRepo repository.BookingRepository
+	salesRepo   repository.SalesRepository
+	vehicleRepo repository.VehicleRepository
+	reviewRepo  repository.ReviewRepository
+	chatRepo    repository.ChatRepository
+}
+
+func NewAccountService(userRepo repository.UserRepository, bookingRepo repository.BookingRepository, salesRepo repository.SalesRepository, vehicleRepo repository.VehicleRepository, reviewRepo repository.ReviewRepository, chatRepo repository.ChatRepository) AccountService {
+	return &accountService{
+		userRepo:    userRepo,
+		bookingRepo: bookingRepo,
+		salesRepo:   salesRepo,
+		vehicleRepo: vehicleRepo,
+		reviewRepo:  reviewRepo,
+		chatRepo:    chatRepo,
+	}
+}
+
+func (s *accountService) ExportData(ctx context.Context, userID uuid.UUID) (model.AccountExport, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return model.AccountExport{}, err
+	}
+
+	// Ekspor selalu berisi semua data, tanpa pagination
+	all := model.PageRequest{}
+	bookings, err := s.bookingRepo.FindBookingsByUserID(ctx, userID, all)
+	if err != nil {
+		return model.AccountExport{}, err
+	}
+	purchases, err := s.salesRepo.FindByBuyerID(ctx, userID, all)
+	if err != nil {
+		return model.AccountExport{}, err
+	}
+	sales, err := s.salesRepo.FindBySellerID(ctx, userID, all)
+	if err != nil {
+		return model.AccountExport{}, err
+	}
+	vehicles, err := s.vehicleRepo.FindAllByOwnerID(ctx, userID, all)
+	if err != nil {
+		return model.AccountExport{}, err
+	}
+	conversations, err := s.chatRepo.FindConversationsByUserID(ctx, userID, all)
+	if err != nil {
+		return model.AccountExport{}, err
+	}
+	reviews, err := s.reviewRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return model.AccountExport{}, err
+	}
+	messages, err := s.chatRepo.FindMessagesByUserID(ctx, userID)
+	if err != nil {
+		return model.AccountExport{}, err
+	}
+
+	return model.AccountExport{
+		ExportedAt:    time.Now(),
+		Profile:       user,
+		Bookings:      bookings.Items,
+		Purchases:     purchases.Items,
+		Sales:         sales.Items,
+		Vehicles:      vehicles.Items,
+		Reviews:       reviews,
+		Conversations: conversations.Items,
+		Messages:      messages,
+	}, nil
+}
+
+// DeleteAccount menganonimkan akun user sendiri. Booking dan transaksi penjualan tetap disimpan untuk
+// pembukuan, tapi tidak lagi terhubung ke data pribadi. Semua sesi ikut dihapus sehingga user langsung logout.
+func (s *accountService) DeleteAccount(ctx context.Context, userID uuid.UUID, input model.DeleteAccountInput) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Role == "admin" {
+		return ErrAdminSelfDelete
+	}
+	if user.PasswordHash != "" && !helper.CheckPasswordHash(input.CurrentPassword, user.PasswordHash) {
+		return ErrCurrentPasswordInvalid
+	}
+
+	open, err := s.hasOpenTransactions(ctx, user)
+	if err != nil {
+		return err
+	}
+	if open {
+		return ErrAccountHasOpenDeals
+	}
+
+	anonymized, err := s.userRepo.Anonymize(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !anonymized {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// hasOpenTransactions memeriksa booking & penjualan yang masih berjalan, baik sebagai pembeli/penyewa
+// maupun sebagai vendor pemilik kendaraan
+func (s *accountService) hasOpenTransactions(ctx context.Context, user model.User) (bool, error) {
+	all := model.PageRequest{}
+	page, err := s.bookingRepo.FindBookingsByUserID(ctx, user.ID, all)
+	if err != nil {
+		return false, err
+	}
+	bookings := page.Items
+	if user.Role == "vendor" {
+		owned, err := s.bookingRepo.FindBookingsByOwnerID(ctx, user.ID, all)
+		if err != nil {
+			return false, err
+		}
+		bookings = append(bookings, owned.Items...)
+	}
+	for _, b := range bookings {
+		if openBookingStatuses[b.Status] {
+			return true, nil
+		}
+	}
+
+	bought, err := s.salesRepo.FindByBuyerID(ctx, user.ID, all)
+	if err != nil {
+		return false, err
+	}
+	sold, err := s.salesRepo.FindBySellerID(ctx, user.ID, all)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range append(bought.Items, sold.Items...) {
+		if openSaleStatuses[t.Status] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *accountService) findUser(ctx context.Context, userID uuid.UUID) (model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.User{}, ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+	if user.DeletedAt != nil {
+		return model.User{}, ErrUserNotFound
+	}
+	return user, nil
+}
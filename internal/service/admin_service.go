@@ -3,75 +3,153 @@ package service
 import (
 	"context"
 	"errors"
+	"sultra-otomotif-api/internal/apperror"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrVendorNotFound = apperror.NotFound("vendor_not_found", "vendor not found")
+	ErrNotAVendor     = apperror.Validation("not_a_vendor", "this user is not a vendor")
 )
 
 type AdminService interface {
-	GetVendors(ctx context.Context) ([]model.User, error)
+	GetVendors(ctx context.Context, page model.PageRequest) (model.Page[model.User], error)
 	VerifyVendor(ctx context.Context, vendorID uuid.UUID) (model.User, error)
-	GetAllUsers(ctx context.Context) ([]model.User, error)
+	UnverifyVendor(ctx context.Context, vendorID uuid.UUID) (model.User, error)
+	GetAllUsers(ctx context.Context, page model.PageRequest) (model.Page[model.User], error)
 	DeleteUser(ctx context.Context, userID uuid.UUID) error
-	GetAllVehicles(ctx context.Context) ([]model.Vehicle, error)
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	GetAllVehicles(ctx context.Context, page model.PageRequest) (model.Page[model.Vehicle], error)
 	DeleteVehicle(ctx context.Context, vehicleID uuid.UUID) error
+	GetLoginLocks(ctx context.Context, page model.PageRequest) (model.Page[model.LoginThrottle], error)
+	ClearLoginLock(ctx context.Context, scope, key string) error
+	GetSettings(ctx context.Context) (model.PlatformSettings, error)
+	UpdateSettings(ctx context.Context, input model.UpdatePlatformSettingsInput, adminID uuid.UUID) (model.PlatformSettings, error)
 }
 
 type adminService struct {
 	userRepo    repository.UserRepository
 	vehicleRepo repository.VehicleRepository
+	authService AuthService
+	throttle    LoginThrottleService
+	settings    SettingsService
 }
 
-func NewAdminService(userRepo repository.UserRepository, vehicleRepo repository.VehicleRepository) AdminService {
-	return &adminService{userRepo: userRepo, vehicleRepo: vehicleRepo}
+func NewAdminService(userRepo repository.UserRepository, vehicleRepo repository.VehicleRepository, authService AuthService, throttle LoginThrottleService, settings SettingsService) AdminService {
+	return &adminService{userRepo: userRepo, vehicleRepo: vehicleRepo, authService: authService, throttle: throttle, settings: settings}
 }
 
-func (s *adminService) GetVendors(ctx context.Context) ([]model.User, error) {
-	return s.userRepo.FindUsersByRole(ctx, "vendor")
+func (s *adminService) GetVendors(ctx context.Context, page model.PageRequest) (model.Page[model.User], error) {
+	return s.userRepo.FindUsersByRole(ctx, "vendor", page)
 }
 
 func (s *adminService) VerifyVendor(ctx context.Context, vendorID uuid.UUID) (model.User, error) {
+	return s.setVendorVerification(ctx, vendorID, true)
+}
+
+// UnverifyVendor mencabut verifikasi vendor dan memaksa semua sesinya login ulang
+func (s *adminService) UnverifyVendor(ctx context.Context, vendorID uuid.UUID) (model.User, error) {
+	vendor, err := s.setVendorVerification(ctx, vendorID, false)
+	if err != nil {
+		return model.User{}, err
+	}
+	if err := s.authService.RevokeUserSessions(ctx, vendorID, RevokeReasonVendorRevoked); err != nil {
+		return model.User{}, err
+	}
+	return vendor, nil
+}
+
+func (s *adminService) setVendorVerification(ctx context.Context, vendorID uuid.UUID, verified bool) (model.User, error) {
 	vendor, err := s.userRepo.FindByID(ctx, vendorID)
 	if err != nil {
-		return model.User{}, errors.New("vendor not found")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.User{}, ErrVendorNotFound
+		}
+		return model.User{}, err
 	}
 
 	if vendor.Role != "vendor" {
-		return model.User{}, errors.New("this user is not a vendor")
+		return model.User{}, ErrNotAVendor
 	}
 
-	if vendor.IsVerified {
+	if vendor.IsVerified == verified {
 		return vendor, nil
 	}
 
-	err = s.userRepo.UpdateVerificationStatus(ctx, vendorID, true)
+	err = s.userRepo.UpdateVerificationStatus(ctx, vendorID, verified)
 	if err != nil {
 		return model.User{}, err
 	}
 
 	updatedVendor, err := s.userRepo.FindByID(ctx, vendorID)
 	if err != nil {
-		return model.User{}, errors.New("failed to fetch updated vendor data")
+		return model.User{}, apperror.Internal("vendor_refresh_failed", "failed to fetch updated vendor data", err)
 	}
 
 	return updatedVendor, nil
 }
 
-func (s *adminService) GetAllUsers(ctx context.Context) ([]model.User, error) {
-	return s.userRepo.FindAll(ctx)
+func (s *adminService) GetAllUsers(ctx context.Context, page model.PageRequest) (model.Page[model.User], error) {
+	return s.userRepo.FindAll(ctx, page)
 }
 
+// DeleteUser menganonimkan akun alih-alih menghapus barisnya, agar booking dan transaksi penjualan
+// user tersebut tidak ikut terhapus. Sesi user dihapus sehingga token yang masih beredar langsung ditolak.
 func (s *adminService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
-	// Di sini bisa ditambahkan logika tambahan, misal logging siapa yang menghapus
-	return s.userRepo.Delete(ctx, userID)
+	anonymized, err := s.userRepo.Anonymize(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !anonymized {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions mengeluarkan user dari semua perangkat, misal saat akunnya diduga dibobol
+func (s *adminService) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return s.authService.RevokeUserSessions(ctx, userID, RevokeReasonAdminRevoked)
 }
 
-func (s *adminService) GetAllVehicles(ctx context.Context) ([]model.Vehicle, error) {
-	return s.vehicleRepo.FindAllAdmin(ctx)
+func (s *adminService) GetAllVehicles(ctx context.Context, page model.PageRequest) (model.Page[model.Vehicle], error) {
+	return s.vehicleRepo.FindAllAdmin(ctx, page)
 }
 
-// FUNGSI BARU:
+// DeleteVehicle mengarsipkan listing, sama seperti penghapusan oleh vendor
 func (s *adminService) DeleteVehicle(ctx context.Context, vehicleID uuid.UUID) error {
-	return s.vehicleRepo.Delete(ctx, vehicleID)
+	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrVehicleNotFound
+		}
+		return err
+	}
+	return archiveVehicle(ctx, s.vehicleRepo, vehicle)
+}
+
+// GetLoginLocks menampilkan akun & IP yang sedang diblokir karena terlalu sering gagal login
+func (s *adminService) GetLoginLocks(ctx context.Context, page model.PageRequest) (model.Page[model.LoginThrottle], error) {
+	return s.throttle.ListLocks(ctx, page)
+}
+
+func (s *adminService) ClearLoginLock(ctx context.Context, scope, key string) error {
+	return s.throttle.ClearLock(ctx, scope, key)
+}
+
+func (s *adminService) GetSettings(ctx context.Context) (model.PlatformSettings, error) {
+	return s.settings.GetSettings(ctx)
+}
+
+func (s *adminService) UpdateSettings(ctx context.Context, input model.UpdatePlatformSettingsInput, adminID uuid.UUID) (model.PlatformSettings, error) {
+	return s.settings.UpdateSettings(ctx, input, adminID)
 }
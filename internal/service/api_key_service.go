@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/auth"
+	"sultra-otomotif-api/internal/authz"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAPIKeyNotFound      = apperror.NotFound("api_key_not_found", "API key not found")
+	ErrInvalidAPIKeyScope  = apperror.Validation("invalid_api_key_scope", "one or more scopes cannot be granted to an API key")
+	ErrInvalidAPIKeyExpiry = apperror.Validation("invalid_api_key_expiry", "expires_at must be in the future")
+)
+
+const (
+	// apiKeyPrefix memudahkan secret scanner (misal GitHub) mengenali key yang bocor
+	apiKeyPrefix = "sok_"
+	// apiKeyDisplayLength adalah jumlah karakter awal key yang disimpan untuk ditampilkan
+	apiKeyDisplayLength = 12
+)
+
+// APIKeyService mengelola API key milik user. Validasi key saat request dilakukan oleh AuthService.
+type APIKeyService interface {
+	CreateKey(ctx context.Context, subject authz.Subject, input model.CreateAPIKeyInput) (model.CreatedAPIKey, error)
+	ListKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error
+}
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, subject authz.Subject, input model.CreateAPIKeyInput) (model.CreatedAPIKey, error) {
+	// Scope hanya boleh berisi permission yang dimiliki role pembuatnya dan memang boleh didelegasikan
+	scopes := make([]string, 0, len(input.Scopes))
+	seen := map[string]bool{}
+	for _, scope := range input.Scopes {
+		perm := authz.Permission(scope)
+		if !authz.IsAPIKeyScope(perm) || !subject.Can(perm) {
+			return model.CreatedAPIKey{}, ErrInvalidAPIKeyScope.WithField("scopes", "scope "+scope+" is not allowed")
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return model.CreatedAPIKey{}, ErrInvalidAPIKeyExpiry
+	}
+
+	secret, _, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+	rawKey := apiKeyPrefix + secret
+
+	created, err := s.repo.Create(ctx, model.APIKey{
+		ID:        uuid.New(),
+		UserID:    subject.UserID,
+		Name:      input.Name,
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		KeyHash:   auth.HashToken(rawKey),
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return model.CreatedAPIKey{}, err
+	}
+	return model.CreatedAPIKey{APIKey: created, Key: rawKey}, nil
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error {
+	revoked, err := s.repo.Revoke(ctx, keyID, userID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/auth"
+	"sultra-otomotif-api/internal/authz"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvalidToken        = apperror.Unauthorized("invalid_token", "invalid or expired token")
+	ErrSessionRevoked      = apperror.Unauthorized("session_revoked", "session has been revoked or expired")
+	ErrInvalidRefreshToken = apperror.Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+	ErrRefreshTokenReused  = apperror.Unauthorized("refresh_token_reused", "refresh token has already been used; all tokens for this session were revoked")
+	ErrInvalidChallenge    = apperror.Unauthorized("invalid_challenge_token", "invalid or expired challenge token, please login again")
+	ErrInvalidAPIKey       = apperror.Unauthorized("invalid_api_key", "invalid, expired or revoked API key")
+	ErrSessionNotFound     = apperror.NotFound("session_not_found", "session not found")
+	ErrAPIKeyOwnerInactive = apperror.Forbidden("api_key_owner_inactive", "the account owning this API key can no longer use API keys")
+)
+
+// Tujuan token tantangan login
+const (
+	ChallengePurposeMFAVerify = "mfa_verify"
+	ChallengePurposeMFAEnroll = "mfa_enroll"
+
+	challengeTokenTTL = 5 * time.Minute
+)
+
+// Alasan pencabutan sesi yang disimpan di kolom sessions.revoked_reason
+const (
+	RevokeReasonLogout         = "logout"
+	RevokeReasonTokenReuse     = "refresh_token_reuse"
+	RevokeReasonVendorRevoked  = "vendor_unverified"
+	RevokeReasonPasswordReset  = "password_reset"
+	RevokeReasonPasswordChange = "password_changed"
+	RevokeReasonRemoteSignOut  = "remote_sign_out"
+	RevokeReasonAdminRevoked   = "admin_revoked"
+)
+
+// AuthService menerbitkan access token berumur pendek dan refresh token yang dirotasi,
+// serta memvalidasi bahwa sesi di balik sebuah access token belum dicabut.
+type AuthService interface {
+	IssueTokens(ctx context.Context, user model.User, meta model.RequestMeta) (model.AuthResponse, error)
+	RefreshTokens(ctx context.Context, refreshToken string, meta model.RequestMeta) (model.AuthResponse, error)
+	Logout(ctx context.Context, sessionID uuid.UUID) error
+	ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]model.Session, error)
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID, reason string) error
+	RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID, reason string) error
+	AuthenticateAccessToken(ctx context.Context, token string, meta model.RequestMeta) (*auth.Claims, error)
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (model.APIKey, authz.Subject, error)
+	IssueChallenge(user model.User, purpose string) (string, time.Time, error)
+	ParseChallenge(token string, purpose string) (*auth.ChallengeClaims, error)
+}
+
+type authService struct {
+	sessionRepo     repository.SessionRepository
+	userRepo        repository.UserRepository
+	apiKeyRepo      repository.APIKeyRepository
+	keys            *auth.KeySet
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+func NewAuthService(sessionRepo repository.SessionRepository, userRepo repository.UserRepository, apiKeyRepo repository.APIKeyRepository, keys *auth.KeySet, accessTokenTTL, refreshTokenTTL time.Duration) AuthService {
+	return &authService{
+		sessionRepo:     sessionRepo,
+		userRepo:        userRepo,
+		apiKeyRepo:      apiKeyRepo,
+		keys:            keys,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+func (s *authService) IssueTokens(ctx context.Context, user model.User, meta model.RequestMeta) (model.AuthResponse, error) {
+	session, err := s.sessionRepo.Create(ctx, model.Session{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+		UserAgent: meta.UserAgent,
+		IPAddress: meta.IPAddress,
+	})
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+	return s.issueForSession(ctx, user, session.ID)
+}
+
+func (s *authService) RefreshTokens(ctx context.Context, refreshToken string, meta model.RequestMeta) (model.AuthResponse, error) {
+	stored, err := s.sessionRepo.FindRefreshTokenByHash(ctx, auth.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.AuthResponse{}, ErrInvalidRefreshToken
+		}
+		return model.AuthResponse{}, err
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, stored.SessionID)
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+	if !session.IsActive(time.Now()) {
+		return model.AuthResponse{}, ErrSessionRevoked
+	}
+
+	// Refresh token yang sudah pernah dirotasi dipakai lagi: kemungkinan besar dicuri.
+	// Cabut seluruh sesi agar pemilik token asli maupun pencuri harus login ulang.
+	if stored.RotatedAt != nil {
+		return model.AuthResponse{}, s.revokeForReuse(ctx, session)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return model.AuthResponse{}, ErrInvalidRefreshToken
+	}
+
+	rotated, err := s.sessionRepo.MarkRefreshTokenRotated(ctx, stored.ID)
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+	if !rotated {
+		return model.AuthResponse{}, s.revokeForReuse(ctx, session)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.AuthResponse{}, ErrSessionRevoked
+		}
+		return model.AuthResponse{}, err
+	}
+
+	if err := s.sessionRepo.Extend(ctx, session.ID, time.Now().Add(s.refreshTokenTTL), meta); err != nil {
+		return model.AuthResponse{}, err
+	}
+	return s.issueForSession(ctx, user, session.ID)
+}
+
+func (s *authService) Logout(ctx context.Context, sessionID uuid.UUID) error {
+	return s.sessionRepo.Revoke(ctx, sessionID, RevokeReasonLogout)
+}
+
+// ListSessions menampilkan sesi aktif milik user, dengan sesi yang sedang dipakai ditandai current
+func (s *authService) ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]model.Session, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession mengeluarkan satu perangkat milik user, misal ponsel yang hilang
+func (s *authService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	revoked, err := s.sessionRepo.RevokeForUser(ctx, sessionID, userID, RevokeReasonRemoteSignOut)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *authService) RevokeUserSessions(ctx context.Context, userID uuid.UUID, reason string) error {
+	return s.sessionRepo.RevokeAllByUserID(ctx, userID, reason)
+}
+
+func (s *authService) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID, reason string) error {
+	return s.sessionRepo.RevokeOthersByUserID(ctx, userID, currentSessionID, reason)
+}
+
+func (s *authService) AuthenticateAccessToken(ctx context.Context, token string, meta model.RequestMeta) (*auth.Claims, error) {
+	claims, err := auth.ParseToken(token, s.keys)
+	if err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+	if session.UserID != claims.UserID || !session.IsActive(time.Now()) {
+		return nil, ErrSessionRevoked
+	}
+	// Gagal mencatat last seen tidak boleh membuat request yang sah ditolak
+	if err := s.sessionRepo.TouchLastSeen(ctx, session.ID, meta.IPAddress); err != nil {
+		log.Printf("Warning: failed to update last seen for session %s: %v", session.ID, err)
+	}
+	return claims, nil
+}
+
+// AuthenticateAPIKey memvalidasi header X-API-Key dan mengembalikan identitas pemiliknya,
+// dibatasi oleh scope key tersebut.
+func (s *authService) AuthenticateAPIKey(ctx context.Context, rawKey string) (model.APIKey, authz.Subject, error) {
+	key, err := s.apiKeyRepo.FindByHash(ctx, auth.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.APIKey{}, authz.Subject{}, ErrInvalidAPIKey
+		}
+		return model.APIKey{}, authz.Subject{}, err
+	}
+	if !key.IsActive(time.Now()) {
+		return model.APIKey{}, authz.Subject{}, ErrInvalidAPIKey
+	}
+
+	owner, err := s.userRepo.FindByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.APIKey{}, authz.Subject{}, ErrInvalidAPIKey
+		}
+		return model.APIKey{}, authz.Subject{}, err
+	}
+	// Vendor yang dicabut verifikasinya tidak bisa memakai key-nya, sama seperti sesinya yang dicabut
+	if !authz.RoleHas(owner.Role, authz.APIKeyManage) || (owner.Role == "vendor" && !owner.IsVerified) {
+		return model.APIKey{}, authz.Subject{}, ErrAPIKeyOwnerInactive
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+		return model.APIKey{}, authz.Subject{}, err
+	}
+
+	scopes := make([]authz.Permission, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = authz.Permission(scope)
+	}
+	return key, authz.Subject{UserID: owner.ID, Role: owner.Role, Scopes: scopes}, nil
+}
+
+// IssueChallenge menerbitkan token tantangan berumur pendek untuk menyelesaikan login bertahap (2FA)
+func (s *authService) IssueChallenge(user model.User, purpose string) (string, time.Time, error) {
+	return auth.GenerateChallengeToken(user.ID, user.Role, purpose, s.keys, challengeTokenTTL)
+}
+
+func (s *authService) ParseChallenge(token string, purpose string) (*auth.ChallengeClaims, error) {
+	claims, err := auth.ParseChallengeToken(token, purpose, s.keys)
+	if err != nil {
+		return nil, ErrInvalidChallenge.Wrap(err)
+	}
+	return claims, nil
+}
+
+// issueForSession membuat pasangan access token & refresh token baru untuk sesi yang sudah ada
+func (s *authService) issueForSession(ctx context.Context, user model.User, sessionID uuid.UUID) (model.AuthResponse, error) {
+	accessToken, expiresAt, err := auth.GenerateToken(user.ID, user.Role, sessionID, s.keys, s.accessTokenTTL)
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+
+	refreshToken, refreshHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+	err = s.sessionRepo.CreateRefreshToken(ctx, model.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		TokenHash: refreshHash,
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	})
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+
+	return model.AuthResponse{
+		Token:        accessToken,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (s *authService) revokeForReuse(ctx context.Context, session model.Session) error {
+	log.Printf("security: refresh token reuse detected for session %s (user %s), revoking session", session.ID, session.UserID)
+	if err := s.sessionRepo.Revoke(ctx, session.ID, RevokeReasonTokenReuse); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
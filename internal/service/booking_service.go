@@ -3,48 +3,67 @@ package service
 import (
 	"context"
 	"errors"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/authz"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/repository"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvalidBookingInput     = apperror.Validation("invalid_booking_input", "invalid booking input")
+	ErrVehicleNotAvailable     = apperror.Conflict("vehicle_not_available", "vehicle is not available for the selected dates")
+	ErrVehicleNotBookable      = apperror.Conflict("vehicle_not_bookable", "vehicle is not open for booking")
+	ErrVehicleInMaintenance    = apperror.Conflict("vehicle_in_maintenance", "vehicle is under maintenance during the selected dates")
+	ErrRentalPriceNotSet       = apperror.Validation("rental_price_not_set", "rental price for this vehicle is not set")
+	ErrBookingNotFound         = apperror.NotFound("booking_not_found", "booking not found")
+	ErrBookingVehicleNotFound  = apperror.NotFound("booking_vehicle_not_found", "associated vehicle not found")
+	ErrBookingViewForbidden    = apperror.Forbidden("booking_view_forbidden", "forbidden: you are not authorized to view this booking")
+	ErrBookingUpdateForbidden  = apperror.Forbidden("booking_update_forbidden", "forbidden: you are not the owner of this vehicle's booking")
+	ErrBookingStatusFinal      = apperror.Conflict("booking_status_final", "cannot change status of a completed or cancelled booking")
+	ErrInvalidStatusTransition = apperror.Conflict("invalid_status_transition", "invalid status transition")
+	ErrPhoneVerificationNeeded = apperror.Forbidden("phone_verification_required", "this vendor requires a verified phone number before booking")
 )
 
 type BookingService interface {
 	CreateBooking(ctx context.Context, input model.CreateBookingInput, userID uuid.UUID) (model.Booking, error)
 	ConfirmPayment(ctx context.Context, bookingID uuid.UUID) error
-	GetBookingsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Booking, error)
-	GetBookingsByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]model.Booking, error)
-	GetBookingByID(ctx context.Context, bookingID uuid.UUID, currentUserID uuid.UUID) (model.Booking, error)
-	UpdateBookingStatus(ctx context.Context, bookingID, currentUserID uuid.UUID, newStatus string) (model.Booking, error)
+	GetBookingsByUserID(ctx context.Context, userID uuid.UUID, page model.PageRequest) (model.Page[model.Booking], error)
+	GetBookingsByOwnerID(ctx context.Context, ownerID uuid.UUID, page model.PageRequest) (model.Page[model.Booking], error)
+	GetBookingByID(ctx context.Context, bookingID uuid.UUID, subject authz.Subject) (model.Booking, error)
+	UpdateBookingStatus(ctx context.Context, bookingID uuid.UUID, subject authz.Subject, newStatus string) (model.Booking, error)
 }
 
 type bookingService struct {
 	bookingRepo repository.BookingRepository
 	vehicleRepo repository.VehicleRepository
+	userRepo    repository.UserRepository
 }
 
-func NewBookingService(bookingRepo repository.BookingRepository, vehicleRepo repository.VehicleRepository) BookingService {
-	return &bookingService{bookingRepo: bookingRepo, vehicleRepo: vehicleRepo}
+func NewBookingService(bookingRepo repository.BookingRepository, vehicleRepo repository.VehicleRepository, userRepo repository.UserRepository) BookingService {
+	return &bookingService{bookingRepo: bookingRepo, vehicleRepo: vehicleRepo, userRepo: userRepo}
 }
 
 func (s *bookingService) CreateBooking(ctx context.Context, input model.CreateBookingInput, userID uuid.UUID) (model.Booking, error) {
 	vehicleID, err := uuid.Parse(input.VehicleID)
 	if err != nil {
-		return model.Booking{}, errors.New("invalid vehicle id format")
+		return model.Booking{}, ErrInvalidBookingInput.WithField("vehicle_id", "invalid vehicle id format")
 	}
 
 	layout := "2006-01-02"
 	startDate, err := time.Parse(layout, input.StartDate)
 	if err != nil {
-		return model.Booking{}, errors.New("invalid start_date format, use YYYY-MM-DD")
+		return model.Booking{}, ErrInvalidBookingInput.WithField("start_date", "invalid start_date format, use YYYY-MM-DD")
 	}
 	endDate, err := time.Parse(layout, input.EndDate)
 	if err != nil {
-		return model.Booking{}, errors.New("invalid end_date format, use YYYY-MM-DD")
+		return model.Booking{}, ErrInvalidBookingInput.WithField("end_date", "invalid end_date format, use YYYY-MM-DD")
 	}
 	if endDate.Before(startDate) {
-		return model.Booking{}, errors.New("end_date cannot be before start_date")
+		return model.Booking{}, ErrInvalidBookingInput.WithField("end_date", "end_date cannot be before start_date")
 	}
 
 	available, err := s.bookingRepo.IsVehicleAvailable(ctx, vehicleID, startDate, endDate)
@@ -52,12 +71,27 @@ func (s *bookingService) CreateBooking(ctx context.Context, input model.CreateBo
 		return model.Booking{}, err
 	}
 	if !available {
-		return model.Booking{}, errors.New("vehicle is not available for the selected dates")
+		return model.Booking{}, ErrVehicleNotAvailable
 	}
 
 	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
-		return model.Booking{}, errors.New("vehicle not found")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Booking{}, ErrVehicleNotFound
+		}
+		return model.Booking{}, err
+	}
+
+	// Listing maintenance tetap bisa dibooking di luar jendela maintenance-nya
+	if vehicle.Status != "published" && vehicle.Status != "maintenance" {
+		return model.Booking{}, ErrVehicleNotBookable
+	}
+	if vehicle.MaintenanceOverlaps(startDate, endDate) {
+		return model.Booking{}, ErrVehicleInMaintenance
+	}
+
+	if err := s.checkPhoneRequirement(ctx, vehicle.OwnerID, userID); err != nil {
+		return model.Booking{}, err
 	}
 
 	durationDays := endDate.Sub(startDate).Hours()/24 + 1
@@ -67,7 +101,7 @@ func (s *bookingService) CreateBooking(ctx context.Context, input model.CreateBo
 
 	// PERBAIKAN: Cek apakah harga sewa tidak NULL sebelum digunakan
 	if vehicle.RentalPriceDaily == nil {
-		return model.Booking{}, errors.New("rental price for this vehicle is not set")
+		return model.Booking{}, ErrRentalPriceNotSet
 	}
 	// Ambil nilai dari pointer
 	dailyRate := *vehicle.RentalPriceDaily
@@ -91,48 +125,76 @@ func (s *bookingService) CreateBooking(ctx context.Context, input model.CreateBo
 	return createdBooking, nil
 }
 
-func (s *bookingService) ConfirmPayment(ctx context.Context, bookingID uuid.UUID) error {
-	return s.bookingRepo.UpdateStatus(ctx, bookingID, "confirmed")
-}
-
-func (s *bookingService) GetBookingsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Booking, error) {
-	return s.bookingRepo.FindBookingsByUserID(ctx, userID)
-}
+// checkPhoneRequirement menolak booking jika vendor mewajibkan nomor telepon terverifikasi
+// dan nomor customer belum diverifikasi lewat OTP
+func (s *bookingService) checkPhoneRequirement(ctx context.Context, vendorID, customerID uuid.UUID) error {
+	vendor, err := s.userRepo.FindByID(ctx, vendorID)
+	if err != nil {
+		return err
+	}
+	if !vendor.RequireVerifiedPhone {
+		return nil
+	}
 
-func (s *bookingService) GetBookingsByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]model.Booking, error) {
-	return s.bookingRepo.FindBookingsByOwnerID(ctx, ownerID)
+	customer, err := s.userRepo.FindByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if customer.PhoneVerifiedAt == nil {
+		return ErrPhoneVerificationNeeded
+	}
+	return nil
 }
 
-func (s *bookingService) GetBookingByID(ctx context.Context, bookingID uuid.UUID, currentUserID uuid.UUID) (model.Booking, error) {
-	booking, err := s.bookingRepo.FindBookingByID(ctx, bookingID)
+// ConfirmPayment mengonfirmasi booking setelah dibayar. Booking yang dibuat sebelum kendaraan dijadwalkan
+// maintenance bisa bertabrakan dengan jendelanya, sehingga dicek lagi di sini.
+func (s *bookingService) ConfirmPayment(ctx context.Context, bookingID uuid.UUID) error {
+	booking, vehicle, err := s.findBookingWithVehicle(ctx, bookingID)
 	if err != nil {
-		return model.Booking{}, errors.New("booking not found")
+		return err
+	}
+	if vehicle.MaintenanceOverlaps(booking.StartDate, booking.EndDate) {
+		return ErrVehicleInMaintenance
 	}
 
-	vehicle, err := s.vehicleRepo.FindByID(ctx, booking.VehicleID)
+	confirmed, err := s.bookingRepo.Confirm(ctx, bookingID)
 	if err != nil {
-		return model.Booking{}, errors.New("associated vehicle not found")
+		return err
 	}
-
-	if booking.UserID != currentUserID && vehicle.OwnerID != currentUserID {
-		return model.Booking{}, errors.New("forbidden: you are not authorized to view this booking")
+	if !confirmed {
+		return ErrVehicleInMaintenance
 	}
+	return nil
+}
 
-	return booking, nil
+func (s *bookingService) GetBookingsByUserID(ctx context.Context, userID uuid.UUID, page model.PageRequest) (model.Page[model.Booking], error) {
+	return s.bookingRepo.FindBookingsByUserID(ctx, userID, page)
 }
 
-func (s *bookingService) UpdateBookingStatus(ctx context.Context, bookingID, currentUserID uuid.UUID, newStatus string) (model.Booking, error) {
-	booking, err := s.bookingRepo.FindBookingByID(ctx, bookingID)
+func (s *bookingService) GetBookingsByOwnerID(ctx context.Context, ownerID uuid.UUID, page model.PageRequest) (model.Page[model.Booking], error) {
+	return s.bookingRepo.FindBookingsByOwnerID(ctx, ownerID, page)
+}
+
+func (s *bookingService) GetBookingByID(ctx context.Context, bookingID uuid.UUID, subject authz.Subject) (model.Booking, error) {
+	booking, vehicle, err := s.findBookingWithVehicle(ctx, bookingID)
 	if err != nil {
-		return model.Booking{}, errors.New("booking not found")
+		return model.Booking{}, err
 	}
 
-	vehicle, err := s.vehicleRepo.FindByID(ctx, booking.VehicleID)
+	if !authz.CanReadBooking(subject, booking, vehicle) {
+		return model.Booking{}, ErrBookingViewForbidden
+	}
+
+	return booking, nil
+}
+
+func (s *bookingService) UpdateBookingStatus(ctx context.Context, bookingID uuid.UUID, subject authz.Subject, newStatus string) (model.Booking, error) {
+	booking, vehicle, err := s.findBookingWithVehicle(ctx, bookingID)
 	if err != nil {
-		return model.Booking{}, errors.New("associated vehicle not found")
+		return model.Booking{}, err
 	}
-	if vehicle.OwnerID != currentUserID {
-		return model.Booking{}, errors.New("forbidden: you are not the owner of this vehicle's booking")
+	if !authz.CanUpdateBookingStatus(subject, vehicle) {
+		return model.Booking{}, ErrBookingUpdateForbidden
 	}
 
 	currentStatus := booking.Status
@@ -147,11 +209,11 @@ func (s *bookingService) UpdateBookingStatus(ctx context.Context, bookingID, cur
 			isValidTransition = true
 		}
 	case "completed", "cancelled":
-		return model.Booking{}, errors.New("cannot change status of a completed or cancelled booking")
+		return model.Booking{}, ErrBookingStatusFinal
 	}
 
 	if !isValidTransition {
-		return model.Booking{}, errors.New("invalid status transition from '" + currentStatus + "' to '" + newStatus + "'")
+		return model.Booking{}, apperror.Conflict(ErrInvalidStatusTransition.Code, "invalid status transition from '"+currentStatus+"' to '"+newStatus+"'")
 	}
 
 	err = s.bookingRepo.UpdateStatus(ctx, bookingID, newStatus)
@@ -166,3 +228,23 @@ func (s *bookingService) UpdateBookingStatus(ctx context.Context, bookingID, cur
 
 	return updatedBooking, nil
 }
+
+// findBookingWithVehicle mengambil booking beserta kendaraannya, dengan error NotFound yang sesuai
+func (s *bookingService) findBookingWithVehicle(ctx context.Context, bookingID uuid.UUID) (model.Booking, model.Vehicle, error) {
+	booking, err := s.bookingRepo.FindBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Booking{}, model.Vehicle{}, ErrBookingNotFound
+		}
+		return model.Booking{}, model.Vehicle{}, err
+	}
+
+	vehicle, err := s.vehicleRepo.FindByID(ctx, booking.VehicleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Booking{}, model.Vehicle{}, ErrBookingVehicleNotFound
+		}
+		return model.Booking{}, model.Vehicle{}, err
+	}
+	return booking, vehicle, nil
+}
@@ -3,60 +3,127 @@ package service
 import (
 	"context"
 	"errors"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/authz"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrCannotChatWithSelf         = apperror.Conflict("cannot_chat_with_self", "cannot start conversation with yourself")
+	ErrConversationNotFound       = apperror.NotFound("conversation_not_found", "conversation not found")
+	ErrNotConversationParticipant = apperror.Forbidden("not_conversation_participant", "forbidden: you are not a participant in this conversation")
+	ErrChatStartForbidden         = apperror.Forbidden("chat_start_forbidden", "forbidden: vendors can only start conversations about their own vehicles")
+	ErrChatCustomerRequired       = apperror.Validation("chat_customer_required", "customer_id is required when a vendor starts a conversation")
+	ErrChatCustomerNotFound       = apperror.NotFound("chat_customer_not_found", "customer not found")
 )
 
 type ChatService interface {
 	SaveMessage(ctx context.Context, msg model.Message) (model.Message, error)
-	StartConversation(ctx context.Context, customerID, vehicleID uuid.UUID) (model.Conversation, error)
-	GetConversationsForUser(ctx context.Context, userID uuid.UUID) ([]model.Conversation, error)
-	GetMessagesForConversation(ctx context.Context, conversationID, userID uuid.UUID) ([]model.Message, error)
+	StartConversation(ctx context.Context, subject authz.Subject, vehicleID uuid.UUID, input model.StartConversationInput) (model.Conversation, error)
+	GetConversationsForUser(ctx context.Context, userID uuid.UUID, page model.PageRequest) (model.Page[model.Conversation], error)
+	GetMessagesForConversation(ctx context.Context, conversationID uuid.UUID, subject authz.Subject, page model.PageRequest) (model.Page[model.Message], error)
 }
 
 type chatService struct {
 	chatRepo    repository.ChatRepository
 	vehicleRepo repository.VehicleRepository
+	userRepo    repository.UserRepository
 }
 
-func NewChatService(chatRepo repository.ChatRepository, vehicleRepo repository.VehicleRepository) ChatService {
-	return &chatService{chatRepo: chatRepo, vehicleRepo: vehicleRepo}
+func NewChatService(chatRepo repository.ChatRepository, vehicleRepo repository.VehicleRepository, userRepo repository.UserRepository) ChatService {
+	return &chatService{chatRepo: chatRepo, vehicleRepo: vehicleRepo, userRepo: userRepo}
 }
 
+// SaveMessage menyimpan pesan dari WebSocket. Pengirim harus peserta percakapan, dan penerima
+// selalu diambil dari percakapan (bukan dari input klien) agar pesan tidak bisa dikirim ke orang lain.
 func (s *chatService) SaveMessage(ctx context.Context, msg model.Message) (model.Message, error) {
+	convo, err := s.findConversation(ctx, msg.ConversationID)
+	if err != nil {
+		return model.Message{}, err
+	}
+	sender, err := s.userRepo.FindByID(ctx, msg.SenderID)
+	if err != nil {
+		return model.Message{}, err
+	}
+	if !authz.CanReadConversation(authz.Subject{UserID: sender.ID, Role: sender.Role}, convo) {
+		return model.Message{}, ErrNotConversationParticipant
+	}
+
+	msg.RecipientID = convo.VendorID
+	if msg.SenderID == convo.VendorID {
+		msg.RecipientID = convo.CustomerID
+	}
 	return s.chatRepo.SaveMessage(ctx, msg)
 }
 
-func (s *chatService) StartConversation(ctx context.Context, customerID, vehicleID uuid.UUID) (model.Conversation, error) {
+// StartConversation memulai (atau mengambil) percakapan tentang sebuah kendaraan. Customer memulai
+// dengan pemilik kendaraan; vendor pemilik kendaraan memulai dengan customer yang dipilih lewat customer_id.
+func (s *chatService) StartConversation(ctx context.Context, subject authz.Subject, vehicleID uuid.UUID, input model.StartConversationInput) (model.Conversation, error) {
 	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
-		return model.Conversation{}, errors.New("vehicle not found")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Conversation{}, ErrVehicleNotFound
+		}
+		return model.Conversation{}, err
 	}
 
-	vendorID := vehicle.OwnerID
-	if customerID == vendorID {
-		return model.Conversation{}, errors.New("cannot start conversation with yourself")
+	if !authz.CanStartConversation(subject, vehicle) {
+		if vehicle.OwnerID == subject.UserID {
+			return model.Conversation{}, ErrCannotChatWithSelf
+		}
+		return model.Conversation{}, ErrChatStartForbidden
 	}
 
-	return s.chatRepo.FindOrCreateConversation(ctx, customerID, vendorID, vehicleID)
+	customerID := subject.UserID
+	if vehicle.OwnerID == subject.UserID {
+		if input.CustomerID == nil {
+			return model.Conversation{}, ErrChatCustomerRequired.WithField("customer_id", "required")
+		}
+		customer, err := s.userRepo.FindByID(ctx, *input.CustomerID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return model.Conversation{}, ErrChatCustomerNotFound
+			}
+			return model.Conversation{}, err
+		}
+		if customer.Role != "customer" {
+			return model.Conversation{}, ErrChatCustomerNotFound
+		}
+		customerID = customer.ID
+	}
+
+	return s.chatRepo.FindOrCreateConversation(ctx, customerID, vehicle.OwnerID, vehicleID)
 }
 
-func (s *chatService) GetConversationsForUser(ctx context.Context, userID uuid.UUID) ([]model.Conversation, error) {
-	return s.chatRepo.FindConversationsByUserID(ctx, userID)
+func (s *chatService) GetConversationsForUser(ctx context.Context, userID uuid.UUID, page model.PageRequest) (model.Page[model.Conversation], error) {
+	return s.chatRepo.FindConversationsByUserID(ctx, userID, page)
 }
 
-func (s *chatService) GetMessagesForConversation(ctx context.Context, conversationID, userID uuid.UUID) ([]model.Message, error) {
+func (s *chatService) GetMessagesForConversation(ctx context.Context, conversationID uuid.UUID, subject authz.Subject, page model.PageRequest) (model.Page[model.Message], error) {
 	// Validasi keamanan: pastikan user yang meminta adalah bagian dari percakapan
-	convo, err := s.chatRepo.FindConversationByID(ctx, conversationID)
+	convo, err := s.findConversation(ctx, conversationID)
 	if err != nil {
-		return nil, errors.New("conversation not found")
+		return model.Page[model.Message]{}, err
 	}
 
-	if userID != convo.CustomerID && userID != convo.VendorID {
-		return nil, errors.New("forbidden: you are not a participant in this conversation")
+	if !authz.CanReadConversation(subject, convo) {
+		return model.Page[model.Message]{}, ErrNotConversationParticipant
 	}
 
-	return s.chatRepo.FindMessagesByConversationID(ctx, conversationID)
+	return s.chatRepo.FindMessagesByConversationID(ctx, conversationID, page)
+}
+
+func (s *chatService) findConversation(ctx context.Context, conversationID uuid.UUID) (model.Conversation, error) {
+	convo, err := s.chatRepo.FindConversationByID(ctx, conversationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Conversation{}, ErrConversationNotFound
+		}
+		return model.Conversation{}, err
+	}
+	return convo, nil
 }
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Repository tiruan di memori untuk test service. Interface repository di-embed sehingga method yang
+// tidak dipakai test panic jika terpanggil.
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	mu    sync.Mutex
+	users map[uuid.UUID]model.User
+}
+
+func newFakeUserRepo(users ...model.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: map[uuid.UUID]model.User{}}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) Save(ctx context.Context, user model.User) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, user.Email) {
+			return model.User{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	user.CreatedAt, user.UpdatedAt = time.Now(), time.Now()
+	r.users[user.ID] = user
+	return user, nil
+}
+
+func (r *fakeUserRepo) FindByID(ctx context.Context, id uuid.UUID) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return model.User{}, pgx.ErrNoRows
+	}
+	return u, nil
+}
+
+func (r *fakeUserRepo) FindByEmail(ctx context.Context, email string) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return model.User{}, pgx.ErrNoRows
+}
+
+func (r *fakeUserRepo) MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok || u.Email != email {
+		return false, nil
+	}
+	now := time.Now()
+	u.EmailVerifiedAt = &now
+	r.users[userID] = u
+	return true, nil
+}
+
+type fakeIdentityRepo struct {
+	mu         sync.Mutex
+	identities []model.UserIdentity
+}
+
+func (r *fakeIdentityRepo) Create(ctx context.Context, identity model.UserIdentity) (model.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range r.identities {
+		if i.Provider == identity.Provider && (i.Subject == identity.Subject || i.UserID == identity.UserID) {
+			return model.UserIdentity{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	r.identities = append(r.identities, identity)
+	return identity, nil
+}
+
+func (r *fakeIdentityRepo) FindByProviderSubject(ctx context.Context, provider, subject string) (model.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range r.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return i, nil
+		}
+	}
+	return model.UserIdentity{}, pgx.ErrNoRows
+}
+
+func (r *fakeIdentityRepo) RecordLogin(ctx context.Context, identity model.UserIdentity) error {
+	return nil
+}
+
+type fakeOIDCStateRepo struct {
+	mu     sync.Mutex
+	states map[string]model.OIDCLoginState
+}
+
+func newFakeOIDCStateRepo() *fakeOIDCStateRepo {
+	return &fakeOIDCStateRepo{states: map[string]model.OIDCLoginState{}}
+}
+
+func (r *fakeOIDCStateRepo) Create(ctx context.Context, state model.OIDCLoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[state.StateHash] = state
+	return nil
+}
+
+func (r *fakeOIDCStateRepo) Consume(ctx context.Context, stateHash, provider string) (model.OIDCLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.states[stateHash]
+	if !ok || s.Provider != provider || !s.ExpiresAt.After(time.Now()) {
+		return model.OIDCLoginState{}, pgx.ErrNoRows
+	}
+	delete(r.states, stateHash)
+	return s, nil
+}
+
+// fakeTwoFactor menganggap 2FA tidak aktif: login langsung selesai dengan token berisi ID user
+type fakeTwoFactor struct {
+	TwoFactorService
+}
+
+func (fakeTwoFactor) CompleteLogin(ctx context.Context, user model.User, meta model.RequestMeta) (model.LoginResponse, error) {
+	return model.LoginResponse{AuthResponse: model.AuthResponse{Token: "token-" + user.ID.String()}}, nil
+}
+
+// fakeVehicleRepo menyimpan kendaraan di memori. maintenanceBooked membuat ScheduleMaintenance menolak
+// jendela seolah bertabrakan dengan booking.
+type fakeVehicleRepo struct {
+	repository.VehicleRepository
+	mu                sync.Mutex
+	vehicles          map[uuid.UUID]model.Vehicle
+	maintenanceBooked bool
+}
+
+func newFakeVehicleRepo(vehicles ...model.Vehicle) *fakeVehicleRepo {
+	r := &fakeVehicleRepo{vehicles: map[uuid.UUID]model.Vehicle{}}
+	for _, v := range vehicles {
+		r.vehicles[v.ID] = v
+	}
+	return r
+}
+
+func (r *fakeVehicleRepo) FindByID(ctx context.Context, id uuid.UUID) (model.Vehicle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.vehicles[id]
+	if !ok {
+		return model.Vehicle{}, pgx.ErrNoRows
+	}
+	return v, nil
+}
+
+// UpdateStatus meniru update bersyarat: gagal dengan pgx.ErrNoRows jika status sudah berubah
+func (r *fakeVehicleRepo) UpdateStatus(ctx context.Context, vehicle model.Vehicle, fromStatus string) (model.Vehicle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current, ok := r.vehicles[vehicle.ID]; !ok || current.Status != fromStatus {
+		return model.Vehicle{}, pgx.ErrNoRows
+	}
+	r.vehicles[vehicle.ID] = vehicle
+	return vehicle, nil
+}
+
+func (r *fakeVehicleRepo) ScheduleMaintenance(ctx context.Context, vehicle model.Vehicle, fromStatus string) (model.Vehicle, bool, error) {
+	if r.maintenanceBooked {
+		return model.Vehicle{}, false, nil
+	}
+	updated, err := r.UpdateStatus(ctx, vehicle, fromStatus)
+	return updated, err == nil, err
+}
+
+// FindAll mengabaikan filter dan mengembalikan semua kendaraan dalam satu halaman
+func (r *fakeVehicleRepo) FindAll(ctx context.Context, filter model.VehicleFilter, page model.PageRequest) (model.Page[model.Vehicle], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result model.Page[model.Vehicle]
+	for _, v := range r.vehicles {
+		result.Items = append(result.Items, v)
+	}
+	return result, nil
+}
+
+// fakeImageRepo mencatat gambar sesuai urutan penyimpanan. failCall membuat penyimpanan ke-n gagal (mulai 1).
+type fakeImageRepo struct {
+	repository.ImageRepository
+	mu       sync.Mutex
+	images   []model.VehicleImage
+	calls    int
+	failCall int
+}
+
+func (r *fakeImageRepo) SaveVehicleImage(ctx context.Context, image model.VehicleImage) (model.VehicleImage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.calls == r.failCall {
+		return model.VehicleImage{}, errors.New("database unavailable")
+	}
+	image.SortOrder = len(r.images)
+	image.IsPrimary = len(r.images) == 0
+	r.images = append(r.images, image)
+	return image, nil
+}
+
+type fakeThrottleRepo struct {
+	repository.LoginThrottleRepository
+	mu       sync.Mutex
+	counters map[string]*model.LoginThrottle
+}
+
+func newFakeThrottleRepo() *fakeThrottleRepo {
+	return &fakeThrottleRepo{counters: map[string]*model.LoginThrottle{}}
+}
+
+func (r *fakeThrottleRepo) FindBlocked(ctx context.Context, keys map[string]string) ([]model.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var blocked []model.LoginThrottle
+	for scope, key := range keys {
+		if t, ok := r.counters[scope+"|"+key]; ok && t.BlockedUntil != nil && t.BlockedUntil.After(time.Now()) {
+			blocked = append(blocked, *t)
+		}
+	}
+	return blocked, nil
+}
+
+func (r *fakeThrottleRepo) RecordFailure(ctx context.Context, scope, key string, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.counters[scope+"|"+key]
+	if !ok || t.LastFailedAt.Before(time.Now().Add(-window)) {
+		t = &model.LoginThrottle{Scope: scope, Key: key}
+		r.counters[scope+"|"+key] = t
+	}
+	t.FailedAttempts++
+	t.LastFailedAt = time.Now()
+	return t.FailedAttempts, nil
+}
+
+func (r *fakeThrottleRepo) Block(ctx context.Context, scope, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.counters[scope+"|"+key]; ok {
+		t.BlockedUntil = &until
+	}
+	return nil
+}
+
+// fakeTwoFactorRepo menyimpan satu data 2FA per user beserta hash kode cadangannya
+type fakeTwoFactorRepo struct {
+	repository.TwoFactorRepository
+	mu          sync.Mutex
+	records     map[uuid.UUID]model.TwoFactor
+	backupCodes map[uuid.UUID][]string
+}
+
+func newFakeTwoFactorRepo() *fakeTwoFactorRepo {
+	return &fakeTwoFactorRepo{records: map[uuid.UUID]model.TwoFactor{}, backupCodes: map[uuid.UUID][]string{}}
+}
+
+func (r *fakeTwoFactorRepo) FindByUserID(ctx context.Context, userID uuid.UUID) (model.TwoFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tf, ok := r.records[userID]
+	if !ok {
+		return model.TwoFactor{}, pgx.ErrNoRows
+	}
+	return tf, nil
+}
+
+func (r *fakeTwoFactorRepo) Delete(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, userID)
+	delete(r.backupCodes, userID)
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) UseBackupCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, hash := range r.backupCodes[userID] {
+		if hash == codeHash {
+			r.backupCodes[userID] = append(r.backupCodes[userID][:i], r.backupCodes[userID][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeSettings mengembalikan pengaturan platform yang tetap
+type fakeSettings struct {
+	SettingsService
+	settings model.PlatformSettings
+}
+
+func (s fakeSettings) GetSettings(ctx context.Context) (model.PlatformSettings, error) {
+	return s.settings, nil
+}
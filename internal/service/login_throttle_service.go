@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/auth"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+	"time"
+)
+
+var (
+	ErrTooManyLoginAttempts = apperror.RateLimited("too_many_login_attempts", "too many failed login attempts, please try again later")
+	ErrTooManyEmailRequests = apperror.RateLimited("too_many_email_requests", "too many email requests, please try again later")
+	ErrInvalidThrottleScope = apperror.Validation("invalid_throttle_scope", "scope must be 'account' or 'ip'")
+	ErrLoginLockNotFound    = apperror.NotFound("login_lock_not_found", "login lock not found")
+)
+
+// LoginThrottleConfig mengatur batas login gagal. Setelah ProgressiveAfter kali gagal, setiap
+// percobaan berikutnya harus menunggu jeda yang berlipat dua (maksimal MaxDelay); setelah
+// MaxAccountAttempts/MaxIPAttempts kali gagal, akun/IP dikunci selama LockoutDuration.
+// Permintaan email (reset password, kirim ulang verifikasi) dibatasi MaxEmailRequests per
+// email dan MaxIPAttempts per IP dalam EmailRequestWindow.
+type LoginThrottleConfig struct {
+	MaxAccountAttempts int
+	MaxIPAttempts      int
+	LockoutDuration    time.Duration
+	ProgressiveAfter   int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	MaxEmailRequests   int
+	EmailRequestWindow time.Duration
+}
+
+type LoginThrottleService interface {
+	Check(ctx context.Context, email string, meta model.RequestMeta) error
+	RecordFailure(ctx context.Context, email string, meta model.RequestMeta) error
+	RecordSuccess(ctx context.Context, email string) error
+	LimitEmailRequest(ctx context.Context, action, email string, meta model.RequestMeta) error
+	ListLocks(ctx context.Context, page model.PageRequest) (model.Page[model.LoginThrottle], error)
+	ClearLock(ctx context.Context, scope, key string) error
+}
+
+type loginThrottleService struct {
+	repo repository.LoginThrottleRepository
+	cfg  LoginThrottleConfig
+}
+
+func NewLoginThrottleService(repo repository.LoginThrottleRepository, cfg LoginThrottleConfig) LoginThrottleService {
+	return &loginThrottleService{repo: repo, cfg: cfg}
+}
+
+// Check menolak percobaan login jika akun atau IP sedang diblokir
+func (s *loginThrottleService) Check(ctx context.Context, email string, meta model.RequestMeta) error {
+	retryAfter, err := s.retryAfter(ctx, s.keys(email, meta))
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return ErrTooManyLoginAttempts.WithRetryAfter(retryAfter)
+	}
+	return nil
+}
+
+func (s *loginThrottleService) RecordFailure(ctx context.Context, email string, meta model.RequestMeta) error {
+	limits := map[string]int{
+		model.ThrottleScopeAccount: s.cfg.MaxAccountAttempts,
+		model.ThrottleScopeIP:      s.cfg.MaxIPAttempts,
+	}
+	for scope, key := range s.keys(email, meta) {
+		attempts, err := s.repo.RecordFailure(ctx, scope, key, s.cfg.LockoutDuration)
+		if err != nil {
+			return err
+		}
+
+		delay := s.delayFor(attempts, limits[scope])
+		if delay == 0 {
+			continue
+		}
+		if delay == s.cfg.LockoutDuration {
+			log.Printf("security: login locked for %s %q after %d failed attempts", scope, key, attempts)
+		}
+		if err := s.repo.Block(ctx, scope, key, time.Now().Add(delay)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordSuccess mereset penghitung akun. Penghitung IP sengaja tidak direset agar satu akun
+// milik penyerang tidak bisa dipakai untuk menghapus jejak tebakan terhadap akun lain.
+func (s *loginThrottleService) RecordSuccess(ctx context.Context, email string) error {
+	_, err := s.repo.Clear(ctx, model.ThrottleScopeAccount, helper.NormalizeEmail(email))
+	return err
+}
+
+// LimitEmailRequest menghitung setiap permintaan email untuk aksi tertentu, berhasil atau tidak,
+// dan menolaknya jika email atau IP sudah melewati batas dalam window. Email disimpan sebagai
+// hash agar kuncinya selalu muat di kolom key, berapa pun panjang email yang dikirim klien.
+func (s *loginThrottleService) LimitEmailRequest(ctx context.Context, action, email string, meta model.RequestMeta) error {
+	keys := s.keys(email, meta)
+	keys[model.ThrottleScopeAccount] = auth.HashToken(keys[model.ThrottleScopeAccount])
+	for scope, key := range keys {
+		keys[scope] = action + ":" + key
+	}
+
+	retryAfter, err := s.retryAfter(ctx, keys)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return ErrTooManyEmailRequests.WithRetryAfter(retryAfter)
+	}
+
+	limits := map[string]int{
+		model.ThrottleScopeAccount: s.cfg.MaxEmailRequests,
+		model.ThrottleScopeIP:      s.cfg.MaxIPAttempts,
+	}
+	for scope, key := range keys {
+		requests, err := s.repo.RecordFailure(ctx, scope, key, s.cfg.EmailRequestWindow)
+		if err != nil {
+			return err
+		}
+		if requests >= limits[scope] {
+			if err := s.repo.Block(ctx, scope, key, time.Now().Add(s.cfg.EmailRequestWindow)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *loginThrottleService) ListLocks(ctx context.Context, page model.PageRequest) (model.Page[model.LoginThrottle], error) {
+	return s.repo.FindAllBlocked(ctx, page)
+}
+
+func (s *loginThrottleService) ClearLock(ctx context.Context, scope, key string) error {
+	switch scope {
+	case model.ThrottleScopeAccount:
+		key = helper.NormalizeEmail(key)
+	case model.ThrottleScopeIP:
+	default:
+		return ErrInvalidThrottleScope
+	}
+
+	cleared, err := s.repo.Clear(ctx, scope, key)
+	if err != nil {
+		return err
+	}
+	if !cleared {
+		return ErrLoginLockNotFound
+	}
+	return nil
+}
+
+// retryAfter mengembalikan sisa waktu blokir terlama di antara kunci yang diberikan (0 jika tidak diblokir)
+func (s *loginThrottleService) retryAfter(ctx context.Context, keys map[string]string) (time.Duration, error) {
+	blocked, err := s.repo.FindBlocked(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	var retryAfter time.Duration
+	for _, t := range blocked {
+		if wait := time.Until(*t.BlockedUntil); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	return retryAfter, nil
+}
+
+// delayFor menghitung berapa lama login harus ditahan setelah sejumlah percobaan gagal
+func (s *loginThrottleService) delayFor(attempts, maxAttempts int) time.Duration {
+	if attempts >= maxAttempts {
+		return s.cfg.LockoutDuration
+	}
+	if attempts < s.cfg.ProgressiveAfter {
+		return 0
+	}
+	shift := attempts - s.cfg.ProgressiveAfter
+	if shift > 30 {
+		return s.cfg.MaxDelay
+	}
+	if delay := s.cfg.BaseDelay << shift; delay < s.cfg.MaxDelay {
+		return delay
+	}
+	return s.cfg.MaxDelay
+}
+
+func (s *loginThrottleService) keys(email string, meta model.RequestMeta) map[string]string {
+	keys := map[string]string{model.ThrottleScopeAccount: helper.NormalizeEmail(email)}
+	if meta.IPAddress != "" {
+		keys[model.ThrottleScopeIP] = meta.IPAddress
+	}
+	return keys
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/model"
+)
+
+func newTestThrottle() LoginThrottleService {
+	return NewLoginThrottleService(newFakeThrottleRepo(), LoginThrottleConfig{
+		MaxAccountAttempts: 5,
+		MaxIPAttempts:      20,
+		LockoutDuration:    15 * time.Minute,
+		ProgressiveAfter:   3,
+		BaseDelay:          time.Second,
+		MaxDelay:           30 * time.Second,
+		MaxEmailRequests:   3,
+		EmailRequestWindow: time.Hour,
+	})
+}
+
+func TestLimitEmailRequestBlocksAfterLimit(t *testing.T) {
+	throttle := newTestThrottle()
+	ctx := context.Background()
+	meta := model.RequestMeta{IPAddress: "10.0.0.1"}
+
+	for i := 0; i < 3; i++ {
+		if err := throttle.LimitEmailRequest(ctx, model.ThrottleActionPasswordReset, "Budi@Example.com", meta); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	err := throttle.LimitEmailRequest(ctx, model.ThrottleActionPasswordReset, "budi@example.com", meta)
+	assertErrorCode(t, err, ErrTooManyEmailRequests)
+	if appErr, _ := apperror.As(err); appErr.RetryAfter <= 0 {
+		t.Errorf("RetryAfter = %v, want > 0", appErr.RetryAfter)
+	}
+
+	// Penghitung per aksi terpisah dari aksi lain dan dari login
+	if err := throttle.LimitEmailRequest(ctx, model.ThrottleActionEmailVerification, "budi@example.com", meta); err != nil {
+		t.Errorf("verification request: %v", err)
+	}
+	if err := throttle.Check(ctx, "budi@example.com", meta); err != nil {
+		t.Errorf("login check: %v", err)
+	}
+}
+
+func TestLimitEmailRequestKeysFitColumn(t *testing.T) {
+	repo := newFakeThrottleRepo()
+	throttle := NewLoginThrottleService(repo, LoginThrottleConfig{MaxIPAttempts: 20, MaxEmailRequests: 3, EmailRequestWindow: time.Hour})
+	longEmail := strings.Repeat("a", 300) + "@example.com"
+
+	if err := throttle.LimitEmailRequest(context.Background(), model.ThrottleActionEmailVerification, longEmail, model.RequestMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, counter := range repo.counters {
+		// login_throttles.key adalah VARCHAR(255)
+		if len(counter.Key) > 255 || strings.Contains(counter.Key, "@") {
+			t.Errorf("key %q must be a short hash of the email", counter.Key)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"strings"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/auth"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/oidc"
+	"sultra-otomotif-api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrOIDCProviderNotFound  = apperror.NotFound("oidc_provider_not_found", "login provider not found")
+	ErrInvalidOIDCState      = apperror.Unauthorized("invalid_oidc_state", "login session is invalid or has expired, please start again")
+	ErrOIDCLoginFailed       = apperror.Unauthorized("oidc_login_failed", "could not verify the login with the provider")
+	ErrOIDCEmailNotVerified  = apperror.Forbidden("oidc_email_not_verified", "the provider account has no verified email address")
+	ErrOIDCLinkNeedsVerified = apperror.Conflict("oidc_link_requires_verified_email", "an account with this email already exists; sign in with your password and verify your email before using this login provider")
+	ErrOIDCAlreadyLinked     = apperror.Conflict("oidc_already_linked", "this account is already linked to a different account at this login provider")
+)
+
+// oidcStateTTL adalah batas waktu user menyelesaikan login di halaman penyedia
+const oidcStateTTL = 10 * time.Minute
+
+// OIDCService menangani login lewat penyedia OpenID Connect (misal Google) dengan authorization code + PKCE
+type OIDCService interface {
+	Providers() []string
+	Authorize(ctx context.Context, provider string) (model.OIDCAuthorization, error)
+	Callback(ctx context.Context, provider string, input model.OIDCCallbackInput, browserState string, meta model.RequestMeta) (model.LoginResponse, error)
+}
+
+type oidcService struct {
+	providers    map[string]*oidc.Provider
+	userRepo     repository.UserRepository
+	identityRepo repository.UserIdentityRepository
+	stateRepo    repository.OIDCStateRepository
+	twoFactor    TwoFactorService
+}
+
+func NewOIDCService(providers []*oidc.Provider, userRepo repository.UserRepository, identityRepo repository.UserIdentityRepository, stateRepo repository.OIDCStateRepository, twoFactor TwoFactorService) OIDCService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &oidcService{
+		providers:    byName,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		stateRepo:    stateRepo,
+		twoFactor:    twoFactor,
+	}
+}
+
+func (s *oidcService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Authorize membuat state, nonce dan PKCE verifier baru lalu mengembalikan URL halaman login penyedia.
+// Verifier dan nonce hanya disimpan di server; yang dibawa browser hanya state, yang juga disimpan
+// handler di cookie HttpOnly agar callback hanya diterima dari browser yang memulai login.
+func (s *oidcService) Authorize(ctx context.Context, providerName string) (model.OIDCAuthorization, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return model.OIDCAuthorization{}, ErrOIDCProviderNotFound
+	}
+
+	state, stateHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return model.OIDCAuthorization{}, apperror.Internal("oidc_provider_unavailable", "login provider is unavailable", err)
+	}
+
+	expiresAt := time.Now().Add(oidcStateTTL)
+	err = s.stateRepo.Create(ctx, model.OIDCLoginState{
+		StateHash:    stateHash,
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	return model.OIDCAuthorization{AuthorizationURL: authURL, State: state, ExpiresAt: expiresAt}, nil
+}
+
+// Callback menukar authorization code, memverifikasi ID token, lalu login sebagai user yang terhubung.
+// browserState adalah state dari cookie browser; harus sama dengan state di input, sehingga penyerang
+// tidak bisa membuat korban login ke akun penyerang dengan meneruskan code & state miliknya (login CSRF).
+// Akun baru dibuat (atau akun lama dihubungkan) hanya berdasarkan email yang sudah diverifikasi.
+func (s *oidcService) Callback(ctx context.Context, providerName string, input model.OIDCCallbackInput, browserState string, meta model.RequestMeta) (model.LoginResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return model.LoginResponse{}, ErrOIDCProviderNotFound
+	}
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(browserState), []byte(input.State)) != 1 {
+		return model.LoginResponse{}, ErrInvalidOIDCState
+	}
+
+	// State dihapus saat dipakai sehingga callback yang sama tidak bisa diputar ulang
+	state, err := s.stateRepo.Consume(ctx, auth.HashToken(input.State), providerName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.LoginResponse{}, ErrInvalidOIDCState
+		}
+		return model.LoginResponse{}, err
+	}
+
+	claims, err := provider.Exchange(ctx, input.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrExchangeFailed) || errors.Is(err, oidc.ErrInvalidIDToken) {
+			return model.LoginResponse{}, ErrOIDCLoginFailed.Wrap(err)
+		}
+		return model.LoginResponse{}, apperror.Internal("oidc_provider_unavailable", "login provider is unavailable", err)
+	}
+
+	user, err := s.resolveUser(ctx, providerName, claims)
+	if err != nil {
+		return model.LoginResponse{}, err
+	}
+	return s.twoFactor.CompleteLogin(ctx, user, meta)
+}
+
+// resolveUser mencari user yang terhubung dengan akun penyedia, atau menghubungkan/membuatnya
+func (s *oidcService) resolveUser(ctx context.Context, providerName string, claims oidc.Claims) (model.User, error) {
+	claims.Email = helper.NormalizeEmail(claims.Email)
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		identity.Email = claims.Email
+		if err := s.identityRepo.RecordLogin(ctx, identity); err != nil {
+			return model.User{}, err
+		}
+		return s.userRepo.FindByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return model.User{}, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return model.User{}, ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		// Tanpa cek ini, siapa pun yang mendaftar duluan dengan email orang lain akan ikut
+		// menguasai akun Google pemilik email tersebut
+		if user.EmailVerifiedAt == nil {
+			return model.User{}, ErrOIDCLinkNeedsVerified
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		user, err = s.createUser(ctx, claims)
+		if err != nil {
+			return model.User{}, err
+		}
+	default:
+		return model.User{}, err
+	}
+
+	_, err = s.identityRepo.Create(ctx, model.UserIdentity{
+		ID:       uuid.New(),
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		if !repository.IsUniqueViolation(err) {
+			return model.User{}, err
+		}
+		// Callback paralel untuk akun yang sama sudah membuat identitasnya lebih dulu
+		identity, err := s.identityRepo.FindByProviderSubject(ctx, providerName, claims.Subject)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// User ini sudah terhubung dengan akun lain di penyedia yang sama
+			return model.User{}, ErrOIDCAlreadyLinked
+		}
+		if err != nil {
+			return model.User{}, err
+		}
+		return s.userRepo.FindByID(ctx, identity.UserID)
+	}
+	return user, nil
+}
+
+// createUser mendaftarkan customer baru dari akun penyedia. Akun ini tidak punya password;
+// user bisa membuatnya lewat lupa password jika ingin login dengan email & password.
+func (s *oidcService) createUser(ctx context.Context, claims oidc.Claims) (model.User, error) {
+	fullName := strings.TrimSpace(claims.Name)
+	if fullName == "" {
+		fullName, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	user, err := s.userRepo.Save(ctx, model.User{
+		ID:       uuid.New(),
+		FullName: fullName,
+		Email:    claims.Email,
+		Role:     "customer",
+	})
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return model.User{}, ErrEmailAlreadyRegistered
+		}
+		return model.User{}, err
+	}
+
+	// Email sudah diverifikasi oleh penyedia
+	if _, err := s.userRepo.MarkEmailVerified(ctx, user.ID, user.Email); err != nil {
+		return model.User{}, err
+	}
+	return s.userRepo.FindByID(ctx, user.ID)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/oidc"
+	"sultra-otomotif-api/internal/oidc/oidctest"
+
+	"github.com/google/uuid"
+)
+
+type oidcTestEnv struct {
+	service    OIDCService
+	idp        *oidctest.IdP
+	users      *fakeUserRepo
+	identities *fakeIdentityRepo
+}
+
+func newOIDCTestEnv(t *testing.T, users ...model.User) *oidcTestEnv {
+	t.Helper()
+	idp := oidctest.NewIdP("client-1")
+	t.Cleanup(idp.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:        "mock",
+		Issuer:      idp.Issuer(),
+		ClientID:    "client-1",
+		RedirectURL: "http://localhost:3000/auth/callback/mock",
+	}, nil)
+	env := &oidcTestEnv{idp: idp, users: newFakeUserRepo(users...), identities: &fakeIdentityRepo{}}
+	env.service = NewOIDCService([]*oidc.Provider{provider}, env.users, env.identities, newFakeOIDCStateRepo(), fakeTwoFactor{})
+	return env
+}
+
+// login menjalankan Authorize lalu login di IdP; mengembalikan input callback yang diteruskan frontend
+func (env *oidcTestEnv) login(t *testing.T, identity oidctest.Identity) model.OIDCCallbackInput {
+	t.Helper()
+	authorization, err := env.service.Authorize(context.Background(), "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := env.idp.Login(authorization.AuthorizationURL, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != authorization.State {
+		t.Fatalf("IdP returned state %q, want %q", state, authorization.State)
+	}
+	return model.OIDCCallbackInput{Code: code, State: state}
+}
+
+func assertErrorCode(t *testing.T, err error, want *apperror.Error) {
+	t.Helper()
+	got, ok := apperror.As(err)
+	if !ok || got.Code != want.Code {
+		t.Fatalf("err = %v, want %s", err, want.Code)
+	}
+}
+
+func TestOIDCCallbackCreatesUser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	input := env.login(t, oidctest.Identity{Subject: "sub-1", Email: "Budi@Example.com", EmailVerified: true, Name: "Budi"})
+
+	resp, err := env.service.Callback(context.Background(), "mock", input, input.State, model.RequestMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := env.users.FindByEmail(context.Background(), "budi@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Token != "token-"+user.ID.String() {
+		t.Errorf("logged in as %q, want the new user %s", resp.Token, user.ID)
+	}
+	if user.Role != "customer" || user.FullName != "Budi" || user.EmailVerifiedAt == nil {
+		t.Errorf("user = %+v, want a verified customer named Budi", user)
+	}
+	if user.Email != "budi@example.com" {
+		t.Errorf("email = %q, want it stored in lowercase", user.Email)
+	}
+	if _, err := env.identities.FindByProviderSubject(context.Background(), "mock", "sub-1"); err != nil {
+		t.Errorf("identity not linked: %v", err)
+	}
+}
+
+func TestOIDCCallbackStateIsSingleUse(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	input := env.login(t, oidctest.Identity{Subject: "sub-1", Email: "budi@example.com", EmailVerified: true})
+
+	if _, err := env.service.Callback(context.Background(), "mock", input, input.State, model.RequestMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := env.service.Callback(context.Background(), "mock", input, input.State, model.RequestMeta{})
+	assertErrorCode(t, err, ErrInvalidOIDCState)
+}
+
+func TestOIDCCallbackRejectsStateFromAnotherBrowser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	// Penyerang memulai login dengan akunnya sendiri lalu meneruskan code & state ke browser korban
+	attacker := env.login(t, oidctest.Identity{Subject: "attacker", Email: "attacker@example.com", EmailVerified: true})
+	victim, err := env.service.Authorize(context.Background(), "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, browserState := range map[string]string{"no cookie": "", "victim cookie": victim.State} {
+		t.Run(name, func(t *testing.T) {
+			_, err := env.service.Callback(context.Background(), "mock", attacker, browserState, model.RequestMeta{})
+			assertErrorCode(t, err, ErrInvalidOIDCState)
+		})
+	}
+	if _, err := env.users.FindByEmail(context.Background(), "attacker@example.com"); err == nil {
+		t.Error("attacker account was created from a rejected callback")
+	}
+}
+
+func TestOIDCCallbackRejectsWrongProvider(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	input := env.login(t, oidctest.Identity{Subject: "sub-1", Email: "budi@example.com", EmailVerified: true})
+
+	_, err := env.service.Callback(context.Background(), "google", input, input.State, model.RequestMeta{})
+	assertErrorCode(t, err, ErrOIDCProviderNotFound)
+}
+
+func TestOIDCCallbackEmailLinking(t *testing.T) {
+	verifiedAt := time.Now()
+	verified := model.User{ID: uuid.New(), FullName: "Sari", Email: "sari@example.com", Role: "vendor", EmailVerifiedAt: &verifiedAt}
+	unverified := model.User{ID: uuid.New(), FullName: "Andi", Email: "andi@example.com", Role: "customer"}
+
+	t.Run("links verified local account", func(t *testing.T) {
+		env := newOIDCTestEnv(t, verified)
+		// Huruf besar di email penyedia tetap cocok dengan akun lokal
+		input := env.login(t, oidctest.Identity{Subject: "sub-sari", Email: "Sari@Example.com", EmailVerified: true})
+
+		resp, err := env.service.Callback(context.Background(), "mock", input, input.State, model.RequestMeta{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Token != "token-"+verified.ID.String() {
+			t.Errorf("logged in as %q, want existing user %s", resp.Token, verified.ID)
+		}
+		identity, err := env.identities.FindByProviderSubject(context.Background(), "mock", "sub-sari")
+		if err != nil || identity.UserID != verified.ID {
+			t.Errorf("identity = %+v, %v; want linked to %s", identity, err, verified.ID)
+		}
+	})
+
+	t.Run("refuses unverified local account", func(t *testing.T) {
+		env := newOIDCTestEnv(t, unverified)
+		input := env.login(t, oidctest.Identity{Subject: "sub-andi", Email: "andi@example.com", EmailVerified: true})
+
+		_, err := env.service.Callback(context.Background(), "mock", input, input.State, model.RequestMeta{})
+		assertErrorCode(t, err, ErrOIDCLinkNeedsVerified)
+	})
+
+	t.Run("refuses unverified provider email", func(t *testing.T) {
+		env := newOIDCTestEnv(t, verified)
+		input := env.login(t, oidctest.Identity{Subject: "sub-x", Email: "sari@example.com", EmailVerified: false})
+
+		_, err := env.service.Callback(context.Background(), "mock", input, input.State, model.RequestMeta{})
+		assertErrorCode(t, err, ErrOIDCEmailNotVerified)
+	})
+
+	t.Run("returning identity logs in without email checks", func(t *testing.T) {
+		env := newOIDCTestEnv(t, verified)
+		identity := oidctest.Identity{Subject: "sub-sari", Email: "sari@example.com", EmailVerified: true}
+		first := env.login(t, identity)
+		if _, err := env.service.Callback(context.Background(), "mock", first, first.State, model.RequestMeta{}); err != nil {
+			t.Fatal(err)
+		}
+
+		identity.EmailVerified = false
+		second := env.login(t, identity)
+		resp, err := env.service.Callback(context.Background(), "mock", second, second.State, model.RequestMeta{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Token != "token-"+verified.ID.String() {
+			t.Errorf("logged in as %q, want %s", resp.Token, verified.ID)
+		}
+	})
+}
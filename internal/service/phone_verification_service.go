@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/auth"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+	"sultra-otomotif-api/internal/sms"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrPhoneAlreadyVerified = apperror.Conflict("phone_already_verified", "phone number is already verified")
+	ErrPhoneNumberMissing   = apperror.Validation("phone_number_missing", "add a phone number to your profile before requesting a verification code")
+	ErrOTPSendLimited       = apperror.RateLimited("otp_send_limited", "too many verification codes requested, please try again later")
+	ErrInvalidOTP           = apperror.Validation("invalid_otp", "invalid verification code")
+	ErrOTPExpired           = apperror.Validation("otp_expired", "verification code has expired or was not requested, please request a new one")
+	ErrOTPTooManyAttempts   = apperror.RateLimited("otp_too_many_attempts", "too many incorrect attempts, please request a new code")
+)
+
+// PhoneVerificationConfig mengatur masa berlaku OTP dan batas pengiriman SMS
+type PhoneVerificationConfig struct {
+	CodeTTL     time.Duration // masa berlaku satu kode
+	MaxAttempts int           // jumlah tebakan per kode, termasuk tebakan yang benar
+	Cooldown    time.Duration // jeda minimal antar pengiriman
+	MaxPerHour  int           // pengiriman maksimal per user/nomor dalam satu jam
+}
+
+// PhoneVerificationService mengirim dan memverifikasi OTP untuk nomor telepon user
+type PhoneVerificationService interface {
+	SendOTP(ctx context.Context, userID uuid.UUID) (model.PhoneOTPSent, error)
+	VerifyOTP(ctx context.Context, userID uuid.UUID, input model.VerifyPhoneInput) (model.User, error)
+}
+
+type phoneVerificationService struct {
+	userRepo repository.UserRepository
+	otpRepo  repository.PhoneOTPRepository
+	sender   sms.SMSSender
+	cfg      PhoneVerificationConfig
+}
+
+func NewPhoneVerificationService(userRepo repository.UserRepository, otpRepo repository.PhoneOTPRepository, sender sms.SMSSender, cfg PhoneVerificationConfig) PhoneVerificationService {
+	return &phoneVerificationService{userRepo: userRepo, otpRepo: otpRepo, sender: sender, cfg: cfg}
+}
+
+func (s *phoneVerificationService) SendOTP(ctx context.Context, userID uuid.UUID) (model.PhoneOTPSent, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return model.PhoneOTPSent{}, err
+	}
+	if user.PhoneVerifiedAt != nil {
+		return model.PhoneOTPSent{}, ErrPhoneAlreadyVerified
+	}
+	// Akun dari login OIDC bisa belum punya nomor telepon
+	if user.PhoneNumber == "" {
+		return model.PhoneOTPSent{}, ErrPhoneNumberMissing
+	}
+
+	code, err := generateOTPCode()
+	if err != nil {
+		return model.PhoneOTPSent{}, err
+	}
+	now := time.Now()
+	otp := model.PhoneOTP{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		PhoneNumber: user.PhoneNumber,
+		ExpiresAt:   now.Add(s.cfg.CodeTTL),
+	}
+	otp.CodeHash = hashOTPCode(otp.ID, code)
+
+	// Batasi pengiriman per user dan per nomor agar endpoint ini tidak dipakai untuk spam SMS
+	limit := model.OTPSendLimit{Cooldown: s.cfg.Cooldown, MaxPerHour: s.cfg.MaxPerHour}
+	created, retryAfter, err := s.otpRepo.CreateWithinLimit(ctx, otp, limit)
+	if err != nil {
+		return model.PhoneOTPSent{}, err
+	}
+	if !created {
+		return model.PhoneOTPSent{}, ErrOTPSendLimited.WithRetryAfter(retryAfter)
+	}
+
+	body := fmt.Sprintf("Kode verifikasi Sultra Otomotif Anda: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.", code, int(s.cfg.CodeTTL.Minutes()))
+	if err := s.sender.Send(ctx, user.PhoneNumber, body); err != nil {
+		return model.PhoneOTPSent{}, apperror.Internal("sms_send_failed", "failed to send verification code", err)
+	}
+
+	return model.PhoneOTPSent{
+		PhoneNumber: user.PhoneNumber,
+		ExpiresAt:   otp.ExpiresAt,
+		ResendAt:    now.Add(s.cfg.Cooldown),
+	}, nil
+}
+
+func (s *phoneVerificationService) VerifyOTP(ctx context.Context, userID uuid.UUID, input model.VerifyPhoneInput) (model.User, error) {
+	otp, err := s.otpRepo.FindActive(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.User{}, ErrOTPExpired
+		}
+		return model.User{}, err
+	}
+	// Jatah tebakan dipakai sebelum kode dibandingkan agar tebakan paralel tidak melebihi MaxAttempts
+	allowed, err := s.otpRepo.UseAttempt(ctx, otp.ID, s.cfg.MaxAttempts)
+	if err != nil {
+		return model.User{}, err
+	}
+	if !allowed {
+		return model.User{}, ErrOTPTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashOTPCode(otp.ID, input.Code)), []byte(otp.CodeHash)) != 1 {
+		return model.User{}, ErrInvalidOTP
+	}
+
+	consumed, err := s.otpRepo.Consume(ctx, otp.ID)
+	if err != nil {
+		return model.User{}, err
+	}
+	if !consumed {
+		return model.User{}, ErrOTPExpired
+	}
+
+	// Kode hanya berlaku untuk nomor tujuan pengirimannya
+	marked, err := s.userRepo.MarkPhoneVerified(ctx, userID, otp.PhoneNumber)
+	if err != nil {
+		return model.User{}, err
+	}
+	if !marked {
+		return model.User{}, ErrOTPExpired
+	}
+	return s.findUser(ctx, userID)
+}
+
+func (s *phoneVerificationService) findUser(ctx context.Context, userID uuid.UUID) (model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.User{}, ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// generateOTPCode membuat kode 6 digit acak
+func generateOTPCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashOTPCode memakai ID OTP sebagai salt agar hash kode yang sama berbeda di setiap pengiriman
+func hashOTPCode(otpID uuid.UUID, code string) string {
+	return auth.HashToken(otpID.String() + ":" + code)
+}
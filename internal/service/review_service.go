@@ -3,15 +3,23 @@ package service
 import (
 	"context"
 	"errors"
+	"sultra-otomotif-api/internal/apperror"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrReviewForbidden     = apperror.Forbidden("review_forbidden", "forbidden: you can only review your own bookings")
+	ErrBookingNotCompleted = apperror.Conflict("booking_not_completed", "you can only review a completed booking")
+	ErrReviewAlreadyExists = apperror.Conflict("review_already_exists", "a review for this booking already exists")
 )
 
 type ReviewService interface {
 	CreateReview(ctx context.Context, input model.CreateReviewInput, bookingID, userID uuid.UUID) (model.Review, error)
-	GetReviewsByVehicleID(ctx context.Context, vehicleID uuid.UUID) ([]model.Review, error)
+	GetReviewsByVehicleID(ctx context.Context, vehicleID uuid.UUID, page model.PageRequest) (model.Page[model.Review], error)
 }
 
 type reviewService struct {
@@ -27,21 +35,24 @@ func (s *reviewService) CreateReview(ctx context.Context, input model.CreateRevi
 	// 1. Ambil data booking untuk divalidasi
 	booking, err := s.bookingRepo.FindBookingByID(ctx, bookingID)
 	if err != nil {
-		return model.Review{}, errors.New("booking not found")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Review{}, ErrBookingNotFound
+		}
+		return model.Review{}, err
 	}
 
 	// 2. Validasi: Apakah user yang login adalah customer yang membuat booking?
 	if booking.UserID != userID {
-		return model.Review{}, errors.New("forbidden: you can only review your own bookings")
+		return model.Review{}, ErrReviewForbidden
 	}
 
 	// 3. Validasi: Apakah status booking sudah 'completed'?
 	if booking.Status != "completed" {
-		return model.Review{}, errors.New("you can only review a completed booking")
+		return model.Review{}, ErrBookingNotCompleted
 	}
 
 	// Karena ada UNIQUE constraint di DB, error akan otomatis muncul jika review sudah ada.
-	// Kita bisa menangani error spesifik dari Postgres (kode 23505) untuk pesan yang lebih baik.
+	// Error spesifik dari Postgres (kode 23505) diterjemahkan menjadi Conflict.
 
 	newReview := model.Review{
 		ID:        uuid.New(),
@@ -52,9 +63,16 @@ func (s *reviewService) CreateReview(ctx context.Context, input model.CreateRevi
 		Comment:   input.Comment,
 	}
 
-	return s.reviewRepo.Create(ctx, newReview)
+	review, err := s.reviewRepo.Create(ctx, newReview)
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return model.Review{}, ErrReviewAlreadyExists
+		}
+		return model.Review{}, err
+	}
+	return review, nil
 }
 
-func (s *reviewService) GetReviewsByVehicleID(ctx context.Context, vehicleID uuid.UUID) ([]model.Review, error) {
-	return s.reviewRepo.FindByVehicleID(ctx, vehicleID)
+func (s *reviewService) GetReviewsByVehicleID(ctx context.Context, vehicleID uuid.UUID, page model.PageRequest) (model.Page[model.Review], error) {
+	return s.reviewRepo.FindByVehicleID(ctx, vehicleID, page)
 }
@@ -3,17 +3,27 @@ package service
 import (
 	"context"
 	"errors"
+	"sultra-otomotif-api/internal/apperror"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrVehicleNotForSale        = apperror.Conflict("vehicle_not_for_sale", "this vehicle is not for sale")
+	ErrVehicleNoLongerAvailable = apperror.Conflict("vehicle_no_longer_available", "this vehicle is no longer available")
+	ErrCannotBuyOwnVehicle      = apperror.Conflict("cannot_buy_own_vehicle", "you cannot buy your own vehicle")
+	ErrSalePriceNotSet          = apperror.Conflict("sale_price_not_set", "sale price for this vehicle is not set")
+	ErrTransactionNotFound      = apperror.NotFound("transaction_not_found", "transaction not found")
 )
 
 type SalesService interface {
 	InitiatePurchase(ctx context.Context, vehicleID, buyerID uuid.UUID) (model.SalesTransaction, error)
 	ConfirmSale(ctx context.Context, transactionID uuid.UUID) error
-	GetPurchasesByBuyerID(ctx context.Context, buyerID uuid.UUID) ([]model.SalesTransaction, error)
-	GetSalesBySellerID(ctx context.Context, sellerID uuid.UUID) ([]model.SalesTransaction, error)
+	GetPurchasesByBuyerID(ctx context.Context, buyerID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error)
+	GetSalesBySellerID(ctx context.Context, sellerID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error)
 }
 
 type salesService struct {
@@ -28,23 +38,26 @@ func NewSalesService(salesRepo repository.SalesRepository, vehicleRepo repositor
 func (s *salesService) InitiatePurchase(ctx context.Context, vehicleID, buyerID uuid.UUID) (model.SalesTransaction, error) {
 	vehicle, err := s.vehicleRepo.FindByID(ctx, vehicleID)
 	if err != nil {
-		return model.SalesTransaction{}, errors.New("vehicle not found")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.SalesTransaction{}, ErrVehicleNotFound
+		}
+		return model.SalesTransaction{}, err
 	}
 
 	if !vehicle.IsForSale {
-		return model.SalesTransaction{}, errors.New("this vehicle is not for sale")
+		return model.SalesTransaction{}, ErrVehicleNotForSale
 	}
-	if vehicle.Status != "available" {
-		return model.SalesTransaction{}, errors.New("this vehicle is no longer available")
+	if !vehicle.IsListed() {
+		return model.SalesTransaction{}, ErrVehicleNoLongerAvailable
 	}
 	if vehicle.OwnerID == buyerID {
-		return model.SalesTransaction{}, errors.New("you cannot buy your own vehicle")
+		return model.SalesTransaction{}, ErrCannotBuyOwnVehicle
 	}
 
 	// PERBAIKAN DI SINI:
 	// Cek apakah harga jual tidak NULL sebelum digunakan
 	if vehicle.SalePrice == nil {
-		return model.SalesTransaction{}, errors.New("sale price for this vehicle is not set")
+		return model.SalesTransaction{}, ErrSalePriceNotSet
 	}
 	// Ambil nilai dari pointer
 	agreedPrice := *vehicle.SalePrice
@@ -64,6 +77,9 @@ func (s *salesService) InitiatePurchase(ctx context.Context, vehicleID, buyerID
 func (s *salesService) ConfirmSale(ctx context.Context, transactionID uuid.UUID) error {
 	transaction, err := s.salesRepo.UpdateStatus(ctx, transactionID, "completed")
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTransactionNotFound
+		}
 		return err
 	}
 
@@ -72,18 +88,27 @@ func (s *salesService) ConfirmSale(ctx context.Context, transactionID uuid.UUID)
 		return err
 	}
 
-	vehicle.Status = "sold"
 	vehicle.IsForSale = false
 	vehicle.IsForRent = false
+	if _, err := s.vehicleRepo.Update(ctx, vehicle); err != nil {
+		return err
+	}
+	if vehicle.Status == "sold" {
+		return nil
+	}
 
-	_, err = s.vehicleRepo.Update(ctx, vehicle)
+	// Kendaraan yang terjual keluar dari state machine listing dan tidak bisa dipublikasikan lagi
+	fromStatus := vehicle.Status
+	vehicle.Status = "sold"
+	vehicle.MaintenanceStart, vehicle.MaintenanceEnd = nil, nil
+	_, err = s.vehicleRepo.UpdateStatus(ctx, vehicle, fromStatus)
 	return err
 }
 
-func (s *salesService) GetPurchasesByBuyerID(ctx context.Context, buyerID uuid.UUID) ([]model.SalesTransaction, error) {
-	return s.salesRepo.FindByBuyerID(ctx, buyerID)
+func (s *salesService) GetPurchasesByBuyerID(ctx context.Context, buyerID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error) {
+	return s.salesRepo.FindByBuyerID(ctx, buyerID, page)
 }
 
-func (s *salesService) GetSalesBySellerID(ctx context.Context, sellerID uuid.UUID) ([]model.SalesTransaction, error) {
-	return s.salesRepo.FindBySellerID(ctx, sellerID)
+func (s *salesService) GetSalesBySellerID(ctx context.Context, sellerID uuid.UUID, page model.PageRequest) (model.Page[model.SalesTransaction], error) {
+	return s.salesRepo.FindBySellerID(ctx, sellerID, page)
 }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Key pengaturan di tabel platform_settings
+const settingRequire2FAForAdmin = "require_2fa_for_admin"
+
+var ErrRequire2FAUnavailable = apperror.Validation("two_factor_unavailable", "two-factor authentication cannot be required because it is not configured on this server").
+	WithField("require_2fa_for_admin", "TOTP_ENCRYPTION_KEY is not set")
+
+type SettingsService interface {
+	GetSettings(ctx context.Context) (model.PlatformSettings, error)
+	UpdateSettings(ctx context.Context, input model.UpdatePlatformSettingsInput, adminID uuid.UUID) (model.PlatformSettings, error)
+}
+
+type settingsService struct {
+	repo repository.PlatformSettingRepository
+	// twoFactorAvailable false jika TOTP_ENCRYPTION_KEY tidak diisi; 2FA tidak boleh diwajibkan
+	// karena admin tidak akan bisa mendaftar dan semuanya terkunci.
+	twoFactorAvailable bool
+}
+
+func NewSettingsService(repo repository.PlatformSettingRepository, twoFactorAvailable bool) SettingsService {
+	return &settingsService{repo: repo, twoFactorAvailable: twoFactorAvailable}
+}
+
+func (s *settingsService) GetSettings(ctx context.Context) (model.PlatformSettings, error) {
+	values, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return model.PlatformSettings{}, err
+	}
+	require2FA, _ := strconv.ParseBool(values[settingRequire2FAForAdmin])
+	return model.PlatformSettings{Require2FAForAdmin: require2FA}, nil
+}
+
+func (s *settingsService) UpdateSettings(ctx context.Context, input model.UpdatePlatformSettingsInput, adminID uuid.UUID) (model.PlatformSettings, error) {
+	if input.Require2FAForAdmin != nil {
+		if *input.Require2FAForAdmin && !s.twoFactorAvailable {
+			return model.PlatformSettings{}, ErrRequire2FAUnavailable
+		}
+		if err := s.repo.Set(ctx, settingRequire2FAForAdmin, strconv.FormatBool(*input.Require2FAForAdmin), adminID); err != nil {
+			return model.PlatformSettings{}, err
+		}
+	}
+	return s.GetSettings(ctx)
+}
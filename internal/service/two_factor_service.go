@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/auth"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrTwoFactorUnavailable    = apperror.New(apperror.KindInternal, "two_factor_unavailable", "two-factor authentication is not configured on this server")
+	ErrTwoFactorAlreadyEnabled = apperror.Conflict("two_factor_already_enabled", "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = apperror.Validation("two_factor_not_enrolled", "start two-factor enrollment first")
+	ErrTwoFactorNotEnabled     = apperror.Validation("two_factor_not_enabled", "two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode    = apperror.Validation("invalid_2fa_code", "invalid or already used two-factor code")
+	ErrTwoFactorRequired       = apperror.Forbidden("two_factor_required", "two-factor authentication is required for this account and cannot be disabled")
+)
+
+const (
+	backupCodeCount    = 10
+	backupCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // tanpa I, O, 0, 1 agar tidak tertukar saat diketik
+)
+
+// TwoFactorService mengelola 2FA berbasis TOTP dan login dua langkah
+type TwoFactorService interface {
+	CompleteLogin(ctx context.Context, user model.User, meta model.RequestMeta) (model.LoginResponse, error)
+	Status(ctx context.Context, userID uuid.UUID) (model.TwoFactorStatus, error)
+	Enroll(ctx context.Context, userID uuid.UUID) (model.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userID uuid.UUID, code string, issueTokens bool, meta model.RequestMeta) (model.TwoFactorConfirmation, error)
+	Verify(ctx context.Context, input model.TwoFactorVerifyInput, meta model.RequestMeta) (model.AuthResponse, error)
+	Disable(ctx context.Context, userID uuid.UUID, input model.TwoFactorDisableInput) error
+	RegenerateBackupCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+}
+
+type twoFactorService struct {
+	repo        repository.TwoFactorRepository
+	userRepo    repository.UserRepository
+	settings    SettingsService
+	authService AuthService
+	throttle    LoginThrottleService
+	box         *auth.SecretBox // nil jika TOTP_ENCRYPTION_KEY tidak diisi
+	issuer      string
+}
+
+func NewTwoFactorService(repo repository.TwoFactorRepository, userRepo repository.UserRepository, settings SettingsService, authService AuthService, throttle LoginThrottleService, box *auth.SecretBox, issuer string) TwoFactorService {
+	return &twoFactorService{
+		repo:        repo,
+		userRepo:    userRepo,
+		settings:    settings,
+		authService: authService,
+		throttle:    throttle,
+		box:         box,
+		issuer:      issuer,
+	}
+}
+
+// CompleteLogin dipanggil setelah password benar. Jika 2FA aktif (atau wajib tapi belum didaftarkan),
+// yang dikembalikan hanya token tantangan; access token baru terbit setelah langkah 2FA selesai.
+func (s *twoFactorService) CompleteLogin(ctx context.Context, user model.User, meta model.RequestMeta) (model.LoginResponse, error) {
+	enabled, err := s.isEnabled(ctx, user.ID)
+	if err != nil {
+		return model.LoginResponse{}, err
+	}
+
+	purpose := ""
+	if enabled {
+		purpose = ChallengePurposeMFAVerify
+	} else {
+		required, err := s.isRequired(ctx, user)
+		if err != nil {
+			return model.LoginResponse{}, err
+		}
+		if required {
+			purpose = ChallengePurposeMFAEnroll
+		}
+	}
+
+	if purpose == "" {
+		tokens, err := s.authService.IssueTokens(ctx, user, meta)
+		if err != nil {
+			return model.LoginResponse{}, err
+		}
+		return model.LoginResponse{AuthResponse: tokens}, nil
+	}
+
+	challenge, expiresAt, err := s.authService.IssueChallenge(user, purpose)
+	if err != nil {
+		return model.LoginResponse{}, err
+	}
+	return model.LoginResponse{
+		MFARequired:           true,
+		MFAEnrollmentRequired: purpose == ChallengePurposeMFAEnroll,
+		ChallengeToken:        challenge,
+		ChallengeExpiresAt:    &expiresAt,
+	}, nil
+}
+
+func (s *twoFactorService) Status(ctx context.Context, userID uuid.UUID) (model.TwoFactorStatus, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return model.TwoFactorStatus{}, err
+	}
+	required, err := s.isRequired(ctx, user)
+	if err != nil {
+		return model.TwoFactorStatus{}, err
+	}
+
+	status := model.TwoFactorStatus{Required: required}
+	tf, err := s.repo.FindByUserID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && tf.EnabledAt == nil) {
+		return status, nil
+	}
+	if err != nil {
+		return model.TwoFactorStatus{}, err
+	}
+
+	status.Enabled = true
+	status.EnabledAt = tf.EnabledAt
+	status.BackupCodesRemaining, err = s.repo.CountBackupCodes(ctx, userID)
+	return status, err
+}
+
+// Enroll membuat secret TOTP baru yang belum aktif sampai dikonfirmasi dengan kode pertama
+func (s *twoFactorService) Enroll(ctx context.Context, userID uuid.UUID) (model.TwoFactorEnrollment, error) {
+	if s.box == nil {
+		return model.TwoFactorEnrollment{}, ErrTwoFactorUnavailable
+	}
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+	enabled, err := s.isEnabled(ctx, userID)
+	if err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+	if enabled {
+		return model.TwoFactorEnrollment{}, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+	if err := s.repo.UpsertPending(ctx, userID, sealed); err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+
+	return model.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm mengaktifkan 2FA setelah user membuktikan authenticator-nya menghasilkan kode yang benar.
+// issueTokens diisi true jika pendaftaran terjadi di tengah login yang mewajibkan 2FA. Seperti Verify,
+// kode yang salah dihitung oleh proteksi brute-force login.
+func (s *twoFactorService) Confirm(ctx context.Context, userID uuid.UUID, code string, issueTokens bool, meta model.RequestMeta) (model.TwoFactorConfirmation, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return model.TwoFactorConfirmation{}, err
+	}
+	tf, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.TwoFactorConfirmation{}, ErrTwoFactorNotEnrolled
+		}
+		return model.TwoFactorConfirmation{}, err
+	}
+	if tf.EnabledAt != nil {
+		return model.TwoFactorConfirmation{}, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := s.openSecret(tf)
+	if err != nil {
+		return model.TwoFactorConfirmation{}, err
+	}
+	if err := s.throttle.Check(ctx, user.Email, meta); err != nil {
+		return model.TwoFactorConfirmation{}, err
+	}
+	step, ok := auth.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		if err := s.throttle.RecordFailure(ctx, user.Email, meta); err != nil {
+			return model.TwoFactorConfirmation{}, err
+		}
+		return model.TwoFactorConfirmation{}, ErrInvalidTwoFactorCode
+	}
+	if err := s.throttle.RecordSuccess(ctx, user.Email); err != nil {
+		return model.TwoFactorConfirmation{}, err
+	}
+
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return model.TwoFactorConfirmation{}, err
+	}
+	if err := s.repo.Enable(ctx, userID, step, hashes); err != nil {
+		return model.TwoFactorConfirmation{}, err
+	}
+
+	result := model.TwoFactorConfirmation{BackupCodes: codes}
+	if issueTokens {
+		tokens, err := s.authService.IssueTokens(ctx, user, meta)
+		if err != nil {
+			return model.TwoFactorConfirmation{}, err
+		}
+		result.Auth = &tokens
+	}
+	return result, nil
+}
+
+// Verify menyelesaikan login dua langkah. Kode yang salah dihitung oleh proteksi brute-force login.
+func (s *twoFactorService) Verify(ctx context.Context, input model.TwoFactorVerifyInput, meta model.RequestMeta) (model.AuthResponse, error) {
+	claims, err := s.authService.ParseChallenge(input.ChallengeToken, ChallengePurposeMFAVerify)
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.AuthResponse{}, ErrInvalidChallenge
+		}
+		return model.AuthResponse{}, err
+	}
+
+	if err := s.throttle.Check(ctx, user.Email, meta); err != nil {
+		return model.AuthResponse{}, err
+	}
+	valid, err := s.checkCode(ctx, user.ID, input.Code)
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+	if !valid {
+		if err := s.throttle.RecordFailure(ctx, user.Email, meta); err != nil {
+			return model.AuthResponse{}, err
+		}
+		return model.AuthResponse{}, ErrInvalidTwoFactorCode
+	}
+	if err := s.throttle.RecordSuccess(ctx, user.Email); err != nil {
+		return model.AuthResponse{}, err
+	}
+
+	// Token tantangan hanya berlaku sekali; dicatat setelah kode benar agar salah ketik tidak menghanguskannya
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return model.AuthResponse{}, ErrInvalidChallenge.Wrap(err)
+	}
+	consumed, err := s.repo.ConsumeChallenge(ctx, jti, user.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return model.AuthResponse{}, err
+	}
+	if !consumed {
+		return model.AuthResponse{}, ErrInvalidChallenge
+	}
+
+	return s.authService.IssueTokens(ctx, user, meta)
+}
+
+// Disable mematikan 2FA. Akun yang hanya login lewat OIDC tidak punya password, sehingga
+// cukup dibuktikan dengan kode TOTP atau kode cadangan yang memang selalu diperiksa.
+func (s *twoFactorService) Disable(ctx context.Context, userID uuid.UUID, input model.TwoFactorDisableInput) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash != "" && !helper.CheckPasswordHash(input.Password, user.PasswordHash) {
+		return ErrCurrentPasswordInvalid.WithField("password", "incorrect password")
+	}
+	required, err := s.isRequired(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	valid, err := s.checkCode(ctx, userID, input.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return ErrInvalidTwoFactorCode
+	}
+	return s.repo.Delete(ctx, userID)
+}
+
+// RegenerateBackupCodes mengganti semua kode cadangan; kode lama langsung tidak berlaku
+func (s *twoFactorService) RegenerateBackupCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	valid, err := s.checkCode(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceBackupCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkCode menerima kode TOTP 6 digit atau kode cadangan. Keduanya hanya bisa dipakai sekali.
+func (s *twoFactorService) checkCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	tf, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrTwoFactorNotEnabled
+		}
+		return false, err
+	}
+	if tf.EnabledAt == nil {
+		return false, ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == 6 && strings.Trim(code, "0123456789") == "" {
+		secret, err := s.openSecret(tf)
+		if err != nil {
+			return false, err
+		}
+		step, ok := auth.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.repo.MarkStepUsed(ctx, userID, step)
+	}
+
+	return s.repo.UseBackupCode(ctx, userID, hashBackupCode(code))
+}
+
+func (s *twoFactorService) openSecret(tf model.TwoFactor) (string, error) {
+	if s.box == nil {
+		return "", ErrTwoFactorUnavailable
+	}
+	secret, err := s.box.Open(tf.SecretEncrypted)
+	if err != nil {
+		return "", apperror.Internal("two_factor_secret_unreadable", "failed to decrypt two-factor secret", err)
+	}
+	return secret, nil
+}
+
+func (s *twoFactorService) isEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	tf, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return tf.EnabledAt != nil, nil
+}
+
+// isRequired mengecek pengaturan platform yang mewajibkan 2FA untuk admin
+func (s *twoFactorService) isRequired(ctx context.Context, user model.User) (bool, error) {
+	if user.Role != "admin" {
+		return false, nil
+	}
+	settings, err := s.settings.GetSettings(ctx)
+	if err != nil {
+		return false, err
+	}
+	return settings.Require2FAForAdmin, nil
+}
+
+func (s *twoFactorService) findUser(ctx context.Context, userID uuid.UUID) (model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.User{}, ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// generateBackupCodes membuat kode cadangan berformat XXXX-XXXX beserta hash-nya
+func generateBackupCodes() ([]string, []string, error) {
+	codes := make([]string, backupCodeCount)
+	hashes := make([]string, backupCodeCount)
+	buf := make([]byte, 8)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		var b strings.Builder
+		for j, v := range buf {
+			if j == 4 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(backupCodeAlphabet[int(v)%len(backupCodeAlphabet)])
+		}
+		codes[i] = b.String()
+		hashes[i] = hashBackupCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashBackupCode menormalkan kode (huruf besar, tanpa spasi/tanda hubung) sebelum di-hash
+func hashBackupCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return auth.HashToken(normalized)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/auth"
+	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type twoFactorTestEnv struct {
+	service TwoFactorService
+	repo    *fakeTwoFactorRepo
+	box     *auth.SecretBox
+}
+
+func newTwoFactorTestEnv(t *testing.T, users ...model.User) *twoFactorTestEnv {
+	t.Helper()
+	box, err := auth.NewSecretBox("test-totp-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &twoFactorTestEnv{repo: newFakeTwoFactorRepo(), box: box}
+	env.service = NewTwoFactorService(env.repo, newFakeUserRepo(users...), fakeSettings{}, nil, newTestThrottle(), box, "Sultra Otomotif")
+	return env
+}
+
+// addTwoFactor menyimpan secret TOTP untuk user; enabled false berarti pendaftaran belum dikonfirmasi
+func (env *twoFactorTestEnv) addTwoFactor(t *testing.T, userID uuid.UUID, enabled bool, backupCodes ...string) {
+	t.Helper()
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := env.box.Seal(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf := model.TwoFactor{UserID: userID, SecretEncrypted: sealed}
+	if enabled {
+		now := time.Now()
+		tf.EnabledAt = &now
+	}
+	env.repo.records[userID] = tf
+	for _, code := range backupCodes {
+		env.repo.backupCodes[userID] = append(env.repo.backupCodes[userID], hashBackupCode(code))
+	}
+}
+
+func TestConfirmCountsWrongCodesAsFailedLogins(t *testing.T) {
+	user := model.User{ID: uuid.New(), Email: "vendor@example.com", Role: "vendor"}
+	env := newTwoFactorTestEnv(t, user)
+	env.addTwoFactor(t, user.ID, false)
+	meta := model.RequestMeta{IPAddress: "10.0.0.1"}
+
+	// newTestThrottle mulai menahan percobaan berikutnya setelah 3 kali gagal
+	for i := 0; i < 3; i++ {
+		_, err := env.service.Confirm(context.Background(), user.ID, "000000", false, meta)
+		assertErrorCode(t, err, ErrInvalidTwoFactorCode)
+	}
+	_, err := env.service.Confirm(context.Background(), user.ID, "000000", false, meta)
+	assertErrorCode(t, err, ErrTooManyLoginAttempts)
+}
+
+func TestDisable(t *testing.T) {
+	hash, err := helper.HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		passwordHash string
+		password     string
+		code         string
+		wantErr      *apperror.Error
+	}{
+		{"password and backup code", hash, "password123", "ABCD-EFGH", nil},
+		{"wrong password", hash, "wrong", "ABCD-EFGH", ErrCurrentPasswordInvalid},
+		{"missing password", hash, "", "ABCD-EFGH", ErrCurrentPasswordInvalid},
+		{"account without password and backup code", "", "", "ABCD-EFGH", nil},
+		{"account without password and wrong code", "", "", "ZZZZ-ZZZZ", ErrInvalidTwoFactorCode},
+		{"account without password and wrong totp", "", "", "000000", ErrInvalidTwoFactorCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := model.User{ID: uuid.New(), Email: "vendor@example.com", Role: "vendor", PasswordHash: tt.passwordHash}
+			env := newTwoFactorTestEnv(t, user)
+			env.addTwoFactor(t, user.ID, true, "ABCD-EFGH")
+
+			err := env.service.Disable(context.Background(), user.ID, model.TwoFactorDisableInput{Password: tt.password, Code: tt.code})
+			_, stillEnabled := env.repo.records[user.ID]
+			if tt.wantErr != nil {
+				assertErrorCode(t, err, tt.wantErr)
+				if !stillEnabled {
+					t.Error("2FA was disabled despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Disable: %v", err)
+			}
+			if stillEnabled {
+				t.Error("2FA is still enabled")
+			}
+		})
+	}
+}
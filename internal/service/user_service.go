@@ -3,39 +3,83 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"sultra-otomotif-api/internal/apperror"
 	"sultra-otomotif-api/internal/auth"
 	"sultra-otomotif-api/internal/helper"
+	"sultra-otomotif-api/internal/mailer"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/repository"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
+var (
+	ErrEmailAlreadyRegistered = apperror.Conflict("email_already_registered", "email already registered")
+	ErrInvalidCredentials     = apperror.Unauthorized("invalid_credentials", "invalid email or password")
+	ErrUserNotFound           = apperror.NotFound("user_not_found", "user not found")
+	ErrInvalidResetToken      = apperror.Validation("invalid_reset_token", "invalid, used or expired password reset token")
+	ErrInvalidVerifyToken     = apperror.Validation("invalid_verification_token", "invalid, used or expired email verification token")
+	ErrEmailAlreadyVerified   = apperror.Conflict("email_already_verified", "email is already verified")
+	ErrNoProfileChanges       = apperror.Validation("no_profile_changes", "no profile fields to update")
+	ErrCurrentPasswordInvalid = apperror.Validation("invalid_current_password", "current password is missing or incorrect")
+	ErrInvalidPhoneNumber     = apperror.Validation("invalid_phone_number", "invalid phone number").WithField("phone_number", "use a valid number, e.g. 081234567890 or +6281234567890")
+	ErrVendorOnlySetting      = apperror.Validation("vendor_only_setting", "only vendors can change this setting").WithField("require_verified_phone", "only available for vendors")
+)
+
+// Masa berlaku token sekali pakai
+const (
+	passwordResetTokenTTL     = 1 * time.Hour
+	emailVerificationTokenTTL = 48 * time.Hour
+	emailDeliveryTimeout      = 30 * time.Second
+)
+
 type UserService interface {
 	RegisterUser(ctx context.Context, input model.RegisterUserInput) (model.User, error)
-	LoginUser(ctx context.Context, input model.LoginUserInput, jwtSecret string) (string, error)
+	LoginUser(ctx context.Context, input model.LoginUserInput, meta model.RequestMeta) (model.LoginResponse, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (model.User, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, input model.UpdateProfileInput) (model.User, error)
+	RequestPasswordReset(ctx context.Context, input model.ForgotPasswordInput, meta model.RequestMeta) error
+	ResetPassword(ctx context.Context, input model.ResetPasswordInput) error
+	SendEmailVerification(ctx context.Context, userID uuid.UUID, meta model.RequestMeta) error
+	VerifyEmail(ctx context.Context, input model.VerifyEmailInput) error
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo        repository.UserRepository
+	tokenRepo   repository.UserTokenRepository
+	authService AuthService
+	twoFactor   TwoFactorService
+	throttle    LoginThrottleService
+	mailer      mailer.Mailer
+	frontendURL string
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+// frontendURL dipakai untuk membangun link di email (halaman reset password & verifikasi email)
+func NewUserService(repo repository.UserRepository, tokenRepo repository.UserTokenRepository, authService AuthService, twoFactor TwoFactorService, throttle LoginThrottleService, mailer mailer.Mailer, frontendURL string) UserService {
+	return &userService{repo: repo, tokenRepo: tokenRepo, authService: authService, twoFactor: twoFactor, throttle: throttle, mailer: mailer, frontendURL: frontendURL}
 }
 
 func (s *userService) RegisterUser(ctx context.Context, input model.RegisterUserInput) (model.User, error) {
+	email := helper.NormalizeEmail(input.Email)
+
 	// Cek apakah email sudah ada
-	_, err := s.repo.FindByEmail(ctx, input.Email)
+	_, err := s.repo.FindByEmail(ctx, email)
 	if err == nil { // Jika tidak ada error, berarti user ditemukan
-		return model.User{}, errors.New("email already registered")
+		return model.User{}, ErrEmailAlreadyRegistered
 	}
 	if !errors.Is(err, pgx.ErrNoRows) { // Jika errornya bukan karena tidak ada baris
 		return model.User{}, err
 	}
 
+	phoneNumber, err := helper.NormalizePhoneNumber(input.PhoneNumber)
+	if err != nil {
+		return model.User{}, ErrInvalidPhoneNumber
+	}
+
 	passwordHash, err := helper.HashPassword(input.Password)
 	if err != nil {
 		return model.User{}, err
@@ -44,42 +88,314 @@ func (s *userService) RegisterUser(ctx context.Context, input model.RegisterUser
 	newUser := model.User{
 		ID:           uuid.New(),
 		FullName:     input.FullName,
-		Email:        input.Email,
+		Email:        email,
 		PasswordHash: passwordHash,
-		PhoneNumber:  input.PhoneNumber,
+		PhoneNumber:  phoneNumber,
 		Role:         input.Role,
 	}
 
 	createdUser, err := s.repo.Save(ctx, newUser)
 	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return model.User{}, ErrEmailAlreadyRegistered
+		}
 		return model.User{}, err
 	}
 
+	// Gagal mengirim email verifikasi tidak menggagalkan registrasi; user bisa minta kirim ulang
+	s.deliverEmail("verification", func(ctx context.Context) error {
+		return s.sendVerificationEmail(ctx, createdUser)
+	})
+
 	return createdUser, nil
 }
 
-func (s *userService) LoginUser(ctx context.Context, input model.LoginUserInput, jwtSecret string) (string, error) {
-	user, err := s.repo.FindByEmail(ctx, input.Email)
+func (s *userService) LoginUser(ctx context.Context, input model.LoginUserInput, meta model.RequestMeta) (model.LoginResponse, error) {
+	// Akun atau IP yang sedang diblokir ditolak sebelum password diperiksa
+	if err := s.throttle.Check(ctx, input.Email, meta); err != nil {
+		return model.LoginResponse{}, err
+	}
+
+	user, err := s.repo.FindByEmail(ctx, helper.NormalizeEmail(input.Email))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", errors.New("invalid email or password")
+			// Email yang tidak terdaftar tetap dihitung agar respons tidak membedakan akun yang ada
+			return model.LoginResponse{}, s.loginFailed(ctx, input.Email, meta)
 		}
-		return "", err
+		return model.LoginResponse{}, err
 	}
 
 	isValidPassword := helper.CheckPasswordHash(input.Password, user.PasswordHash)
 	if !isValidPassword {
-		return "", errors.New("invalid email or password")
+		return model.LoginResponse{}, s.loginFailed(ctx, input.Email, meta)
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Role, jwtSecret)
-	if err != nil {
-		return "", err
+	if err := s.throttle.RecordSuccess(ctx, input.Email); err != nil {
+		return model.LoginResponse{}, err
 	}
+	// Jika 2FA aktif, token baru terbit setelah kode diverifikasi di /auth/2fa/verify
+	return s.twoFactor.CompleteLogin(ctx, user, meta)
+}
 
-	return token, nil
+func (s *userService) loginFailed(ctx context.Context, email string, meta model.RequestMeta) error {
+	if err := s.throttle.RecordFailure(ctx, email, meta); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
 }
 
 func (s *userService) GetUserByID(ctx context.Context, userID uuid.UUID) (model.User, error) {
-	return s.repo.FindByID(ctx, userID)
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.User{}, ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// UpdateProfile mengubah profil user yang sedang login. Ganti email mereset status verifikasi email
+// dan mengirim link verifikasi ke alamat baru; ganti password mencabut sesi lain selain sesi ini.
+func (s *userService) UpdateProfile(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, input model.UpdateProfileInput) (model.User, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	changed := false
+	if input.FullName != nil && *input.FullName != user.FullName {
+		user.FullName = *input.FullName
+		changed = true
+	}
+	if input.PhoneNumber != nil {
+		phoneNumber, err := helper.NormalizePhoneNumber(*input.PhoneNumber)
+		if err != nil {
+			return model.User{}, ErrInvalidPhoneNumber
+		}
+		// Nomor baru harus diverifikasi ulang lewat OTP
+		if phoneNumber != user.PhoneNumber {
+			user.PhoneNumber = phoneNumber
+			user.PhoneVerifiedAt = nil
+			changed = true
+		}
+	}
+	if input.RequireVerifiedPhone != nil && *input.RequireVerifiedPhone != user.RequireVerifiedPhone {
+		if user.Role != "vendor" {
+			return model.User{}, ErrVendorOnlySetting
+		}
+		user.RequireVerifiedPhone = *input.RequireVerifiedPhone
+		changed = true
+	}
+
+	var newEmail string
+	if input.Email != nil {
+		newEmail = helper.NormalizeEmail(*input.Email)
+	}
+	emailChanged := input.Email != nil && newEmail != user.Email
+	passwordChanged := input.NewPassword != nil
+	if emailChanged || passwordChanged {
+		if input.CurrentPassword == "" || !helper.CheckPasswordHash(input.CurrentPassword, user.PasswordHash) {
+			return model.User{}, ErrCurrentPasswordInvalid.WithField("current_password", "required to change email or password")
+		}
+	}
+
+	if emailChanged {
+		existing, err := s.repo.FindByEmail(ctx, newEmail)
+		if err == nil && existing.ID != user.ID {
+			return model.User{}, ErrEmailAlreadyRegistered
+		}
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return model.User{}, err
+		}
+		user.Email = newEmail
+		user.EmailVerifiedAt = nil
+		changed = true
+	}
+	if passwordChanged {
+		passwordHash, err := helper.HashPassword(*input.NewPassword)
+		if err != nil {
+			return model.User{}, err
+		}
+		user.PasswordHash = passwordHash
+		changed = true
+	}
+
+	if !changed {
+		return model.User{}, ErrNoProfileChanges
+	}
+
+	updatedUser, err := s.repo.UpdateProfile(ctx, user)
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return model.User{}, ErrEmailAlreadyRegistered
+		}
+		return model.User{}, err
+	}
+
+	if passwordChanged {
+		if err := s.authService.RevokeOtherSessions(ctx, user.ID, sessionID, RevokeReasonPasswordChange); err != nil {
+			return model.User{}, err
+		}
+	}
+	if emailChanged {
+		s.deliverEmail("verification", func(ctx context.Context) error {
+			return s.sendVerificationEmail(ctx, updatedUser)
+		})
+	}
+
+	return updatedUser, nil
+}
+
+// RequestPasswordReset mengirim link reset password di background. Respons tidak bergantung pada
+// apakah email terdaftar atau mailer berhasil, agar endpoint ini tidak bisa dipakai untuk menebak
+// email yang terdaftar.
+func (s *userService) RequestPasswordReset(ctx context.Context, input model.ForgotPasswordInput, meta model.RequestMeta) error {
+	if err := s.throttle.LimitEmailRequest(ctx, model.ThrottleActionPasswordReset, input.Email, meta); err != nil {
+		return err
+	}
+
+	s.deliverEmail("password reset", func(ctx context.Context) error {
+		user, err := s.repo.FindByEmail(ctx, helper.NormalizeEmail(input.Email))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		token, err := s.issueUserToken(ctx, user, model.TokenPurposePasswordReset, passwordResetTokenTTL)
+		if err != nil {
+			return err
+		}
+
+		return s.mailer.Send(ctx, mailer.Message{
+			To:      user.Email,
+			Subject: "Reset password akun Sultra Otomotif",
+			Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan untuk mereset password akun Anda. "+
+				"Buka link berikut untuk membuat password baru (berlaku %d menit):\n\n%s/reset-password?token=%s\n\n"+
+				"Abaikan email ini jika Anda tidak merasa memintanya.",
+				user.FullName, int(passwordResetTokenTTL.Minutes()), s.frontendURL, token),
+		})
+	})
+	return nil
+}
+
+// ResetPassword mengganti password dengan token dari email, lalu mencabut semua sesi user
+// sehingga siapa pun yang memegang token lama harus login ulang.
+func (s *userService) ResetPassword(ctx context.Context, input model.ResetPasswordInput) error {
+	token, err := s.tokenRepo.Consume(ctx, auth.HashToken(input.Token), model.TokenPurposePasswordReset)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	passwordHash, err := helper.HashPassword(input.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, token.UserID, passwordHash); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.InvalidateAll(ctx, token.UserID, model.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+	// Link reset hanya bisa dibuka dari inbox, jadi sekaligus membuktikan kepemilikan email
+	if _, err := s.repo.MarkEmailVerified(ctx, token.UserID, token.Email); err != nil {
+		return err
+	}
+
+	return s.authService.RevokeUserSessions(ctx, token.UserID, RevokeReasonPasswordReset)
+}
+
+// SendEmailVerification mengirim ulang email verifikasi di background; gagal kirim hanya dicatat di log
+func (s *userService) SendEmailVerification(ctx context.Context, userID uuid.UUID, meta model.RequestMeta) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	if err := s.throttle.LimitEmailRequest(ctx, model.ThrottleActionEmailVerification, user.Email, meta); err != nil {
+		return err
+	}
+
+	s.deliverEmail("verification", func(ctx context.Context) error {
+		return s.sendVerificationEmail(ctx, user)
+	})
+	return nil
+}
+
+func (s *userService) VerifyEmail(ctx context.Context, input model.VerifyEmailInput) error {
+	token, err := s.tokenRepo.Consume(ctx, auth.HashToken(input.Token), model.TokenPurposeEmailVerification)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidVerifyToken
+		}
+		return err
+	}
+
+	verified, err := s.repo.MarkEmailVerified(ctx, token.UserID, token.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		// Email user sudah diganti setelah token ini diterbitkan
+		return ErrInvalidVerifyToken
+	}
+	return nil
+}
+
+func (s *userService) sendVerificationEmail(ctx context.Context, user model.User) error {
+	token, err := s.issueUserToken(ctx, user, model.TokenPurposeEmailVerification, emailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email akun Sultra Otomotif",
+		Body: fmt.Sprintf("Halo %s,\n\nSilakan konfirmasi alamat email Anda dengan membuka link berikut (berlaku %d jam):\n\n%s/verify-email?token=%s",
+			user.FullName, int(emailVerificationTokenTTL.Hours()), s.frontendURL, token),
+	})
+}
+
+// deliverEmail menjalankan send di goroutine terpisah dengan context baru, karena context request
+// sudah selesai (dan dipakai ulang oleh gin) saat email dikirim. Kegagalan hanya dicatat di log.
+func (s *userService) deliverEmail(kind string, send func(ctx context.Context) error) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), emailDeliveryTimeout)
+		defer cancel()
+		if err := send(ctx); err != nil {
+			log.Printf("Warning: failed to send %s email: %v", kind, err)
+		}
+	}()
+}
+
+// issueUserToken membuat token sekali pakai baru dan membatalkan token sebelumnya dengan tujuan yang sama,
+// sehingga hanya link di email terakhir yang berlaku.
+func (s *userService) issueUserToken(ctx context.Context, user model.User, purpose string, ttl time.Duration) (string, error) {
+	if err := s.tokenRepo.InvalidateAll(ctx, user.ID, purpose); err != nil {
+		return "", err
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	err = s.tokenRepo.Create(ctx, model.UserToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
 }
@@ -1,16 +1,70 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"log"
 	"mime/multipart"
-	"sultra-otomotif-api/internal/config"
+	"slices"
+	"strings"
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/authz"
+	"sultra-otomotif-api/internal/geocode"
+	"sultra-otomotif-api/internal/imaging"
 	"sultra-otomotif-api/internal/model"
 	"sultra-otomotif-api/internal/repository"
+	"sultra-otomotif-api/internal/storage"
+	"sync"
+	"time"
+	"unicode"
 
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrVehicleNotFound   = apperror.NotFound("vehicle_not_found", "vehicle not found")
+	ErrOwnerNotFound     = apperror.NotFound("owner_not_found", "owner not found")
+	ErrVendorNotVerified = apperror.Forbidden("vendor_not_verified", "forbidden: vendor account is not verified")
+	ErrNotVehicleOwner   = apperror.Forbidden("not_vehicle_owner", "forbidden: you are not the owner of this vehicle")
+	ErrPlateNumberTaken  = apperror.Conflict("plate_number_taken", "a vehicle with this plate number already exists")
+	ErrDistanceOrigin    = apperror.Validation("distance_origin_required", "lat and lng are required to sort by distance")
+	ErrCursorSortChanged = apperror.Validation("cursor_sort_mismatch", "cursor belongs to a different sort order").WithField("cursor", "request the first page again after changing sort, q, lat/lng or is_for_rent")
+	ErrImageNotFound     = apperror.NotFound("image_not_found", "image not found")
+	ErrInvalidImageOrder = apperror.Validation("invalid_image_order", "image_ids must list every image of the vehicle exactly once")
+	ErrImageType         = apperror.Validation("image_unsupported_type", "only JPEG, PNG and WebP images are accepted")
+	ErrImageTooLarge     = apperror.Validation("image_too_large", "image file is too large")
+	ErrImageDimensions   = apperror.Validation("image_dimensions_too_large", "image dimensions are too large")
+	ErrImageInvalid      = apperror.Validation("image_invalid", "image file is invalid or corrupt")
+	ErrNoImages          = apperror.Validation("images_required", "at least one image file is required")
+	ErrTooManyImages     = apperror.Validation("too_many_images", fmt.Sprintf("at most %d images can be uploaded at once", maxImagesPerUpload))
+
+	ErrVehicleSold              = apperror.Conflict("vehicle_sold", "cannot change status of a sold vehicle")
+	ErrInvalidVehicleTransition = apperror.Conflict("invalid_vehicle_status_transition", "invalid vehicle status transition")
+	ErrVehicleStatusChanged     = apperror.Conflict("vehicle_status_changed", "vehicle status was changed by another request, please retry")
+	ErrInvalidMaintenanceWindow = apperror.Validation("invalid_maintenance_window", "invalid maintenance window")
+	ErrMaintenanceBooked        = apperror.Conflict("maintenance_booking_conflict", "vehicle has pending or active bookings during the maintenance window")
+)
+
+// vehicleStatusTransitions adalah perpindahan status listing yang boleh dilakukan pemilik atau admin.
+// maintenance -> maintenance dipakai untuk menjadwal ulang jendela maintenance. Status sold hanya diset
+// oleh SalesService.ConfirmSale dan tidak bisa diubah lagi.
+var vehicleStatusTransitions = map[string][]string{
+	"draft":       {"published", "archived"},
+	"published":   {"draft", "maintenance", "archived"},
+	"maintenance": {"published", "maintenance", "archived"},
+	"archived":    {"draft", "published"},
+}
+
+const (
+	// maxImagesPerUpload membatasi jumlah file dalam satu upload banyak gambar
+	maxImagesPerUpload = 20
+	// imageUploadWorkers membatasi jumlah gambar yang didecode bersamaan, karena setiap decode
+	// bisa memakan memori sebesar ukuran gambar penuh
+	imageUploadWorkers = 4
 )
 
 // Helper function untuk membuat pointer dari string, mengembalikan nil jika string kosong
@@ -31,31 +85,48 @@ func float64ToPtr(f float64) *float64 {
 
 type VehicleService interface {
 	CreateVehicle(ctx context.Context, input model.CreateVehicleInput, ownerID uuid.UUID) (model.Vehicle, error)
-	GetAllVehicles(ctx context.Context, filter model.VehicleFilter) ([]model.Vehicle, error)
+	GetAllVehicles(ctx context.Context, filter model.VehicleFilter, page model.PageRequest) (model.VehicleSearchResult, error)
 	GetVehicleByID(ctx context.Context, id uuid.UUID) (model.Vehicle, error)
-	UpdateVehicle(ctx context.Context, id uuid.UUID, currentUserID uuid.UUID, input model.CreateVehicleInput) (model.Vehicle, error)
-	DeleteVehicle(ctx context.Context, id uuid.UUID, currentUserID uuid.UUID) error
-	UploadImage(ctx context.Context, vehicleID, currentUserID uuid.UUID, file multipart.File) (string, error)
-	GetVehiclesByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]model.Vehicle, error)
+	UpdateVehicle(ctx context.Context, id uuid.UUID, subject authz.Subject, input model.CreateVehicleInput) (model.Vehicle, error)
+	UpdateVehicleStatus(ctx context.Context, id uuid.UUID, subject authz.Subject, input model.UpdateVehicleStatusInput) (model.Vehicle, error)
+	DeleteVehicle(ctx context.Context, id uuid.UUID, subject authz.Subject) error
+	UploadImage(ctx context.Context, vehicleID uuid.UUID, subject authz.Subject, file multipart.File) (model.VehicleImage, error)
+	UploadImages(ctx context.Context, vehicleID uuid.UUID, subject authz.Subject, files []*multipart.FileHeader) ([]model.ImageUploadResult, error)
+	DeleteImage(ctx context.Context, vehicleID uuid.UUID, imageID uuid.UUID, subject authz.Subject) error
+	SetPrimaryImage(ctx context.Context, vehicleID uuid.UUID, imageID uuid.UUID, subject authz.Subject) ([]model.VehicleImage, error)
+	ReorderImages(ctx context.Context, vehicleID uuid.UUID, subject authz.Subject, input model.ReorderImagesInput) ([]model.VehicleImage, error)
+	GetVehiclesByOwnerID(ctx context.Context, ownerID uuid.UUID, page model.PageRequest) (model.Page[model.Vehicle], error)
 }
 
 type vehicleService struct {
 	repo      repository.VehicleRepository
 	imageRepo repository.ImageRepository
 	userRepo  repository.UserRepository
+	geocoder  geocode.Geocoder
+	storage   storage.ObjectStorage
+	images    *imaging.Processor
 }
 
-func NewVehicleService(repo repository.VehicleRepository, imageRepo repository.ImageRepository, userRepo repository.UserRepository) VehicleService {
-	return &vehicleService{repo: repo, imageRepo: imageRepo, userRepo: userRepo}
+func NewVehicleService(repo repository.VehicleRepository, imageRepo repository.ImageRepository, userRepo repository.UserRepository, geocoder geocode.Geocoder, objectStorage storage.ObjectStorage, imageProcessor *imaging.Processor) VehicleService {
+	return &vehicleService{repo: repo, imageRepo: imageRepo, userRepo: userRepo, geocoder: geocoder, storage: objectStorage, images: imageProcessor}
 }
 
 func (s *vehicleService) CreateVehicle(ctx context.Context, input model.CreateVehicleInput, ownerID uuid.UUID) (model.Vehicle, error) {
 	owner, err := s.userRepo.FindByID(ctx, ownerID)
 	if err != nil {
-		return model.Vehicle{}, errors.New("owner not found")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Vehicle{}, ErrOwnerNotFound
+		}
+		return model.Vehicle{}, err
 	}
 	if !owner.IsVerified {
-		return model.Vehicle{}, errors.New("forbidden: vendor account is not verified")
+		return model.Vehicle{}, ErrVendorNotVerified
+	}
+
+	// Tanpa status, listing langsung dipublikasikan seperti sebelum ada status draft
+	status := input.Status
+	if status == "" {
+		status = "published"
 	}
 
 	newVehicle := model.Vehicle{
@@ -68,7 +139,7 @@ func (s *vehicleService) CreateVehicle(ctx context.Context, input model.CreateVe
 		VehicleType:  input.VehicleType,
 		Transmission: input.Transmission,
 		Fuel:         input.Fuel,
-		Status:       "available",
+		Status:       status,
 		IsForSale:    input.IsForSale,
 		IsForRent:    input.IsForRent,
 		Features:     input.Features,
@@ -81,30 +152,113 @@ func (s *vehicleService) CreateVehicle(ctx context.Context, input model.CreateVe
 		RentalPriceWeekly:  float64ToPtr(input.RentalPriceWeekly),
 		RentalPriceMonthly: float64ToPtr(input.RentalPriceMonthly),
 	}
+	s.applyCoordinates(ctx, &newVehicle, input, nil)
 
 	createdVehicle, err := s.repo.Create(ctx, newVehicle)
 	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return model.Vehicle{}, ErrPlateNumberTaken
+		}
 		return model.Vehicle{}, err
 	}
 	return createdVehicle, nil
 }
 
-func (s *vehicleService) GetAllVehicles(ctx context.Context, filter model.VehicleFilter) ([]model.Vehicle, error) {
-	return s.repo.FindAll(ctx, filter)
+// GetAllVehicles mencari kendaraan sesuai filter. Jika halaman pertama pencarian kata kunci kosong,
+// kata kunci dikoreksi dengan merek/model terdekat lalu pencarian diulang dengan kata kunci tersebut.
+func (s *vehicleService) GetAllVehicles(ctx context.Context, filter model.VehicleFilter, page model.PageRequest) (model.VehicleSearchResult, error) {
+	if filter.SortOrder() == "distance" && !filter.HasOrigin() {
+		return model.VehicleSearchResult{}, ErrDistanceOrigin
+	}
+	if page.After != nil && page.After.Sort != filter.CursorKey() {
+		return model.VehicleSearchResult{}, ErrCursorSortChanged
+	}
+	vehicles, err := s.repo.FindAll(ctx, filter, page)
+	if err != nil {
+		return model.VehicleSearchResult{}, err
+	}
+	result := model.VehicleSearchResult{Page: vehicles}
+	if len(vehicles.Items) > 0 || page.After != nil || filter.SearchQuery() == "" {
+		return result, nil
+	}
+
+	suggestion, err := s.suggestQuery(ctx, filter.SearchQuery())
+	if err != nil || suggestion == "" {
+		return result, err
+	}
+	filter.Query, filter.Search = suggestion, ""
+	vehicles, err = s.repo.FindAll(ctx, filter, page)
+	if err != nil {
+		return model.VehicleSearchResult{}, err
+	}
+	if len(vehicles.Items) == 0 {
+		return result, nil
+	}
+	return model.VehicleSearchResult{Page: vehicles, DidYouMean: suggestion}, nil
 }
 
-func (s *vehicleService) GetVehicleByID(ctx context.Context, id uuid.UUID) (model.Vehicle, error) {
-	return s.repo.FindByID(ctx, id)
+// suggestQuery mengembalikan kata kunci hasil koreksi, atau string kosong jika tidak ada kata yang berubah
+func (s *vehicleService) suggestQuery(ctx context.Context, query string) (string, error) {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "", nil
+	}
+	terms, err := s.repo.SuggestTerms(ctx, words)
+	if err != nil {
+		return "", err
+	}
+	suggestion := strings.Join(terms, " ")
+	if suggestion == strings.Join(words, " ") {
+		return "", nil
+	}
+	return suggestion, nil
 }
 
-func (s *vehicleService) UpdateVehicle(ctx context.Context, id uuid.UUID, currentUserID uuid.UUID, input model.CreateVehicleInput) (model.Vehicle, error) {
-	vehicleToUpdate, err := s.repo.FindByID(ctx, id)
+// applyCoordinates mengisi koordinat kendaraan. Koordinat dari input dipakai apa adanya; jika kosong,
+// koordinat lama dipertahankan selama lokasi tidak berubah, selain itu lokasi di-geocode. Kegagalan
+// geocoding tidak menggagalkan penyimpanan, kendaraan hanya tidak muncul di pencarian jarak.
+func (s *vehicleService) applyCoordinates(ctx context.Context, v *model.Vehicle, input model.CreateVehicleInput, previousLocation *string) {
+	if input.Latitude != nil && input.Longitude != nil {
+		v.Latitude, v.Longitude = input.Latitude, input.Longitude
+		return
+	}
+	if v.Latitude != nil && previousLocation != nil && v.Location != nil && *previousLocation == *v.Location {
+		return
+	}
+
+	v.Latitude, v.Longitude = nil, nil
+	if v.Location == nil {
+		return
+	}
+	point, err := s.geocoder.Geocode(ctx, *v.Location)
 	if err != nil {
-		return model.Vehicle{}, errors.New("vehicle not found")
+		if !errors.Is(err, geocode.ErrNotFound) {
+			log.Printf("Warning: failed to geocode location %q: %v", *v.Location, err)
+		}
+		return
 	}
+	v.Latitude, v.Longitude = &point.Latitude, &point.Longitude
+}
 
-	if vehicleToUpdate.OwnerID != currentUserID {
-		return model.Vehicle{}, errors.New("forbidden: you are not the owner of this vehicle")
+// GetVehicleByID mengambil detail kendaraan untuk publik. Draft belum pernah dipublikasikan sehingga
+// tidak ditampilkan; listing yang diarsipkan atau terjual tetap bisa dibuka dari riwayat booking/penjualan.
+func (s *vehicleService) GetVehicleByID(ctx context.Context, id uuid.UUID) (model.Vehicle, error) {
+	vehicle, err := s.findVehicle(ctx, id)
+	if err != nil {
+		return model.Vehicle{}, err
+	}
+	if vehicle.Status == "draft" {
+		return model.Vehicle{}, ErrVehicleNotFound
+	}
+	return vehicle, nil
+}
+
+func (s *vehicleService) UpdateVehicle(ctx context.Context, id uuid.UUID, subject authz.Subject, input model.CreateVehicleInput) (model.Vehicle, error) {
+	vehicleToUpdate, err := s.findWritableVehicle(ctx, id, subject)
+	if err != nil {
+		return model.Vehicle{}, err
 	}
 
 	vehicleToUpdate.Brand = input.Brand
@@ -118,6 +272,7 @@ func (s *vehicleService) UpdateVehicle(ctx context.Context, id uuid.UUID, curren
 	vehicleToUpdate.IsForRent = input.IsForRent
 	vehicleToUpdate.Features = input.Features
 
+	previousLocation := vehicleToUpdate.Location
 	// PERBAIKAN: Gunakan helper untuk memperbarui field pointer
 	vehicleToUpdate.Color = stringToPtr(input.Color)
 	vehicleToUpdate.Description = stringToPtr(input.Description)
@@ -126,58 +281,359 @@ func (s *vehicleService) UpdateVehicle(ctx context.Context, id uuid.UUID, curren
 	vehicleToUpdate.RentalPriceDaily = float64ToPtr(input.RentalPriceDaily)
 	vehicleToUpdate.RentalPriceWeekly = float64ToPtr(input.RentalPriceWeekly)
 	vehicleToUpdate.RentalPriceMonthly = float64ToPtr(input.RentalPriceMonthly)
+	s.applyCoordinates(ctx, &vehicleToUpdate, input, previousLocation)
 
 	updatedVehicle, err := s.repo.Update(ctx, vehicleToUpdate)
 	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return model.Vehicle{}, ErrPlateNumberTaken
+		}
 		return model.Vehicle{}, err
 	}
 	return updatedVehicle, nil
 }
 
-func (s *vehicleService) DeleteVehicle(ctx context.Context, id uuid.UUID, currentUserID uuid.UUID) error {
-	vehicleToDelete, err := s.repo.FindByID(ctx, id)
+// UpdateVehicleStatus memindahkan listing ke status baru sesuai vehicleStatusTransitions. Status
+// maintenance butuh jendela tanggal yang tidak bertabrakan dengan booking aktif; selama jendela itu
+// kendaraan tidak bisa dibooking.
+func (s *vehicleService) UpdateVehicleStatus(ctx context.Context, id uuid.UUID, subject authz.Subject, input model.UpdateVehicleStatusInput) (model.Vehicle, error) {
+	vehicle, err := s.findWritableVehicle(ctx, id, subject)
 	if err != nil {
-		return errors.New("vehicle not found")
+		return model.Vehicle{}, err
+	}
+
+	fromStatus := vehicle.Status
+	if fromStatus == "sold" {
+		return model.Vehicle{}, ErrVehicleSold
+	}
+	if !slices.Contains(vehicleStatusTransitions[fromStatus], input.Status) {
+		return model.Vehicle{}, apperror.Conflict(ErrInvalidVehicleTransition.Code, "invalid status transition from '"+fromStatus+"' to '"+input.Status+"'")
 	}
 
-	if vehicleToDelete.OwnerID != currentUserID {
-		return errors.New("forbidden: you are not the owner of this vehicle")
+	vehicle.Status = input.Status
+	vehicle.MaintenanceStart, vehicle.MaintenanceEnd = nil, nil
+	if input.Status == "maintenance" {
+		start, end, err := parseMaintenanceWindow(input)
+		if err != nil {
+			return model.Vehicle{}, err
+		}
+		vehicle.MaintenanceStart, vehicle.MaintenanceEnd = &start, &end
 	}
 
-	return s.repo.Delete(ctx, id)
+	var updatedVehicle model.Vehicle
+	if input.Status == "maintenance" {
+		var scheduled bool
+		updatedVehicle, scheduled, err = s.repo.ScheduleMaintenance(ctx, vehicle, fromStatus)
+		if err == nil && !scheduled {
+			return model.Vehicle{}, ErrMaintenanceBooked
+		}
+	} else {
+		updatedVehicle, err = s.repo.UpdateStatus(ctx, vehicle, fromStatus)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Vehicle{}, ErrVehicleStatusChanged
+		}
+		return model.Vehicle{}, err
+	}
+	return updatedVehicle, nil
 }
 
-func (s *vehicleService) UploadImage(ctx context.Context, vehicleID, currentUserID uuid.UUID, file multipart.File) (string, error) {
-	vehicle, err := s.repo.FindByID(ctx, vehicleID)
+// parseMaintenanceWindow membaca jendela maintenance dari input; tanggal mulai default hari ini
+func parseMaintenanceWindow(input model.UpdateVehicleStatusInput) (time.Time, time.Time, error) {
+	layout := "2006-01-02"
+	today, _ := time.Parse(layout, time.Now().Format(layout))
+
+	if input.MaintenanceEnd == "" {
+		return time.Time{}, time.Time{}, ErrInvalidMaintenanceWindow.WithField("maintenance_end", "required for maintenance status")
+	}
+	end, err := time.Parse(layout, input.MaintenanceEnd)
 	if err != nil {
-		return "", errors.New("vehicle not found")
+		return time.Time{}, time.Time{}, ErrInvalidMaintenanceWindow.WithField("maintenance_end", "invalid date format, use YYYY-MM-DD")
+	}
+	start := today
+	if input.MaintenanceStart != "" {
+		if start, err = time.Parse(layout, input.MaintenanceStart); err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidMaintenanceWindow.WithField("maintenance_start", "invalid date format, use YYYY-MM-DD")
+		}
 	}
-	if vehicle.OwnerID != currentUserID {
-		return "", errors.New("forbidden: you are not the owner of this vehicle")
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, ErrInvalidMaintenanceWindow.WithField("maintenance_end", "cannot be before maintenance_start")
 	}
+	if end.Before(today) {
+		return time.Time{}, time.Time{}, ErrInvalidMaintenanceWindow.WithField("maintenance_end", "cannot be in the past")
+	}
+	return start, end, nil
+}
 
-	cfg := config.LoadConfig()
-	cld, err := cloudinary.NewFromURL(cfg.CloudinaryURL)
+func (s *vehicleService) DeleteVehicle(ctx context.Context, id uuid.UUID, subject authz.Subject) error {
+	vehicle, err := s.findWritableVehicle(ctx, id, subject)
 	if err != nil {
-		return "", errors.New("failed to connect to cloudinary")
+		return err
 	}
+	return archiveVehicle(ctx, s.repo, vehicle)
+}
 
-	uploadResult, err := cld.Upload.Upload(ctx, file, uploader.UploadParams{
-		Folder: "sultra-otomotif",
-	})
+// archiveVehicle menarik listing dari pencarian tanpa menghapus barisnya, agar booking, ulasan dan
+// transaksi penjualan yang mereferensikan kendaraan tidak ikut terhapus. Listing yang sudah diarsipkan dibiarkan.
+func archiveVehicle(ctx context.Context, repo repository.VehicleRepository, vehicle model.Vehicle) error {
+	fromStatus := vehicle.Status
+	switch fromStatus {
+	case "archived":
+		return nil
+	case "sold":
+		return ErrVehicleSold
+	}
+
+	vehicle.Status = "archived"
+	vehicle.MaintenanceStart, vehicle.MaintenanceEnd = nil, nil
+	if _, err := repo.UpdateStatus(ctx, vehicle, fromStatus); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrVehicleStatusChanged
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *vehicleService) UploadImage(ctx context.Context, vehicleID uuid.UUID, subject authz.Subject, file multipart.File) (model.VehicleImage, error) {
+	if _, err := s.findWritableVehicle(ctx, vehicleID, subject); err != nil {
+		return model.VehicleImage{}, err
+	}
+
+	image, err := s.storeImage(ctx, vehicleID, file)
 	if err != nil {
-		return "", errors.New("failed to upload image to cloudinary")
+		return model.VehicleImage{}, err
 	}
+	return s.saveImage(ctx, image)
+}
+
+// UploadImages mengupload banyak gambar sekaligus. File diproses dan disimpan paralel oleh
+// imageUploadWorkers worker, lalu dicatat ke database sesuai urutan file di request sehingga
+// urutan tampilnya mengikuti urutan upload. Kegagalan satu file hanya tercatat di hasil file itu.
+func (s *vehicleService) UploadImages(ctx context.Context, vehicleID uuid.UUID, subject authz.Subject, files []*multipart.FileHeader) ([]model.ImageUploadResult, error) {
+	if len(files) == 0 {
+		return nil, ErrNoImages
+	}
+	if len(files) > maxImagesPerUpload {
+		return nil, ErrTooManyImages
+	}
+	if _, err := s.findWritableVehicle(ctx, vehicleID, subject); err != nil {
+		return nil, err
+	}
+
+	stored := make([]model.VehicleImage, len(files))
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(imageUploadWorkers, len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				stored[i], errs[i] = s.storeUploadedFile(ctx, vehicleID, files[i])
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-	imageURL := uploadResult.SecureURL
-	err = s.imageRepo.SaveVehicleImage(ctx, vehicleID, imageURL)
+	results := make([]model.ImageUploadResult, len(files))
+	for i, fileHeader := range files {
+		results[i].Filename = fileHeader.Filename
+		if errs[i] == nil {
+			var saved model.VehicleImage
+			if saved, errs[i] = s.saveImage(ctx, stored[i]); errs[i] == nil {
+				results[i].Success = true
+				results[i].Image = &saved
+				continue
+			}
+		}
+		results[i].Error = imageUploadError(fileHeader.Filename, errs[i])
+	}
+	return results, nil
+}
+
+// storeUploadedFile membuka satu file upload lalu menyimpannya. Panic saat decode diubah menjadi
+// error agar tidak menghentikan worker dan file lainnya.
+func (s *vehicleService) storeUploadedFile(ctx context.Context, vehicleID uuid.UUID, fileHeader *multipart.FileHeader) (image model.VehicleImage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = apperror.Internal("image_processing_failed", "failed to process image", fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	file, err := fileHeader.Open()
 	if err != nil {
-		return "", err
+		return model.VehicleImage{}, apperror.Internal("image_upload_failed", "failed to open image file", err)
+	}
+	defer file.Close()
+	return s.storeImage(ctx, vehicleID, file)
+}
+
+// storeImage memvalidasi gambar dan menyimpan semua rendition-nya ke penyimpanan, belum ke database
+func (s *vehicleService) storeImage(ctx context.Context, vehicleID uuid.UUID, file io.Reader) (model.VehicleImage, error) {
+	renditions, err := s.images.Process(file)
+	if err != nil {
+		return model.VehicleImage{}, imageProcessingError(err)
+	}
+
+	// Setiap rendition disimpan sebagai vehicles/<vehicle>/<image>/<rendition>.jpg
+	image := model.VehicleImage{ID: uuid.New(), VehicleID: vehicleID}
+	for _, rendition := range renditions {
+		key := fmt.Sprintf("vehicles/%s/%s/%s.jpg", vehicleID, image.ID, rendition.Name)
+		object, err := s.storage.Put(ctx, key, bytes.NewReader(rendition.Data), imaging.ContentType)
+		if err != nil {
+			s.deleteStoredImage(ctx, image.StorageKeys()...)
+			return model.VehicleImage{}, apperror.Internal("image_upload_failed", "failed to store image", err)
+		}
+		switch rendition.Name {
+		case imaging.RenditionThumbnail:
+			image.ThumbnailURL, image.ThumbnailKey = object.URL, object.Key
+		case imaging.RenditionCard:
+			image.CardURL, image.CardKey = object.URL, object.Key
+		default:
+			image.ImageURL, image.StorageKey = object.URL, object.Key
+		}
+	}
+	return image, nil
+}
+
+// saveImage mencatat gambar yang sudah tersimpan ke database; file-nya dihapus lagi jika gagal
+func (s *vehicleService) saveImage(ctx context.Context, image model.VehicleImage) (model.VehicleImage, error) {
+	saved, err := s.imageRepo.SaveVehicleImage(ctx, image)
+	if err != nil {
+		s.deleteStoredImage(ctx, image.StorageKeys()...)
+		return model.VehicleImage{}, err
+	}
+	return saved, nil
+}
+
+// imageUploadError mengubah error satu file menjadi hasil untuk klien. Detail error internal hanya
+// dicatat di log.
+func imageUploadError(filename string, err error) *model.ImageUploadError {
+	if appErr, ok := apperror.As(err); ok && appErr.Kind != apperror.KindInternal {
+		return &model.ImageUploadError{Code: appErr.Code, Message: appErr.Message}
+	}
+	log.Printf("Warning: failed to upload image %q: %v", filename, err)
+	return &model.ImageUploadError{Code: "image_upload_failed", Message: "failed to upload image"}
+}
+
+// imageProcessingError mengubah error validasi gambar menjadi error 400 untuk klien
+func imageProcessingError(err error) error {
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedType):
+		return ErrImageType
+	case errors.Is(err, imaging.ErrTooLarge):
+		return ErrImageTooLarge
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return ErrImageDimensions
+	case errors.Is(err, imaging.ErrInvalidImage):
+		return ErrImageInvalid
+	default:
+		return apperror.Internal("image_processing_failed", "failed to process image", err)
+	}
+}
+
+// DeleteImage menghapus gambar dari listing lalu dari penyimpanan. File yang gagal dihapus dari
+// penyimpanan hanya dicatat di log karena gambar sudah tidak ditampilkan lagi.
+func (s *vehicleService) DeleteImage(ctx context.Context, vehicleID uuid.UUID, imageID uuid.UUID, subject authz.Subject) error {
+	image, err := s.findWritableImage(ctx, vehicleID, imageID, subject)
+	if err != nil {
+		return err
+	}
+	if err := s.imageRepo.Delete(ctx, image); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrImageNotFound
+		}
+		return err
+	}
+
+	s.deleteStoredImage(ctx, image.StorageKeys()...)
+	return nil
+}
+
+// deleteStoredImage menghapus file rendition dari penyimpanan; kegagalan hanya dicatat di log
+func (s *vehicleService) deleteStoredImage(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("Warning: failed to delete stored image %s: %v", key, err)
+		}
+	}
+}
+
+func (s *vehicleService) SetPrimaryImage(ctx context.Context, vehicleID uuid.UUID, imageID uuid.UUID, subject authz.Subject) ([]model.VehicleImage, error) {
+	if _, err := s.findWritableImage(ctx, vehicleID, imageID, subject); err != nil {
+		return nil, err
+	}
+	if err := s.imageRepo.SetPrimary(ctx, vehicleID, imageID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	return s.imageRepo.FindByVehicleID(ctx, vehicleID)
+}
+
+// ReorderImages mengubah urutan tampil gambar. image_ids harus memuat setiap gambar kendaraan tepat sekali.
+func (s *vehicleService) ReorderImages(ctx context.Context, vehicleID uuid.UUID, subject authz.Subject, input model.ReorderImagesInput) ([]model.VehicleImage, error) {
+	if _, err := s.findWritableVehicle(ctx, vehicleID, subject); err != nil {
+		return nil, err
+	}
+	reordered, err := s.imageRepo.Reorder(ctx, vehicleID, input.ImageIDs)
+	if err != nil {
+		return nil, err
+	}
+	if !reordered {
+		return nil, ErrInvalidImageOrder
 	}
+	return s.imageRepo.FindByVehicleID(ctx, vehicleID)
+}
+
+func (s *vehicleService) GetVehiclesByOwnerID(ctx context.Context, ownerID uuid.UUID, page model.PageRequest) (model.Page[model.Vehicle], error) {
+	return s.repo.FindAllByOwnerID(ctx, ownerID, page)
+}
+
+func (s *vehicleService) findVehicle(ctx context.Context, id uuid.UUID) (model.Vehicle, error) {
+	vehicle, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Vehicle{}, ErrVehicleNotFound
+		}
+		return model.Vehicle{}, err
+	}
+	return vehicle, nil
+}
 
-	return imageURL, nil
+// findWritableImage mengambil gambar milik kendaraan tertentu dan memastikan subjek boleh mengubah kendaraannya
+func (s *vehicleService) findWritableImage(ctx context.Context, vehicleID uuid.UUID, imageID uuid.UUID, subject authz.Subject) (model.VehicleImage, error) {
+	if _, err := s.findWritableVehicle(ctx, vehicleID, subject); err != nil {
+		return model.VehicleImage{}, err
+	}
+	image, err := s.imageRepo.FindByID(ctx, imageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.VehicleImage{}, ErrImageNotFound
+		}
+		return model.VehicleImage{}, err
+	}
+	if image.VehicleID != vehicleID {
+		return model.VehicleImage{}, ErrImageNotFound
+	}
+	return image, nil
 }
 
-func (s *vehicleService) GetVehiclesByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]model.Vehicle, error) {
-	return s.repo.FindAllByOwnerID(ctx, ownerID)
+// findWritableVehicle mengambil kendaraan dan memastikan subjek boleh mengubahnya
+func (s *vehicleService) findWritableVehicle(ctx context.Context, id uuid.UUID, subject authz.Subject) (model.Vehicle, error) {
+	vehicle, err := s.findVehicle(ctx, id)
+	if err != nil {
+		return model.Vehicle{}, err
+	}
+	if !authz.CanWriteVehicle(subject, vehicle) {
+		return model.Vehicle{}, ErrNotVehicleOwner
+	}
+	return vehicle, nil
 }
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"slices"
+	"testing"
+	"time"
+
+	"sultra-otomotif-api/internal/apperror"
+	"sultra-otomotif-api/internal/authz"
+	"sultra-otomotif-api/internal/imaging"
+	"sultra-otomotif-api/internal/model"
+	"sultra-otomotif-api/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+type imageTestEnv struct {
+	service VehicleService
+	storage *storage.MemoryStorage
+	images  *fakeImageRepo
+	vehicle model.Vehicle
+	owner   authz.Subject
+}
+
+func newImageTestEnv(t *testing.T) *imageTestEnv {
+	t.Helper()
+	owner := authz.Subject{UserID: uuid.New(), Role: "vendor"}
+	vehicle := model.Vehicle{ID: uuid.New(), OwnerID: owner.UserID, Status: "published"}
+	env := &imageTestEnv{storage: storage.NewMemoryStorage(), images: &fakeImageRepo{}, vehicle: vehicle, owner: owner}
+	env.service = NewVehicleService(newFakeVehicleRepo(vehicle), env.images, nil, nil, env.storage, imaging.NewProcessor(imaging.Options{}))
+	return env
+}
+
+// assertStored memastikan semua rendition gambar ada di penyimpanan dan berupa JPEG
+func (env *imageTestEnv) assertStored(t *testing.T, img model.VehicleImage) {
+	t.Helper()
+	keys := img.StorageKeys()
+	if len(keys) != 3 {
+		t.Fatalf("image has %d storage keys, want 3 renditions", len(keys))
+	}
+	for _, key := range keys {
+		data, ok := env.storage.Get(key)
+		if !ok {
+			t.Errorf("rendition %s not stored", key)
+			continue
+		}
+		if _, err := jpeg.DecodeConfig(bytes.NewReader(data)); err != nil {
+			t.Errorf("rendition %s is not a JPEG: %v", key, err)
+		}
+	}
+	if img.ImageURL != "memory://"+img.StorageKey || img.ThumbnailURL != "memory://"+img.ThumbnailKey || img.CardURL != "memory://"+img.CardKey {
+		t.Errorf("image URLs %q %q %q do not match storage keys", img.ImageURL, img.ThumbnailURL, img.CardURL)
+	}
+}
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, solidImage(width, height), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, solidImage(width, height)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func solidImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 200, 30, 30, 255
+	}
+	return img
+}
+
+// multipartFile membuat satu file upload seperti yang dibaca handler dari field "image"
+func multipartFile(t *testing.T, data []byte) multipart.File {
+	t.Helper()
+	file, err := multipartFiles(t, map[string][]byte{"image.jpg": data}, "image.jpg")[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+// multipartFiles membuat file upload seperti yang dibaca handler dari field "images"
+func multipartFiles(t *testing.T, files map[string][]byte, order ...string) []*multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, name := range order {
+		part, err := w.CreateFormFile("images", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(files[name])
+	}
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(32 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["images"]
+}
+
+func TestUploadImage(t *testing.T) {
+	env := newImageTestEnv(t)
+
+	img, err := env.service.UploadImage(context.Background(), env.vehicle.ID, env.owner, multipartFile(t, testJPEG(t, 2000, 1000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.assertStored(t, img)
+	if img.VehicleID != env.vehicle.ID || !img.IsPrimary || len(env.images.images) != 1 {
+		t.Errorf("saved image = %+v, want the first (primary) image of the vehicle", img)
+	}
+}
+
+func TestUploadImageRejections(t *testing.T) {
+	env := newImageTestEnv(t)
+	ctx := context.Background()
+
+	stranger := authz.Subject{UserID: uuid.New(), Role: "vendor"}
+	_, err := env.service.UploadImage(ctx, env.vehicle.ID, stranger, multipartFile(t, testJPEG(t, 10, 10)))
+	assertErrorCode(t, err, ErrNotVehicleOwner)
+
+	_, err = env.service.UploadImage(ctx, uuid.New(), env.owner, multipartFile(t, testJPEG(t, 10, 10)))
+	assertErrorCode(t, err, ErrVehicleNotFound)
+
+	_, err = env.service.UploadImage(ctx, env.vehicle.ID, env.owner, multipartFile(t, []byte("GIF89a bukan gambar yang diterima")))
+	assertErrorCode(t, err, ErrImageType)
+
+	if len(env.images.images) != 0 {
+		t.Errorf("%d images saved, want none", len(env.images.images))
+	}
+}
+
+func TestUploadImageRemovesFilesWhenSaveFails(t *testing.T) {
+	env := newImageTestEnv(t)
+	env.images.failCall = 1
+
+	if _, err := env.service.UploadImage(context.Background(), env.vehicle.ID, env.owner, multipartFile(t, testJPEG(t, 100, 100))); err == nil {
+		t.Fatal("UploadImage succeeded, want the repository error")
+	}
+	if keys := env.storage.Keys(); len(keys) != 0 {
+		t.Errorf("renditions %v left in storage after failed save", keys)
+	}
+}
+
+func TestUploadImages(t *testing.T) {
+	env := newImageTestEnv(t)
+	files := multipartFiles(t, map[string][]byte{
+		"depan.jpg":    testJPEG(t, 1200, 800),
+		"animasi.gif":  []byte("GIF89a bukan gambar yang diterima"),
+		"samping.png":  testPNG(t, 640, 480),
+		"rusak.jpg":    testJPEG(t, 64, 64)[:40],
+		"belakang.jpg": testJPEG(t, 300, 900),
+	}, "depan.jpg", "animasi.gif", "samping.png", "rusak.jpg", "belakang.jpg")
+
+	results, err := env.service.UploadImages(context.Background(), env.vehicle.ID, env.owner, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		filename string
+		errCode  string
+	}{
+		{"depan.jpg", ""},
+		{"animasi.gif", ErrImageType.Code},
+		{"samping.png", ""},
+		{"rusak.jpg", ErrImageInvalid.Code},
+		{"belakang.jpg", ""},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	var saved []uuid.UUID
+	for i, w := range want {
+		r := results[i]
+		if r.Filename != w.filename {
+			t.Errorf("result %d is for %q, want %q (request order)", i, r.Filename, w.filename)
+		}
+		if w.errCode != "" {
+			if r.Success || r.Error == nil || r.Error.Code != w.errCode {
+				t.Errorf("%s: result = %+v, want error %s", w.filename, r, w.errCode)
+			}
+			continue
+		}
+		if !r.Success || r.Image == nil {
+			t.Errorf("%s: result = %+v, want success", w.filename, r)
+			continue
+		}
+		env.assertStored(t, *r.Image)
+		saved = append(saved, r.Image.ID)
+	}
+
+	// Gambar dicatat sesuai urutan file di request, bukan urutan selesai diproses
+	if len(env.images.images) != len(saved) {
+		t.Fatalf("%d images saved, want %d", len(env.images.images), len(saved))
+	}
+	for i, img := range env.images.images {
+		if img.ID != saved[i] || img.SortOrder != i {
+			t.Errorf("saved image %d = %s (sort %d), want %s", i, img.ID, img.SortOrder, saved[i])
+		}
+	}
+	if got := len(env.storage.Keys()); got != 3*len(saved) {
+		t.Errorf("storage holds %d objects, want %d", got, 3*len(saved))
+	}
+}
+
+func TestUploadImagesLimits(t *testing.T) {
+	env := newImageTestEnv(t)
+	ctx := context.Background()
+
+	_, err := env.service.UploadImages(ctx, env.vehicle.ID, env.owner, nil)
+	assertErrorCode(t, err, ErrNoImages)
+
+	files := map[string][]byte{}
+	var names []string
+	for i := 0; i <= maxImagesPerUpload; i++ {
+		name := uuid.NewString() + ".jpg"
+		files[name] = []byte("x")
+		names = append(names, name)
+	}
+	_, err = env.service.UploadImages(ctx, env.vehicle.ID, env.owner, multipartFiles(t, files, names...))
+	assertErrorCode(t, err, ErrTooManyImages)
+}
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	layout := "2006-01-02"
+	today, _ := time.Parse(layout, time.Now().Format(layout))
+	day := func(offset int) string { return today.AddDate(0, 0, offset).Format(layout) }
+
+	tests := []struct {
+		name      string
+		input     model.UpdateVehicleStatusInput
+		wantStart string
+		wantEnd   string
+		wantField string // field error yang diharapkan, kosong jika valid
+	}{
+		{"start defaults to today", model.UpdateVehicleStatusInput{MaintenanceEnd: day(3)}, day(0), day(3), ""},
+		{"explicit window", model.UpdateVehicleStatusInput{MaintenanceStart: day(2), MaintenanceEnd: day(5)}, day(2), day(5), ""},
+		{"single day", model.UpdateVehicleStatusInput{MaintenanceStart: day(1), MaintenanceEnd: day(1)}, day(1), day(1), ""},
+		{"started in the past", model.UpdateVehicleStatusInput{MaintenanceStart: day(-2), MaintenanceEnd: day(0)}, day(-2), day(0), ""},
+		{"missing end", model.UpdateVehicleStatusInput{MaintenanceStart: day(1)}, "", "", "maintenance_end"},
+		{"invalid end", model.UpdateVehicleStatusInput{MaintenanceEnd: "07/01/2025"}, "", "", "maintenance_end"},
+		{"invalid start", model.UpdateVehicleStatusInput{MaintenanceStart: "besok", MaintenanceEnd: day(1)}, "", "", "maintenance_start"},
+		{"end before start", model.UpdateVehicleStatusInput{MaintenanceStart: day(5), MaintenanceEnd: day(2)}, "", "", "maintenance_end"},
+		{"end in the past", model.UpdateVehicleStatusInput{MaintenanceStart: day(-5), MaintenanceEnd: day(-1)}, "", "", "maintenance_end"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseMaintenanceWindow(tt.input)
+			if tt.wantField != "" {
+				appErr, ok := apperror.As(err)
+				if !ok || appErr.Code != ErrInvalidMaintenanceWindow.Code || appErr.Fields[tt.wantField] == "" {
+					t.Errorf("err = %v, want %s on field %s", err, ErrInvalidMaintenanceWindow.Code, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if start.Format(layout) != tt.wantStart || end.Format(layout) != tt.wantEnd {
+				t.Errorf("window = %s..%s, want %s..%s", start.Format(layout), end.Format(layout), tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestUpdateVehicleStatusTransitions(t *testing.T) {
+	allowed := map[string][]string{
+		"draft":       {"published", "archived"},
+		"published":   {"draft", "maintenance", "archived"},
+		"maintenance": {"published", "maintenance", "archived"},
+		"archived":    {"draft", "published"},
+		"sold":        {},
+	}
+	maintenanceEnd := time.Now().AddDate(0, 0, 3).Format("2006-01-02")
+
+	for from, targets := range allowed {
+		for _, to := range []string{"draft", "published", "maintenance", "archived"} {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				owner := authz.Subject{UserID: uuid.New(), Role: "vendor"}
+				vehicle := model.Vehicle{ID: uuid.New(), OwnerID: owner.UserID, Status: from}
+				if from == "maintenance" {
+					start, end := time.Now(), time.Now().AddDate(0, 0, 1)
+					vehicle.MaintenanceStart, vehicle.MaintenanceEnd = &start, &end
+				}
+				svc := NewVehicleService(newFakeVehicleRepo(vehicle), &fakeImageRepo{}, nil, nil, storage.NewMemoryStorage(), imaging.NewProcessor(imaging.Options{}))
+
+				input := model.UpdateVehicleStatusInput{Status: to}
+				if to == "maintenance" {
+					input.MaintenanceEnd = maintenanceEnd
+				}
+				updated, err := svc.UpdateVehicleStatus(context.Background(), vehicle.ID, owner, input)
+
+				switch {
+				case from == "sold":
+					assertErrorCode(t, err, ErrVehicleSold)
+				case !slices.Contains(targets, to):
+					assertErrorCode(t, err, ErrInvalidVehicleTransition)
+				case err != nil:
+					t.Fatalf("transition rejected: %v", err)
+				case updated.Status != to:
+					t.Errorf("status = %q, want %q", updated.Status, to)
+				case (to == "maintenance") != (updated.MaintenanceEnd != nil):
+					t.Errorf("maintenance window = %v..%v after moving to %s", updated.MaintenanceStart, updated.MaintenanceEnd, to)
+				}
+			})
+		}
+	}
+}
+
+func TestUpdateVehicleStatusRejectsBookedMaintenance(t *testing.T) {
+	owner := authz.Subject{UserID: uuid.New(), Role: "vendor"}
+	vehicle := model.Vehicle{ID: uuid.New(), OwnerID: owner.UserID, Status: "published"}
+	repo := newFakeVehicleRepo(vehicle)
+	repo.maintenanceBooked = true
+	svc := NewVehicleService(repo, &fakeImageRepo{}, nil, nil, storage.NewMemoryStorage(), imaging.NewProcessor(imaging.Options{}))
+
+	input := model.UpdateVehicleStatusInput{Status: "maintenance", MaintenanceEnd: time.Now().AddDate(0, 0, 3).Format("2006-01-02")}
+	_, err := svc.UpdateVehicleStatus(context.Background(), vehicle.ID, owner, input)
+	assertErrorCode(t, err, ErrMaintenanceBooked)
+
+	stored, _ := repo.FindByID(context.Background(), vehicle.ID)
+	if stored.Status != "published" {
+		t.Errorf("status = %q, want the vehicle to stay published", stored.Status)
+	}
+}
+
+func TestGetAllVehiclesRejectsCursorFromAnotherSort(t *testing.T) {
+	lat, lng := -3.99, 122.51
+	svc := NewVehicleService(newFakeVehicleRepo(model.Vehicle{ID: uuid.New()}), &fakeImageRepo{}, nil, nil, storage.NewMemoryStorage(), imaging.NewProcessor(imaging.Options{}))
+
+	byPrice := model.VehicleFilter{Sort: "price_asc"}
+	after := &model.Cursor{ID: uuid.New(), Sort: byPrice.CursorKey()}
+	if _, err := svc.GetAllVehicles(context.Background(), byPrice, model.PageRequest{Limit: 20, After: after}); err != nil {
+		t.Fatalf("cursor from the same sort: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		cursorSort string
+		filter     model.VehicleFilter
+	}{
+		{"different sort", model.VehicleFilter{Sort: "year_desc"}.CursorKey(), byPrice},
+		{"price of another listing type", model.VehicleFilter{Sort: "price_asc", IsForRent: true}.CursorKey(), byPrice},
+		{"keyword added", model.VehicleFilter{}.CursorKey(), model.VehicleFilter{Query: "avanza"}},
+		{"distance origin added", model.VehicleFilter{Sort: "distance"}.CursorKey(), model.VehicleFilter{Sort: "distance", Lat: &lat, Lng: &lng}},
+		{"cursor without sort", "", byPrice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := &model.Cursor{ID: uuid.New(), Sort: tt.cursorSort}
+			_, err := svc.GetAllVehicles(context.Background(), tt.filter, model.PageRequest{Limit: 20, After: after})
+			assertErrorCode(t, err, ErrCursorSortChanged)
+		})
+	}
+}
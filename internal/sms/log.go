@@ -0,0 +1,18 @@
+package sms
+
+import (
+	"context"
+	"log"
+)
+
+// LogSender tidak benar-benar mengirim SMS; isi pesan hanya ditulis ke log aplikasi
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, to string, body string) error {
+	log.Printf("sms: to=%s\n%s", to, body)
+	return nil
+}
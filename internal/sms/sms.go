@@ -0,0 +1,9 @@
+package sms
+
+import "context"
+
+// SMSSender mengirim SMS transaksional (misal kode OTP). TwilioSender dipakai di production,
+// LogSender untuk development; provider lain cukup mengimplementasikan interface ini.
+type SMSSender interface {
+	Send(ctx context.Context, to string, body string) error
+}
@@ -0,0 +1,58 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const twilioAPIBaseURL = "https://api.twilio.com/2010-04-01"
+
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string // nomor pengirim (E.164) atau Messaging Service SID (MG...)
+}
+
+// TwilioSender mengirim SMS lewat REST API Twilio
+type TwilioSender struct {
+	cfg     TwilioConfig
+	baseURL string
+	client  *http.Client
+}
+
+func NewTwilioSender(cfg TwilioConfig) *TwilioSender {
+	return &TwilioSender{cfg: cfg, baseURL: twilioAPIBaseURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *TwilioSender) Send(ctx context.Context, to string, body string) error {
+	form := url.Values{"To": {to}, "Body": {body}}
+	if strings.HasPrefix(s.cfg.From, "MG") {
+		form.Set("MessagingServiceSid", s.cfg.From)
+	} else {
+		form.Set("From", s.cfg.From)
+	}
+
+	endpoint := s.baseURL + "/Accounts/" + url.PathEscape(s.cfg.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.cfg.AccountSID, s.cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send sms to %s: %w", to, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("send sms to %s: twilio returned %s: %s", to, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// CloudinaryStorage menyimpan file di Cloudinary. Key yang dikembalikan adalah public ID Cloudinary
+// (Folder + key tanpa ekstensi), karena Cloudinary menentukan format file sendiri.
+type CloudinaryStorage struct {
+	client *cloudinary.Cloudinary
+	Folder string
+}
+
+func NewCloudinaryStorage(cloudinaryURL, folder string) (*CloudinaryStorage, error) {
+	client, err := cloudinary.NewFromURL(cloudinaryURL)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudinaryStorage{client: client, Folder: folder}, nil
+}
+
+func (s *CloudinaryStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) (Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return Object{}, err
+	}
+	result, err := s.client.Upload.Upload(ctx, r, uploader.UploadParams{
+		PublicID:  strings.TrimSuffix(key, path.Ext(key)),
+		Folder:    s.Folder,
+		Overwrite: api.Bool(true),
+	})
+	if err != nil {
+		return Object{}, err
+	}
+	if result.Error.Message != "" {
+		return Object{}, fmt.Errorf("cloudinary: %s", result.Error.Message)
+	}
+	return Object{Key: result.PublicID, URL: result.SecureURL}, nil
+}
+
+// Delete menerima public ID Cloudinary, termasuk public ID gambar lama yang diupload sebelum
+// penyimpanan ini dipakai
+func (s *CloudinaryStorage) Delete(ctx context.Context, key string) error {
+	result, err := s.client.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: key})
+	if err != nil {
+		return err
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("cloudinary: %s", result.Error.Message)
+	}
+	return nil
+}
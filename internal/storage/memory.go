@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+)
+
+// MemoryStorage menyimpan file di memori; dipakai sebagai pengganti penyimpanan sungguhan di test
+type MemoryStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: map[string][]byte{}}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) (Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return Object{}, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Object{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return Object{Key: key, URL: "memory://" + key}, nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// Get mengembalikan isi object yang tersimpan, untuk pengecekan di test
+func (s *MemoryStorage) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	return data, ok
+}
+
+// Keys mengembalikan key semua object yang tersimpan secara terurut, untuk pengecekan di test
+func (s *MemoryStorage) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
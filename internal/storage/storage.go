@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrInvalidKey dikembalikan jika key kosong atau mencoba keluar dari direktori penyimpanan
+var ErrInvalidKey = errors.New("storage: invalid object key")
+
+// Object adalah file yang sudah tersimpan. Key dipakai untuk menghapusnya, URL untuk menampilkannya.
+type Object struct {
+	Key string
+	URL string
+}
+
+// ObjectStorage menyimpan file publik seperti gambar kendaraan. Implementasinya dipilih saat startup:
+// CloudinaryStorage untuk produksi, LocalStorage untuk development, MemoryStorage untuk test.
+type ObjectStorage interface {
+	// Put menyimpan isi r dengan key yang diminta (misal "vehicles/<id>/<uuid>.jpg").
+	// Key di Object yang dikembalikan bisa berbeda sesuai aturan penyedia.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (Object, error)
+	// Delete menghapus object; key yang tidak ada tidak dianggap error
+	Delete(ctx context.Context, key string) error
+}
+
+// cleanKey menormalkan key menjadi path relatif dengan pemisah "/" dan menolak ".."
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + strings.ReplaceAll(key, "\\", "/"))
+	cleaned = strings.TrimPrefix(cleaned, "/")
+	if cleaned == "" || cleaned == "." || strings.Contains(key, "..") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
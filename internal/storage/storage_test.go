@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "vehicles/a/b.jpg", want: "vehicles/a/b.jpg"},
+		{key: "/vehicles/a/b.jpg", want: "vehicles/a/b.jpg"},
+		{key: `vehicles\a\b.jpg`, want: "vehicles/a/b.jpg"},
+		{key: "vehicles//a/./b.jpg", want: "vehicles/a/b.jpg"},
+		{key: "", wantErr: true},
+		{key: "/", wantErr: true},
+		{key: ".", wantErr: true},
+		{key: "../etc/passwd", wantErr: true},
+		{key: "vehicles/../../etc/passwd", wantErr: true},
+		{key: `vehicles\..\secret`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := cleanKey(tt.key)
+			if tt.wantErr {
+				if err != ErrInvalidKey {
+					t.Errorf("cleanKey(%q) = %q, %v; want ErrInvalidKey", tt.key, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("cleanKey(%q) = %q, %v; want %q", tt.key, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStorage()
+
+	object, err := s.Put(ctx, "/vehicles/1/full.jpg", strings.NewReader("data"), "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object.Key != "vehicles/1/full.jpg" || object.URL != "memory://vehicles/1/full.jpg" {
+		t.Errorf("Put() = %+v, want cleaned key and memory URL", object)
+	}
+	if data, ok := s.Get(object.Key); !ok || string(data) != "data" {
+		t.Errorf("Get() = %q, %v; want stored data", data, ok)
+	}
+
+	if _, err := s.Put(ctx, "../outside", strings.NewReader("x"), "image/jpeg"); err != ErrInvalidKey {
+		t.Errorf("Put with traversal key error = %v, want ErrInvalidKey", err)
+	}
+
+	if err := s.Delete(ctx, object.Key); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get(object.Key); ok {
+		t.Error("object still present after Delete")
+	}
+	if err := s.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete of missing key error = %v, want nil", err)
+	}
+}